@@ -6,6 +6,7 @@ import (
 	"buddy/internal/apps/common"
 	cobraPkg "buddy/internal/apps/common/cobra"
 	mybuddyCmd "buddy/internal/apps/mybuddy/commands"
+	"buddy/internal/config"
 	"buddy/internal/di"
 	"buddy/internal/logging"
 )
@@ -20,9 +21,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Resolve the profile before building services, since flags are parsed later
+	profile, err := config.ResolveProfile(common.ProfileFromArgs(os.Args[1:]), appCtx.Environment)
+	if err != nil {
+		logger.Error("Failed to select profile: %v", err)
+		os.Exit(1)
+	}
+	appCtx.Profile = profile.Name
+
 	// Initialize dependency injection container
 	container := di.NewContainer()
-	if err := container.InitializeForEnvironment(appCtx.Environment); err != nil {
+	if err := container.InitializeForProfile(profile); err != nil {
 		logger.Error("Failed to initialize services: %v", err)
 		os.Exit(1)
 	}
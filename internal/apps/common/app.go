@@ -3,11 +3,13 @@ package common
 import (
 	"buddy/internal/config"
 	"fmt"
+	"strings"
 )
 
 type Context struct {
 	Environment string
 	BinaryName  string
+	Profile     string // active profile; empty means the production profile
 }
 
 func NewContext(binaryName string) (*Context, error) {
@@ -26,10 +28,15 @@ func NewContext(binaryName string) (*Context, error) {
 }
 
 func (c *Context) GetPrefix() string {
+	country := "MY"
 	if c.Environment == "sg" {
-		return "[SG] "
+		country = "SG"
 	}
-	return "[MY] "
+	// Make it obvious in every line of output when a non-production profile is active
+	if c.Profile != "" && c.Profile != config.DefaultProfile(c.Environment).Name {
+		return fmt.Sprintf("[%s:%s] ", country, c.Profile)
+	}
+	return "[" + country + "] "
 }
 
 func (c *Context) IsSG() bool {
@@ -39,3 +46,21 @@ func (c *Context) IsSG() bool {
 func (c *Context) IsMY() bool {
 	return c.Environment == "my"
 }
+
+// ProfileFromArgs returns the value of a --profile flag in args. The profile has
+// to be known before the DI container is built, which happens before cobra parses
+// flags, so main scans for it directly; the root command declares the flag too.
+func ProfileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--profile" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if value, ok := strings.CutPrefix(arg, "--profile="); ok {
+			return value
+		}
+	}
+	return ""
+}
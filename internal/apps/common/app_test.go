@@ -0,0 +1,34 @@
+package common
+
+import "testing"
+
+func TestProfileFromArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"txn", "abc"}, ""},
+		{[]string{"--profile", "my-stg", "txn", "abc"}, "my-stg"},
+		{[]string{"txn", "abc", "--profile=sg-uat"}, "sg-uat"},
+		{[]string{"txn", "--", "--profile", "x"}, ""},
+		{[]string{"txn", "--profile"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := ProfileFromArgs(tt.args); got != tt.want {
+			t.Errorf("ProfileFromArgs(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestGetPrefix(t *testing.T) {
+	if got := (&Context{Environment: "my"}).GetPrefix(); got != "[MY] " {
+		t.Errorf("expected [MY] , got %q", got)
+	}
+	if got := (&Context{Environment: "sg", Profile: "sg-prd"}).GetPrefix(); got != "[SG] " {
+		t.Errorf("expected [SG] for the production profile, got %q", got)
+	}
+	if got := (&Context{Environment: "my", Profile: "my-stg"}).GetPrefix(); got != "[MY:my-stg] " {
+		t.Errorf("expected the profile in the prefix, got %q", got)
+	}
+}
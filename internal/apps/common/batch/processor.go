@@ -3,6 +3,7 @@ package batch
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"buddy/internal/di"
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
+	"buddy/internal/txn/service"
 	"buddy/internal/txn/utils"
 )
 
@@ -45,7 +47,7 @@ func shouldAutoResumeFromTicket(clients *di.ClientSet, jiraID string, prefix str
 }
 
 // ProcessTransactionFile processes a file containing multiple transaction IDs
-func ProcessTransactionFile(appCtx *common.Context, clients *di.ClientSet, filePath string, autoMode bool) {
+func ProcessTransactionFile(appCtx *common.Context, clients *di.ClientSet, filePath string, autoMode bool, opts service.BatchOptions) {
 	fmt.Printf("%sProcessing batch file: %s\n", appCtx.GetPrefix(), filePath)
 
 	// Read transaction IDs from file
@@ -69,33 +71,65 @@ func ProcessTransactionFile(appCtx *common.Context, clients *di.ClientSet, fileP
 
 		if shouldAutoResumeFromTicket(clients, jiraID, appCtx.GetPrefix()) {
 			fmt.Printf("%sAuto mode: Ticket contains confirmation keywords - will auto-resume all eligible transactions\n", appCtx.GetPrefix())
-			// Pre-populate auto-choice for CaseCashoutRpp210Pe220Pc201
-			// Choice 1 = Resume to Success (which is what option 3 sets)
-			adapters.PrepopulateAutoChoice(domain.CaseCashoutRpp210Pe220Pc201, 1)
-		} else {
+			if opts.Decisions == nil {
+				opts.Decisions = adapters.NewDecisions(false)
+			}
+			// Decisions from a --decisions file take precedence over the ticket title
+			if _, ok := opts.Decisions.ByCase[domain.CaseCashoutRpp210Pe220Pc201]; !ok {
+				opts.Decisions.ByCase[domain.CaseCashoutRpp210Pe220Pc201] = adapters.DecisionAccept
+			}
+		} else if opts.Interactive() {
 			fmt.Printf("%sAuto mode: Ticket does not contain confirmation keywords - will use interactive prompts\n", appCtx.GetPrefix())
+		} else {
+			fmt.Printf("%sAuto mode: Ticket does not contain confirmation keywords - will use the decisions file\n", appCtx.GetPrefix())
 		}
 	}
 
-	// Process each transaction ID
-	var results []domain.TransactionResult
-	for i, txnID := range transactionIDs {
-		fmt.Printf("%sProcessing %d/%d: %s\n", appCtx.GetPrefix(), i+1, len(transactionIDs), txnID)
+	// Checkpoint every completed transaction so an interrupted run can be resumed
+	checkpoint, err := service.OpenCheckpoint(filePath, opts.Resume, opts.Fresh)
+	if err != nil {
+		fmt.Printf("%sError opening checkpoint: %v\n", appCtx.GetPrefix(), err)
+		return
+	}
+	defer func() {
+		_ = checkpoint.Close()
+	}()
+	if opts.Resume {
+		fmt.Printf("%sResuming: %d transactions already completed in %s\n", appCtx.GetPrefix(), checkpoint.Len(), checkpoint.Path())
+	}
 
-		result := clients.TxnSvc.QueryTransactionWithEnv(txnID, appCtx.Environment)
+	// Query all transaction IDs with the worker pool. Interactive prompts are only
+	// raised afterwards by GenerateSQLStatements, so they never run concurrently.
+	opts.Prefix = appCtx.GetPrefix()
+	opts.Checkpoint = checkpoint
+	var results []domain.TransactionResult
+	for i, result := range clients.TxnSvc.QueryTransactionsWithEnv(transactionIDs, appCtx.Environment, opts) {
 		if result != nil {
 			results = append(results, *result)
 		} else {
-			fmt.Printf("%sError processing transaction ID: %s\n", appCtx.GetPrefix(), txnID)
+			fmt.Printf("%sError processing transaction ID: %s\n", appCtx.GetPrefix(), transactionIDs[i])
 		}
 	}
 
 	// Write batch results to file
 	if len(results) > 0 {
 		outputPath := filePath + "_results.txt"
-		fmt.Printf("%s\nWriting batch results to: %s\n", appCtx.GetPrefix(), outputPath)
 
-		if err := adapters.WriteBatchResults(results, outputPath); err != nil {
+		var statements domain.SQLStatements
+		if opts.Output.IsStructured() {
+			// Structured results embed the generated SQL, so generate it before writing
+			if statements, err = adapters.GenerateSQLStatementsWithDecisions(results, opts.DecisionProvider()); err != nil {
+				fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+				return
+			}
+			outputPath = adapters.StructuredOutputPath(outputPath, opts.Output)
+			fmt.Printf("%s\nWriting batch results to: %s\n", appCtx.GetPrefix(), outputPath)
+			err = adapters.WriteStructuredBatchResults(results, statements, outputPath, opts.Output)
+		} else {
+			fmt.Printf("%s\nWriting batch results to: %s\n", appCtx.GetPrefix(), outputPath)
+			err = adapters.WriteBatchResults(results, outputPath)
+		}
+		if err != nil {
 			fmt.Printf("%sError writing batch results: %v\n", appCtx.GetPrefix(), err)
 		} else {
 			fmt.Printf("%sBatch processing completed. Results written to %s\n", appCtx.GetPrefix(), outputPath)
@@ -105,7 +139,12 @@ func ProcessTransactionFile(appCtx *common.Context, clients *di.ClientSet, fileP
 		adapters.ClearSQLFiles()
 
 		// Generate SQL statements
-		statements := adapters.GenerateSQLStatements(results)
+		if !opts.Output.IsStructured() {
+			if statements, err = adapters.GenerateSQLStatementsWithDecisions(results, opts.DecisionProvider()); err != nil {
+				fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+				return
+			}
+		}
 
 		// Write SQL to database-specific files
 		filesCreated, err := adapters.WriteSQLFiles(statements, filePath)
@@ -121,42 +160,119 @@ func ProcessTransactionFile(appCtx *common.Context, clients *di.ClientSet, fileP
 			fmt.Printf("%sNo SQL fixes required for these transactions.\n", appCtx.GetPrefix())
 		}
 
+		// A clean run has nothing to resume; keep the checkpoint only for failed IDs
+		removed := false
+		if len(results) == len(transactionIDs) {
+			if removed, err = checkpoint.Finish(results); err != nil {
+				fmt.Printf("%sWarning: %v\n", appCtx.GetPrefix(), err)
+			}
+		}
+		if !removed && err == nil {
+			fmt.Printf("%sCheckpoint kept at %s; rerun with --resume to retry failed transactions\n", appCtx.GetPrefix(), checkpoint.Path())
+		}
+
 		// Prompt to create Doorman DML tickets for all services combined
-		doorman.PromptForDoormanTicket(clients.Doorman, statements, false, "")
+		if opts.Interactive() {
+			doorman.PromptForDoormanTicket(clients.Doorman, statements, false, "")
+		}
 	}
 }
 
-// ProcessRPPResumeFile processes a file containing E2E IDs for RPP resume operations
-func ProcessRPPResumeFile(appCtx *common.Context, clients *di.ClientSet, filePath string) {
-	fmt.Printf("%sProcessing RPP resume batch file: %s\n", appCtx.GetPrefix(), filePath)
+// ProcessRPPResumeFile processes a file containing E2E IDs for RPP resume operations.
+// With a structured output format, status lines go to stderr and the results are
+// written to stdout as one JSON/NDJSON document once every ID has been queried.
+func ProcessRPPResumeFile(appCtx *common.Context, clients *di.ClientSet, filePath string, output adapters.OutputFormat) {
+	status := io.Writer(os.Stdout)
+	if output.IsStructured() {
+		status = os.Stderr
+	}
+
+	writeStatus := func(format string, args ...interface{}) {
+		if _, err := fmt.Fprintf(status, format, args...); err != nil {
+			fmt.Printf("Warning: failed to write status: %v\n", err)
+		}
+	}
+
+	writeStatus("%sProcessing RPP resume batch file: %s\n", appCtx.GetPrefix(), filePath)
 
 	// Read E2E IDs from file
 	e2eIDs, err := utils.ReadTransactionIDsFromFile(filePath)
 	if err != nil {
-		fmt.Printf("%sError reading file %s: %v\n", appCtx.GetPrefix(), filePath, err)
+		writeStatus("%sError reading file %s: %v\n", appCtx.GetPrefix(), filePath, err)
 		return
 	}
 
 	if len(e2eIDs) == 0 {
-		fmt.Printf("%sNo E2E IDs found in file: %s\n", appCtx.GetPrefix(), filePath)
+		writeStatus("%sNo E2E IDs found in file: %s\n", appCtx.GetPrefix(), filePath)
 		return
 	}
 
-	fmt.Printf("%sFound %d E2E IDs to process for RPP resume\n", appCtx.GetPrefix(), len(e2eIDs))
+	writeStatus("%sFound %d E2E IDs to process for RPP resume\n", appCtx.GetPrefix(), len(e2eIDs))
 
 	// Process each E2E ID for RPP resume
+	var results []domain.TransactionResult
 	for i, e2eID := range e2eIDs {
-		fmt.Printf("%sProcessing RPP resume %d/%d: %s\n", appCtx.GetPrefix(), i+1, len(e2eIDs), e2eID)
+		writeStatus("%sProcessing RPP resume %d/%d: %s\n", appCtx.GetPrefix(), i+1, len(e2eIDs), e2eID)
 
 		result := clients.TxnSvc.QueryTransactionWithEnv(e2eID, appCtx.Environment)
-		if result != nil {
-			// Generate and display RPP resume SQL
-			fmt.Printf("%sTransaction found, generating RPP resume SQL...\n", appCtx.GetPrefix())
-			adapters.WriteResult(os.Stdout, *result, i+1)
-		} else {
-			fmt.Printf("%sError processing E2E ID: %s\n", appCtx.GetPrefix(), e2eID)
+		if result == nil {
+			writeStatus("%sError processing E2E ID: %s\n", appCtx.GetPrefix(), e2eID)
+			continue
+		}
+
+		if output.IsStructured() {
+			results = append(results, *result)
+			continue
+		}
+
+		// Generate and display RPP resume SQL
+		fmt.Printf("%sTransaction found, generating RPP resume SQL...\n", appCtx.GetPrefix())
+		adapters.WriteResult(os.Stdout, *result, i+1)
+	}
+
+	if output.IsStructured() {
+		statements, err := rppResumeStatements(results)
+		if err != nil {
+			writeStatus("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
 		}
+		if err := adapters.WriteStructuredResults(os.Stdout, output, results, statements); err != nil {
+			writeStatus("%sError writing results: %v\n", appCtx.GetPrefix(), err)
+		}
+	}
+}
+
+// rppResumeStatements generates the resume SQL of every result that matches the
+// resume criteria, consolidated into one set of statements. Results that failed
+// to query are skipped. It returns nil if no result matches.
+func rppResumeStatements(results []domain.TransactionResult) (*domain.SQLStatements, error) {
+	var merged *domain.DMLTicket
+	for _, result := range results {
+		if result.Error != "" || result.QueryFailed() {
+			continue
+		}
+		ticket := adapters.GetDMLTicketForRppResume(result)
+		if ticket == nil {
+			continue
+		}
+		if merged == nil {
+			merged = ticket
+			continue
+		}
+		merged.Deploy = append(merged.Deploy, ticket.Deploy...)
+		merged.Rollback = append(merged.Rollback, ticket.Rollback...)
+	}
+	if merged == nil {
+		return nil, nil
+	}
+
+	statements, err := adapters.GenerateSQLFromTicket(*merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := adapters.VerifySQLStatements(statements); err != nil {
+		return nil, err
 	}
+	return &statements, nil
 }
 
 // ProcessRTPCashinFile processes a file containing E2E IDs for RTP cashin operations
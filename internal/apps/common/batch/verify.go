@@ -0,0 +1,136 @@
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"buddy/internal/apps/common"
+	"buddy/internal/di"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+	"buddy/internal/txn/service"
+	"buddy/internal/txn/utils"
+)
+
+// verifyTarget is a transaction to re-check and the case whose SQL was run on it
+type verifyTarget struct {
+	id       string
+	caseType domain.Case
+}
+
+// VerifyFixes re-queries transactions after their DML was deployed and reports,
+// per transaction, whether the fix landed, is still stuck or moved to another
+// SOP case. input is a --output json/ndjson results file, a file of IDs or a
+// single ID; caseOverride is required for the latter two. It returns false if
+// any transaction did not land.
+func VerifyFixes(appCtx *common.Context, clients *di.ClientSet, input string, caseOverride string, opts service.BatchOptions) bool {
+	prefix := appCtx.GetPrefix()
+
+	targets, err := loadVerifyTargets(input, domain.Case(caseOverride))
+	if err != nil {
+		fmt.Printf("%sError: %v\n", prefix, err)
+		return false
+	}
+	if len(targets) == 0 {
+		fmt.Printf("%sNo transactions to verify in %s\n", prefix, input)
+		return false
+	}
+
+	for _, target := range targets {
+		if !adapters.HasFixExpectations(target.caseType) {
+			fmt.Printf("%sError: case %s for %s has no SQL template, so there is nothing to verify\n", prefix, target.caseType, target.id)
+			return false
+		}
+	}
+
+	ids := make([]string, len(targets))
+	for i, target := range targets {
+		ids[i] = target.id
+	}
+
+	fmt.Printf("%sVerifying %d transactions\n", prefix, len(ids))
+	opts.Prefix = prefix
+	opts.Checkpoint = nil
+	opts.Resume = false
+	results := clients.TxnSvc.QueryTransactionsWithEnv(ids, appCtx.Environment, opts)
+
+	counts := make(map[adapters.FixStatus]int)
+	for i, target := range targets {
+		result := results[i]
+		if result == nil {
+			result = &domain.TransactionResult{InputID: target.id, Error: "failed to query transaction"}
+		}
+
+		verification := adapters.SOPRepo.VerifyFix(target.caseType, result, appCtx.Environment)
+		verification.InputID = target.id
+		counts[verification.Status]++
+		fmt.Printf("%s%s\n", prefix, verification)
+	}
+
+	printVerifySummary(prefix, len(targets), counts)
+	return counts[adapters.FixLanded] == len(targets)
+}
+
+// loadVerifyTargets resolves the verify input into transactions and their cases
+func loadVerifyTargets(input string, caseOverride domain.Case) ([]verifyTarget, error) {
+	if _, err := os.Stat(input); err != nil {
+		if caseOverride == "" {
+			return nil, fmt.Errorf("--case is required when verifying a single transaction")
+		}
+		return []verifyTarget{{id: input, caseType: caseOverride}}, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(input)) {
+	case ".json", ".ndjson":
+		records, err := adapters.ReadStructuredResults(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []verifyTarget
+		for _, record := range records {
+			caseType := domain.Case(record.Case)
+			if caseOverride != "" {
+				caseType = caseOverride
+			}
+			// Transactions without a case had no SQL generated for them
+			if record.Error != "" || caseType == "" || caseType == domain.CaseNone {
+				continue
+			}
+			targets = append(targets, verifyTarget{id: record.InputID, caseType: caseType})
+		}
+		return targets, nil
+	default:
+		if caseOverride == "" {
+			return nil, fmt.Errorf("--case is required when verifying a file of transaction IDs; pass a --output json or ndjson results file to use the recorded cases")
+		}
+
+		ids, err := utils.ReadTransactionIDsFromFile(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", input, err)
+		}
+
+		targets := make([]verifyTarget, 0, len(ids))
+		for _, id := range ids {
+			targets = append(targets, verifyTarget{id: id, caseType: caseOverride})
+		}
+		return targets, nil
+	}
+}
+
+func printVerifySummary(prefix string, total int, counts map[adapters.FixStatus]int) {
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s=%d", status, counts[adapters.FixStatus(status)]))
+	}
+	fmt.Printf("%s\nVerified %d transactions: %s\n", prefix, total, strings.Join(parts, ", "))
+}
@@ -2,6 +2,7 @@ package cobra
 
 import (
 	"buddy/internal/apps/common"
+	"buddy/internal/config"
 	"fmt"
 	"github.com/spf13/cobra"
 )
@@ -20,9 +21,13 @@ func NewRootCommand(appCtx *common.Context) *cobra.Command {
 		Use:   "version",
 		Short: "Display the version of " + appCtx.BinaryName,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("%s version 1.0.0 (Environment: %s)\n", appCtx.BinaryName, appCtx.GetPrefix()[1:3])
+			fmt.Printf("%s version 1.0.0 (Environment: %s, Profile: %s)\n", appCtx.BinaryName, appCtx.GetPrefix()[1:3], appCtx.Profile)
 		},
 	})
 
+	// Read by main before the command tree runs; declared here for help and validation
+	rootCmd.PersistentFlags().String("profile", appCtx.Profile,
+		"Named profile selecting the Doorman, Jira and Datadog backends (e.g. my-stg, sg-uat; env "+config.ProfileEnvVar+")")
+
 	return rootCmd
 }
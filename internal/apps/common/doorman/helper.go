@@ -9,25 +9,47 @@ import (
 	"strings"
 )
 
+// CreatedTicket is a Doorman DML ticket created for a service
+type CreatedTicket struct {
+	Service string
+	ID      string
+	URL     string // Doorman page of the ticket
+}
+
 // PromptForDoormanTicket prompts user to create Doorman DML tickets for all services
 // This function is shared between mybuddy and sgbuddy to avoid circular dependencies
 // If autoCreate is true and note is provided, skips prompts and creates tickets automatically
-func PromptForDoormanTicket(doormanClient doorman.DoormanInterface, statements domain.SQLStatements, autoCreate bool, note string) {
+// Returns the tickets that were created
+func PromptForDoormanTicket(doormanClient doorman.DoormanInterface, statements domain.SQLStatements, autoCreate bool, note string) []CreatedTicket {
 	if doormanClient == nil {
-		return
+		return nil
+	}
+
+	services := []struct {
+		name             string
+		deploy, rollback []string
+	}{
+		{"payment_core", statements.PCDeployStatements, statements.PCRollbackStatements},
+		{"rpp_adapter", statements.RPPDeployStatements, statements.RPPRollbackStatements},
+		{"payment_engine", statements.PEDeployStatements, statements.PERollbackStatements},
+		{"partnerpay_engine", statements.PPEDeployStatements, statements.PPERollbackStatements},
 	}
 
-	ProcessServiceDML(doormanClient, "payment_core", statements.PCDeployStatements, statements.PCRollbackStatements, autoCreate, note)
-	ProcessServiceDML(doormanClient, "rpp_adapter", statements.RPPDeployStatements, statements.RPPRollbackStatements, autoCreate, note)
-	ProcessServiceDML(doormanClient, "payment_engine", statements.PEDeployStatements, statements.PERollbackStatements, autoCreate, note)
-	ProcessServiceDML(doormanClient, "partnerpay_engine", statements.PPEDeployStatements, statements.PPERollbackStatements, autoCreate, note)
+	var created []CreatedTicket
+	for _, service := range services {
+		if ticketID := ProcessServiceDML(doormanClient, service.name, service.deploy, service.rollback, autoCreate, note); ticketID != "" {
+			created = append(created, CreatedTicket{Service: service.name, ID: ticketID, URL: doormanClient.TicketURL(ticketID)})
+		}
+	}
+	return created
 }
 
 // ProcessServiceDML prompts and creates a Doorman ticket for a single service
 // If autoCreate is true and note is provided, skips prompts and creates ticket automatically
-func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName string, deployStmts, rollbackStmts []string, autoCreate bool, note string) {
+// Returns the ID of the created ticket, or an empty string if none was created
+func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName string, deployStmts, rollbackStmts []string, autoCreate bool, note string) string {
 	if len(deployStmts) == 0 {
-		return
+		return ""
 	}
 
 	// Auto-create mode: skip prompts and create ticket directly
@@ -39,12 +61,11 @@ func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName strin
 		ticketID, err := doormanClient.CreateTicket(serviceName, originalQuery, rollbackQuery, note)
 		if err != nil {
 			fmt.Printf("Failed to create ticket: %v\n", err)
-			return
+			return ""
 		}
 
-		ticketURL := fmt.Sprintf("https://doorman.infra.prd.g-bank.app/rds/dml/%s", ticketID)
-		fmt.Printf("Ticket created successfully!\nTicket ID: %s\nTicket URL: %s\n", ticketID, ticketURL)
-		return
+		fmt.Printf("Ticket created successfully!\nTicket ID: %s\nTicket URL: %s\n", ticketID, doormanClient.TicketURL(ticketID))
+		return ticketID
 	}
 
 	// Interactive mode: prompt user
@@ -57,7 +78,7 @@ func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName strin
 	_, result, err := prompt.Run()
 	if err != nil {
 		fmt.Printf("Prompt failed %v\n", err)
-		return
+		return ""
 	}
 
 	if result == "Yes" {
@@ -67,7 +88,7 @@ func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName strin
 		note, err := promptNote.Run()
 		if err != nil {
 			fmt.Printf("Prompt failed %v\n", err)
-			return
+			return ""
 		}
 
 		originalQuery := strings.Join(deployStmts, "\n")
@@ -77,10 +98,11 @@ func ProcessServiceDML(doormanClient doorman.DoormanInterface, serviceName strin
 		ticketID, err := doormanClient.CreateTicket(serviceName, originalQuery, rollbackQuery, note)
 		if err != nil {
 			fmt.Printf("Failed to create ticket: %v\n", err)
-			return
+			return ""
 		}
 
-		ticketURL := fmt.Sprintf("https://doorman.infra.prd.g-bank.app/rds/dml/%s", ticketID)
-		fmt.Printf("Ticket created successfully!\nTicket ID: %s\nTicket URL: %s\n", ticketID, ticketURL)
+		fmt.Printf("Ticket created successfully!\nTicket ID: %s\nTicket URL: %s\n", ticketID, doormanClient.TicketURL(ticketID))
+		return ticketID
 	}
+	return ""
 }
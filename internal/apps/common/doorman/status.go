@@ -0,0 +1,122 @@
+package doorman
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"buddy/internal/clients/doorman"
+)
+
+// DefaultTicketPollInterval is how often doorman status re-fetches unsettled tickets
+const DefaultTicketPollInterval = 15 * time.Second
+
+// TicketState is the last known status of one DML ticket
+type TicketState struct {
+	ID     string
+	Ticket *doorman.TicketResult
+	Err    error
+}
+
+// Status returns the ticket status, or UNKNOWN if it could not be fetched
+func (s TicketState) Status() string {
+	if s.Ticket == nil {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(s.Ticket.Status)
+}
+
+// Settled reports whether the ticket has been approved, executed or rejected
+func (s TicketState) Settled() bool {
+	return s.Ticket != nil && doorman.IsTicketSettled(s.Ticket.Status)
+}
+
+// WatchTickets fetches every ticket and, while wait has not elapsed, re-fetches
+// the unsettled ones every interval. Status changes are written to out as they
+// are seen. A wait of zero fetches each ticket once.
+func WatchTickets(client doorman.DoormanInterface, ticketIDs []string, wait, interval time.Duration, out io.Writer) []TicketState {
+	states := make([]TicketState, len(ticketIDs))
+	for i, id := range ticketIDs {
+		states[i].ID = id
+	}
+	if interval <= 0 {
+		interval = DefaultTicketPollInterval
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		pending := 0
+		for i := range states {
+			if states[i].Settled() {
+				continue
+			}
+
+			previous := states[i].Status()
+			ticket, err := client.GetTicket(states[i].ID)
+			states[i].Err = err
+			if err == nil {
+				states[i].Ticket = ticket
+			}
+
+			if wait > 0 && states[i].Status() != previous && err == nil {
+				_, _ = fmt.Fprintf(out, "%s ticket %s: %s\n", time.Now().Format("15:04:05"), states[i].ID, states[i].Status())
+			}
+			if !states[i].Settled() {
+				pending++
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if pending == 0 || remaining <= 0 {
+			return states
+		}
+		if remaining < interval {
+			time.Sleep(remaining)
+		} else {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// WriteTicketStates prints one row per ticket
+func WriteTicketStates(w io.Writer, states []TicketState) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TICKET\tSTATUS\tSERVICE\tSUBMITTER\tAFFECTED\tNOTE")
+	for _, state := range states {
+		if state.Ticket == nil {
+			errMsg := ""
+			if state.Err != nil {
+				errMsg = state.Err.Error()
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t\t\t\t%s\n", state.ID, state.Status(), errMsg)
+			continue
+		}
+		ticket := state.Ticket
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			state.ID, state.Status(), ticket.Schema, ticket.Submitter, ticket.AffectRows, firstLine(ticket.Note))
+	}
+	_ = tw.Flush()
+}
+
+// TicketsSucceeded reports whether every ticket was fetched and none was
+// rejected; with requireSettled, every ticket must also have settled
+func TicketsSucceeded(states []TicketState, requireSettled bool) bool {
+	for _, state := range states {
+		if state.Ticket == nil || doorman.IsTicketRejected(state.Ticket.Status) {
+			return false
+		}
+		if requireSettled && !state.Settled() {
+			return false
+		}
+	}
+	return true
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
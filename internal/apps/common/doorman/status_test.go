@@ -0,0 +1,79 @@
+package doorman
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"buddy/internal/clients/doorman"
+)
+
+// fakeTicketClient serves ticket statuses from a script, one entry per GetTicket call
+type fakeTicketClient struct {
+	doorman.DoormanInterface
+	statuses map[string][]string
+	calls    map[string]int
+}
+
+func (f *fakeTicketClient) GetTicket(ticketID string) (*doorman.TicketResult, error) {
+	script, ok := f.statuses[ticketID]
+	if !ok {
+		return nil, errors.New("ticket not found")
+	}
+	call := f.calls[ticketID]
+	f.calls[ticketID]++
+	if call >= len(script) {
+		call = len(script) - 1
+	}
+	return &doorman.TicketResult{Status: script[call]}, nil
+}
+
+func TestWatchTickets(t *testing.T) {
+	client := &fakeTicketClient{
+		statuses: map[string][]string{
+			"1": {"PENDING", "APPROVED", "EXECUTED"},
+			"2": {"EXECUTED"},
+		},
+		calls: map[string]int{},
+	}
+
+	var out bytes.Buffer
+	states := WatchTickets(client, []string{"1", "2"}, time.Second, time.Millisecond, &out)
+
+	if states[0].Status() != "APPROVED" || states[1].Status() != "EXECUTED" {
+		t.Errorf("unexpected final statuses: %s, %s", states[0].Status(), states[1].Status())
+	}
+	if client.calls["2"] != 1 {
+		t.Errorf("settled ticket should not be polled again, got %d calls", client.calls["2"])
+	}
+	if !strings.Contains(out.String(), "ticket 1: APPROVED") {
+		t.Errorf("expected status change to be reported, got %q", out.String())
+	}
+	if !TicketsSucceeded(states, true) {
+		t.Error("expected tickets to have succeeded")
+	}
+}
+
+func TestWatchTickets_TimesOut(t *testing.T) {
+	client := &fakeTicketClient{
+		statuses: map[string][]string{"1": {"PENDING"}},
+		calls:    map[string]int{},
+	}
+
+	states := WatchTickets(client, []string{"1", "missing"}, 20*time.Millisecond, 5*time.Millisecond, &bytes.Buffer{})
+
+	if states[0].Settled() {
+		t.Error("pending ticket should not be settled")
+	}
+	if states[1].Err == nil || states[1].Status() != "UNKNOWN" {
+		t.Errorf("expected lookup error for missing ticket, got %+v", states[1])
+	}
+	if client.calls["1"] < 2 {
+		t.Errorf("expected pending ticket to be polled more than once, got %d", client.calls["1"])
+	}
+	if TicketsSucceeded(states, true) {
+		t.Error("expected unsettled tickets to fail")
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"buddy/internal/apps/common"
 	"buddy/internal/clients/jira"
+	"buddy/internal/config"
 	"buddy/internal/ui"
 
 	"github.com/spf13/cobra"
@@ -47,7 +48,7 @@ func RunJiraView(ctx context.Context, appCtx *common.Context, issueKey string, s
 
 	// Validate JIRA username is configured
 	if jiraConfig.Auth.Username == "" {
-		fmt.Printf("Error: JIRA_USERNAME not configured. Please set it in your .env.%s file\n", appCtx.Environment)
+		fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
 		os.Exit(1)
 	}
 
@@ -99,6 +100,12 @@ func RunJiraView(ctx context.Context, appCtx *common.Context, issueKey string, s
 
 // NewJiraSearchCmd creates a generic JIRA search command that can be used by both mybuddy and sgbuddy
 func NewJiraSearchCmd(appCtx *common.Context) *cobra.Command {
+	var (
+		jql        string
+		outputFlag string
+		opts       jira.SearchOptions
+	)
+
 	cmd := &cobra.Command{
 		Use:   "search [search terms...]",
 		Short: "Search JIRA tickets with custom criteria",
@@ -108,6 +115,16 @@ Search criteria is defined by you - use text search for simple queries or JQL fo
 Supports both text search and raw JQL queries:
 - Text search: buddy jira search "payment issue"
 - JQL query: buddy jira search "project = TS"
+- Explicit JQL: buddy jira search --jql "project = TS ORDER BY created DESC"
+
+Results are fetched page by page up to --max-results tickets.
+
+Output (--output):
+links (default) prints one hyperlinked ticket key per line, table prints key, type,
+status, priority, assignee, created date and summary, and json prints the tickets
+with every attribute. --fields adds raw values of other fields, such as custom
+fields, and --expand adds expanded sections such as renderedFields or changelog
+to the JSON output.
 
 Examples:
   buddy jira search "payment issue"
@@ -115,9 +132,25 @@ Examples:
   buddy jira search "API" "error"
   buddy jira search "project = TS"
   buddy jira search "reporter = currentUser()"
-  buddy jira search "status = Closed"`,
-		Args: cobra.MinimumNArgs(1),
+  buddy jira search "status = Closed"
+  buddy jira search --jql "project = TS AND status = Open" --output table
+  buddy jira search --jql "key = TS-1234" --fields customfield_10060 --expand changelog --output json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if jql == "" && len(args) == 0 {
+				return fmt.Errorf("requires search terms or --jql")
+			}
+			if jql != "" && len(args) > 0 {
+				return fmt.Errorf("search terms cannot be combined with --jql")
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
+			output, err := ParseSearchOutput(outputFlag)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			// Check if JIRA client is initialized
 			if jira.Jira == nil {
 				fmt.Printf("Error: JIRA client not initialized. Please ensure JIRA is properly configured.\n")
@@ -129,7 +162,7 @@ Examples:
 
 			// Validate JIRA username is configured
 			if jiraConfig.Auth.Username == "" {
-				fmt.Printf("Error: JIRA_USERNAME not configured. Please set it in your .env.%s file\n", appCtx.Environment)
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
 				os.Exit(1)
 			}
 
@@ -139,15 +172,14 @@ Examples:
 			// Create context with timeout
 			ctx := cmd.Context()
 
-			// Detect if this is a JQL query (contains field operators like =, !=, IN, etc.)
-			isJQL := looksLikeJQL(searchTerm)
-
 			var issues []jira.JiraTicket
-			var err error
 
-			if isJQL {
+			if jql != "" {
+				searchTerm = jql
+				issues, err = jira.Jira.ExecuteJQL(ctx, jql, opts)
+			} else if looksLikeJQL(searchTerm) {
 				// Execute raw JQL query
-				issues, err = jira.Jira.ExecuteJQL(ctx, searchTerm)
+				issues, err = jira.Jira.ExecuteJQL(ctx, searchTerm, opts)
 			} else {
 				// Search tickets in summary/description
 				issues, err = jira.Jira.SearchIssues(ctx, searchTerm)
@@ -158,7 +190,7 @@ Examples:
 				os.Exit(1)
 			}
 
-			if len(issues) == 0 {
+			if len(issues) == 0 && output != SearchOutputJSON {
 				fmt.Printf("No tickets found matching '%s'\n", searchTerm)
 				return
 			}
@@ -169,13 +201,18 @@ Examples:
 				baseURL = jiraConfig.Domain
 			}
 
-			// Display results as hyperlinks
-			for _, issue := range issues {
-				ticketURL := fmt.Sprintf("%s/browse/%s", baseURL, issue.Key)
-				fmt.Println(ui.CreateHyperlink(ticketURL, issue.Key))
+			if err := WriteTickets(os.Stdout, issues, output, baseURL); err != nil {
+				fmt.Printf("Error writing results: %v\n", err)
+				os.Exit(1)
 			}
 		},
 	}
+
+	cmd.Flags().StringVar(&jql, "jql", "", "Run this JQL query instead of a text search")
+	cmd.Flags().StringVar(&outputFlag, "output", SearchOutputLinks, "Output format: links, table or json")
+	cmd.Flags().StringSliceVar(&opts.Fields, "fields", nil, "Extra fields to return raw in the JSON output, e.g. customfield_10060 (JQL searches only)")
+	cmd.Flags().StringSliceVar(&opts.Expand, "expand", nil, "Sections to expand in the JSON output, e.g. renderedFields,changelog (JQL searches only)")
+	cmd.Flags().IntVar(&opts.MaxResults, "max-results", jira.DefaultSearchMaxResults, "Maximum number of tickets to fetch across all pages (JQL searches only)")
 	return cmd
 }
 
@@ -150,6 +150,11 @@ func (s *AttachmentDownloadService) filterCSVAttachments(attachments []jira.Atta
 
 // isCSVFile checks if an attachment is a CSV file based on filename extension or mime type
 func (s *AttachmentDownloadService) isCSVFile(attachment jira.Attachment) bool {
+	return isCSVAttachment(attachment)
+}
+
+// isCSVAttachment checks if an attachment is a CSV file based on filename extension or mime type
+func isCSVAttachment(attachment jira.Attachment) bool {
 	// Check file extension
 	if strings.HasSuffix(strings.ToLower(attachment.Filename), ".csv") {
 		return true
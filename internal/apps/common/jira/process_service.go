@@ -0,0 +1,162 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"buddy/internal/apps/common/doorman"
+	"buddy/internal/clients/jira"
+	"buddy/internal/txn/domain"
+)
+
+// Sources the transaction IDs of a ticket are extracted from
+const (
+	SourceCSVAttachments  = "CSV attachments"
+	SourceTextAttachments = "text attachments"
+	SourceDescription     = "description"
+)
+
+// descriptionIDPattern matches transaction IDs (32 hex characters) and RPP
+// end-to-end IDs in free text
+var descriptionIDPattern = regexp.MustCompile(`\b(?:[0-9a-fA-F]{32}|\d{8}[A-Z0-9]{14}\d{8})\b`)
+
+// ExtractTransactionIDs collects the transaction IDs of a ticket. CSV attachments
+// are preferred, using the end-to-end ID of each row or its transaction ID; then
+// .txt attachments with one ID per line; then IDs found in the description.
+// IDs are deduplicated in the order they are found. The source is returned along
+// with the IDs.
+func ExtractTransactionIDs(ctx context.Context, client jira.JiraInterface, ticket *jira.JiraTicket) ([]string, string, error) {
+	var csvIDs, textIDs []string
+	for _, attachment := range ticket.Attachments {
+		isCSV := isCSVAttachment(attachment)
+		if !isCSV && !strings.EqualFold(filepath.Ext(attachment.Filename), ".txt") {
+			continue
+		}
+
+		content, err := client.GetAttachmentContent(ctx, attachment.URL)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to download attachment %s: %w", attachment.Filename, err)
+		}
+
+		if !isCSV {
+			textIDs = append(textIDs, strings.Fields(string(content))...)
+			continue
+		}
+
+		rows, err := client.ParseCSVAttachment(string(content))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse attachment %s: %w", attachment.Filename, err)
+		}
+		for _, row := range rows {
+			if id := valueOf(row.EndToEndID); id != "" {
+				csvIDs = append(csvIDs, id)
+			} else if id := valueOf(row.TransactionID); id != "" {
+				csvIDs = append(csvIDs, id)
+			}
+		}
+	}
+
+	switch {
+	case len(csvIDs) > 0:
+		return uniqueIDs(csvIDs), SourceCSVAttachments, nil
+	case len(textIDs) > 0:
+		return uniqueIDs(textIDs), SourceTextAttachments, nil
+	}
+
+	ids := uniqueIDs(descriptionIDPattern.FindAllString(ticket.Description, -1))
+	if len(ids) == 0 {
+		return nil, "", fmt.Errorf("no transaction IDs found in the attachments or description of %s", ticket.Key)
+	}
+	return ids, SourceDescription, nil
+}
+
+// uniqueIDs trims ids and drops blanks and duplicates, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	var unique []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// ProcessSummary records what a jira process run did for a ticket
+type ProcessSummary struct {
+	TicketID       string
+	Source         string   // where the transaction IDs were found
+	IDs            []string // transaction IDs extracted from the ticket
+	Results        []domain.TransactionResult
+	SQLGenerated   bool     // false if SQL generation was skipped
+	SQLFiles       []string // Deploy/Rollback files written
+	Attached       []string // files attached to the ticket
+	DoormanTickets []doorman.CreatedTicket
+}
+
+// CaseCounts returns the number of results per case in the case summary order,
+// followed by the results whose case could not be identified
+func (s *ProcessSummary) CaseCounts() []string {
+	counts := make(map[domain.Case]int)
+	unmatched := 0
+	for _, result := range s.Results {
+		if result.CaseType == domain.CaseNone || result.CaseType == "" || result.Error != "" {
+			unmatched++
+			continue
+		}
+		counts[result.CaseType]++
+	}
+
+	var lines []string
+	for _, caseType := range domain.GetCaseSummaryOrder() {
+		if count := counts[caseType]; count > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %d", caseType, count))
+		}
+	}
+	if unmatched > 0 {
+		lines = append(lines, fmt.Sprintf("unmatched: %d", unmatched))
+	}
+	return lines
+}
+
+// SummaryComment builds the comment posted back on the ticket: the transactions
+// processed, their cases, the SQL files generated and the Doorman tickets created
+func (s *ProcessSummary) SummaryComment(app string) jira.ADFDoc {
+	blocks := []jira.ADFNode{
+		jira.ADFParagraph(fmt.Sprintf("%s processed %d transaction(s) from the %s of this ticket (%d queried).",
+			app, len(s.IDs), s.Source, len(s.Results))),
+	}
+
+	if counts := s.CaseCounts(); len(counts) > 0 {
+		blocks = append(blocks, jira.ADFParagraph("Cases:"), jira.ADFBulletList(counts...))
+	}
+
+	switch {
+	case !s.SQLGenerated:
+		// SQL generation was skipped
+	case len(s.SQLFiles) == 0:
+		blocks = append(blocks, jira.ADFParagraph("No SQL fixes required for these transactions."))
+	default:
+		blocks = append(blocks, jira.ADFParagraph("SQL files generated:"), jira.ADFBulletList(s.SQLFiles...))
+	}
+
+	if len(s.Attached) > 0 {
+		blocks = append(blocks, jira.ADFParagraph("Attached to this ticket:"), jira.ADFBulletList(s.Attached...))
+	}
+
+	if len(s.DoormanTickets) > 0 {
+		var tickets []string
+		for _, ticket := range s.DoormanTickets {
+			tickets = append(tickets, fmt.Sprintf("%s: %s", ticket.Service, ticket.URL))
+		}
+		blocks = append(blocks, jira.ADFParagraph("Doorman DML tickets:"), jira.ADFBulletList(tickets...))
+	}
+
+	return jira.NewADFDoc(blocks...)
+}
@@ -0,0 +1,116 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"buddy/internal/apps/common/doorman"
+	"buddy/internal/clients/jira"
+	"buddy/internal/txn/domain"
+)
+
+func TestExtractTransactionIDs(t *testing.T) {
+	csv := "Date,Batch ID,TAR02 BMID,Transaction ID,DBMY Status,TAR02 STS\n" +
+		"2025-10-17,b1,e2e-1,t1,PROCESSING,ACSP\n" +
+		"2025-10-17,b2,,t2,PROCESSING,ACSP\n" +
+		"2025-10-17,b3,e2e-1,t3,PROCESSING,ACSP\n"
+	description := "Please check ccc572052d6446a2b896fee381dcca3a and 20251228GXSPMYKL010ORB22837568, " +
+		"also ccc572052d6446a2b896fee381dcca3a again."
+
+	tests := []struct {
+		name        string
+		attachments []jira.Attachment
+		wantIDs     string
+		wantSource  string
+	}{
+		{
+			name: "csv attachments take precedence",
+			attachments: []jira.Attachment{
+				{Filename: "ids.txt", URL: "txt"},
+				{Filename: "recon.csv", URL: "csv"},
+			},
+			wantIDs:    "e2e-1,t2",
+			wantSource: SourceCSVAttachments,
+		},
+		{
+			name:        "text attachments",
+			attachments: []jira.Attachment{{Filename: "TS-1.TXT", URL: "txt"}, {Filename: "shot.png", URL: "png"}},
+			wantIDs:     "id-1,id-2",
+			wantSource:  SourceTextAttachments,
+		},
+		{
+			name:       "description",
+			wantIDs:    "ccc572052d6446a2b896fee381dcca3a,20251228GXSPMYKL010ORB22837568",
+			wantSource: SourceDescription,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeJira{contents: map[string]string{"csv": csv, "txt": "id-1\n\n id-2\nid-1\n"}}
+			ticket := &jira.JiraTicket{Key: "TS-1", Description: description, Attachments: tt.attachments}
+
+			ids, source, err := ExtractTransactionIDs(context.Background(), client, ticket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(ids, ","); got != tt.wantIDs {
+				t.Errorf("expected IDs %s, got %s", tt.wantIDs, got)
+			}
+			if source != tt.wantSource {
+				t.Errorf("expected source %q, got %q", tt.wantSource, source)
+			}
+		})
+	}
+
+	t.Run("no IDs", func(t *testing.T) {
+		_, _, err := ExtractTransactionIDs(context.Background(), &fakeJira{}, &jira.JiraTicket{Key: "TS-1", Description: "nothing here"})
+		if err == nil {
+			t.Error("expected an error when the ticket has no IDs")
+		}
+	})
+}
+
+func TestProcessSummary_SummaryComment(t *testing.T) {
+	summary := &ProcessSummary{
+		TicketID: "TS-1",
+		Source:   SourceCSVAttachments,
+		IDs:      []string{"a", "b", "c"},
+		Results: []domain.TransactionResult{
+			{InputID: "a", CaseType: domain.CaseCashoutRpp210Pe220Pc201},
+			{InputID: "b", CaseType: domain.CaseCashoutRpp210Pe220Pc201},
+			{InputID: "c", CaseType: domain.CaseNone},
+		},
+		SQLGenerated:   true,
+		SQLFiles:       []string{"PE_Deploy.sql", "PE_Rollback.sql"},
+		Attached:       []string{"TS-1_results.txt"},
+		DoormanTickets: []doorman.CreatedTicket{{Service: "payment_engine", ID: "42", URL: "https://doorman.example/rds/dml/42"}},
+	}
+
+	body, err := json.Marshal(summary.SummaryComment("mybuddy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`"type":"doc"`,
+		"mybuddy processed 3 transaction(s) from the CSV attachments of this ticket (3 queried).",
+		"cashout_rpp210_pe220_pc201: 2",
+		"unmatched: 1",
+		"PE_Rollback.sql",
+		"Attached to this ticket:",
+		"TS-1_results.txt",
+		"payment_engine: https://doorman.example/rds/dml/42",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected comment to contain %q, got %s", want, body)
+		}
+	}
+
+	summary.SQLGenerated = false
+	if body, _ := json.Marshal(summary.SummaryComment("mybuddy")); strings.Contains(string(body), "No SQL fixes") {
+		t.Error("expected no SQL section when SQL generation was skipped")
+	}
+}
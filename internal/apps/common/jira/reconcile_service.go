@@ -0,0 +1,300 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"buddy/internal/clients/jira"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+)
+
+// PayNet transaction statuses reported in reconciliation CSVs
+const (
+	PaynetAccepted          = "ACSP" // accepted, settlement in process
+	PaynetAcceptedTechnical = "ACTC" // accepted after technical validation; treated as ACSP
+	PaynetRejected          = "RJCT" // rejected
+)
+
+// Outcomes of a transaction on our side
+const (
+	OutcomeSuccess = "SUCCESS"
+	OutcomeFailed  = "FAILED"
+	OutcomePending = "PENDING"
+)
+
+// ReconcileStatus compares our outcome of a transaction with PayNet's
+type ReconcileStatus string
+
+const (
+	ReconcileMatch    ReconcileStatus = "MATCH"    // our outcome agrees with PayNet
+	ReconcileMismatch ReconcileStatus = "MISMATCH" // PayNet reached an outcome our state disagrees with
+	ReconcileUnknown  ReconcileStatus = "UNKNOWN"  // the PayNet status is neither accepted nor RJCT
+	ReconcileError    ReconcileStatus = "ERROR"    // the transaction could not be queried
+)
+
+// TransactionQuerier looks up a transaction by ID for an environment
+type TransactionQuerier interface {
+	QueryTransactionWithEnv(inputID string, env string) *domain.TransactionResult
+}
+
+// ReconcileRow is the reconciliation of one CSV row
+type ReconcileRow struct {
+	Attachment     string
+	EndToEndID     string
+	PaynetStatus   string
+	InternalStatus string // status column of the CSV as exported by ops
+	Outcome        string // our outcome: SUCCESS, FAILED or PENDING
+	Case           domain.Case
+	Decision       adapters.Decision // decision taken from PayNet for cases that need one
+	Status         ReconcileStatus
+	Detail         string
+	Result         *domain.TransactionResult
+}
+
+// ReconcileReport holds the reconciliation of every CSV row of a ticket
+type ReconcileReport struct {
+	TicketID string
+	Rows     []ReconcileRow
+}
+
+// Mismatches returns the rows where our state disagrees with PayNet
+func (r *ReconcileReport) Mismatches() []ReconcileRow {
+	var rows []ReconcileRow
+	for _, row := range r.Rows {
+		if row.Status == ReconcileMismatch {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Results returns the queried transactions to generate SQL for: matches, and
+// mismatches that PayNet decided. Other mismatches are left out since their SOP
+// fix could contradict PayNet, as are rows whose PayNet status is unknown or
+// whose transaction could not be queried; they are left for manual review.
+func (r *ReconcileReport) Results() []domain.TransactionResult {
+	var results []domain.TransactionResult
+	for _, row := range r.Rows {
+		if row.Result == nil {
+			continue
+		}
+		switch {
+		case row.Status == ReconcileMatch:
+		case row.Status == ReconcileMismatch && row.Decision != "":
+		default:
+			continue
+		}
+		results = append(results, *row.Result)
+	}
+	return results
+}
+
+// Decisions returns the decisions taken from PayNet, keyed by end-to-end ID.
+// Unless nonInteractive, cases PayNet could not decide are prompted for.
+func (r *ReconcileReport) Decisions(nonInteractive bool) *adapters.Decisions {
+	decisions := adapters.NewDecisions(nonInteractive)
+	for _, row := range r.Rows {
+		if row.Decision != "" {
+			decisions.ByID[row.EndToEndID] = row.Decision
+		}
+	}
+	return decisions
+}
+
+// ReconcileService cross-checks transactions against the PayNet status in the
+// CSV attachments of a Jira ticket
+type ReconcileService struct {
+	jiraClient jira.JiraInterface
+	txns       TransactionQuerier
+	env        string
+	onRow      func(endToEndID string)
+}
+
+// NewReconcileService creates a new reconcile service
+func NewReconcileService(jiraClient jira.JiraInterface, txns TransactionQuerier, env string) *ReconcileService {
+	return &ReconcileService{
+		jiraClient: jiraClient,
+		txns:       txns,
+		env:        env,
+	}
+}
+
+// OnRow sets a function called with the end-to-end ID of each row before it is
+// reconciled, e.g. to show progress
+func (s *ReconcileService) OnRow(fn func(endToEndID string)) *ReconcileService {
+	s.onRow = fn
+	return s
+}
+
+// Reconcile reads every CSV attachment of ticketID and reconciles each row that
+// has an end-to-end ID. An end-to-end ID listed again, e.g. in a re-attached
+// CSV, is reconciled only once, from the row it first appears in.
+func (s *ReconcileService) Reconcile(ctx context.Context, ticketID string) (*ReconcileReport, error) {
+	ticket, err := s.jiraClient.GetIssueDetails(ctx, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ticket %s: %w", ticketID, err)
+	}
+	if ticket == nil {
+		return nil, fmt.Errorf("ticket %s not found", ticketID)
+	}
+
+	report := &ReconcileReport{TicketID: ticketID}
+	seen := make(map[string]bool)
+	found := false
+	for _, attachment := range ticket.Attachments {
+		if !isCSVAttachment(attachment) {
+			continue
+		}
+		found = true
+
+		content, err := s.jiraClient.GetAttachmentContent(ctx, attachment.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download attachment %s: %w", attachment.Filename, err)
+		}
+		rows, err := s.jiraClient.ParseCSVAttachment(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse attachment %s: %w", attachment.Filename, err)
+		}
+
+		for _, row := range rows {
+			if row.EndToEndID == nil || seen[*row.EndToEndID] {
+				continue
+			}
+			seen[*row.EndToEndID] = true
+			if s.onRow != nil {
+				s.onRow(*row.EndToEndID)
+			}
+			report.Rows = append(report.Rows, s.reconcileRow(attachment.Filename, row))
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no CSV attachments found in ticket %s", ticketID)
+	}
+	return report, nil
+}
+
+// reconcileRow queries the transaction of one CSV row and compares it with PayNet
+func (s *ReconcileService) reconcileRow(attachment string, row jira.CSVRow) ReconcileRow {
+	reconciled := ReconcileRow{
+		Attachment:     attachment,
+		EndToEndID:     *row.EndToEndID,
+		PaynetStatus:   strings.ToUpper(valueOf(row.PaynetStatus)),
+		InternalStatus: valueOf(row.InternalStatus),
+	}
+
+	result := s.txns.QueryTransactionWithEnv(reconciled.EndToEndID, s.env)
+	if result == nil || result.Error != "" || result.QueryFailed() {
+		reconciled.Status = ReconcileError
+		reconciled.Detail = "transaction could not be queried"
+		if result != nil && result.Error != "" {
+			reconciled.Detail = result.Error
+		}
+		return reconciled
+	}
+
+	reconciled.Result = result
+	compareWithPaynet(&reconciled)
+	return reconciled
+}
+
+// compareWithPaynet sets the outcome, status and decision of a row whose
+// transaction has been queried
+func compareWithPaynet(row *ReconcileRow) {
+	row.Case = row.Result.CaseType
+	row.Outcome = transactionOutcome(row.Result)
+
+	var paynetOutcome string
+	var decision adapters.Decision
+	switch row.PaynetStatus {
+	case PaynetAccepted, PaynetAcceptedTechnical:
+		paynetOutcome, decision = OutcomeSuccess, adapters.DecisionAccept
+	case PaynetRejected:
+		paynetOutcome, decision = OutcomeFailed, adapters.DecisionReject
+	default:
+		row.Status = ReconcileUnknown
+		row.Detail = fmt.Sprintf("PayNet status %q is neither %s, %s nor %s", row.PaynetStatus, PaynetAccepted, PaynetAcceptedTechnical, PaynetRejected)
+		return
+	}
+
+	if row.Outcome == paynetOutcome {
+		row.Status = ReconcileMatch
+		return
+	}
+
+	row.Status = ReconcileMismatch
+	row.Detail = fmt.Sprintf("PayNet %s but transaction is %s", row.PaynetStatus, row.Outcome)
+	if row.Outcome == OutcomePending && adapters.NeedsDecision(row.Case) {
+		row.Decision = decision
+	}
+}
+
+// transactionOutcome classifies the payment-engine transfer status
+func transactionOutcome(result *domain.TransactionResult) string {
+	if result.PaymentEngine == nil {
+		return OutcomePending
+	}
+	switch strings.ToUpper(result.PaymentEngine.Transfers.Status) {
+	case "COMPLETED", "SUCCESS":
+		return OutcomeSuccess
+	case "FAILED", "REJECTED", "CANCELLED", "CANCELED":
+		return OutcomeFailed
+	default:
+		return OutcomePending
+	}
+}
+
+// WriteReconcileReport prints one row per reconciled transaction followed by the
+// mismatches and the rows that could not be compared
+func WriteReconcileReport(w io.Writer, report *ReconcileReport) {
+	_, _ = fmt.Fprintf(w, "\nReconciliation for %s (%d rows):\n", report.TicketID, len(report.Rows))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "E2E ID\tPAYNET\tCSV STATUS\tOUTCOME\tCASE\tDECISION\tRESULT")
+	for _, row := range report.Rows {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.EndToEndID, orDash(row.PaynetStatus), orDash(row.InternalStatus), orDash(row.Outcome),
+			orDash(string(row.Case)), orDash(string(row.Decision)), row.Status)
+	}
+	_ = tw.Flush()
+
+	mismatches := report.Mismatches()
+	if len(mismatches) == 0 {
+		_, _ = fmt.Fprintln(w, "\nNo mismatches with PayNet.")
+	} else {
+		_, _ = fmt.Fprintf(w, "\nMismatches with PayNet (%d):\n", len(mismatches))
+		for _, row := range mismatches {
+			action := ""
+			if row.Decision != "" {
+				action = fmt.Sprintf(" - will %s", row.Decision)
+			}
+			_, _ = fmt.Fprintf(w, "  ✗ %s (%s): %s%s\n", row.EndToEndID, row.Attachment, row.Detail, action)
+		}
+	}
+
+	for _, row := range report.Rows {
+		if row.Status == ReconcileError || row.Status == ReconcileUnknown {
+			_, _ = fmt.Fprintf(w, "  ? %s: %s\n", row.EndToEndID, row.Detail)
+		}
+	}
+}
+
+// orDash renders empty table cells as "-"
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// valueOf dereferences an optional CSV value
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
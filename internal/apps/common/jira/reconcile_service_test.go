@@ -0,0 +1,184 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"buddy/internal/clients/jira"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+)
+
+// fakeJira serves one ticket and its attachment contents from memory
+type fakeJira struct {
+	jira.JiraInterface
+	ticket   *jira.JiraTicket
+	contents map[string]string
+}
+
+func (f *fakeJira) GetIssueDetails(ctx context.Context, issueKey string) (*jira.JiraTicket, error) {
+	return f.ticket, nil
+}
+
+func (f *fakeJira) GetAttachmentContent(ctx context.Context, attachmentURL string) ([]byte, error) {
+	return []byte(f.contents[attachmentURL]), nil
+}
+
+func (f *fakeJira) ParseCSVAttachment(content string) ([]jira.CSVRow, error) {
+	return (&jira.JiraClient{}).ParseCSVAttachment(content)
+}
+
+// fakeTransactions serves query results by input ID
+type fakeTransactions map[string]*domain.TransactionResult
+
+func (f fakeTransactions) QueryTransactionWithEnv(inputID string, env string) *domain.TransactionResult {
+	return f[inputID]
+}
+
+func transferWithStatus(inputID, status string, caseType domain.Case) *domain.TransactionResult {
+	return &domain.TransactionResult{
+		InputID:       inputID,
+		CaseType:      caseType,
+		PaymentEngine: &domain.PaymentEngineInfo{Transfers: domain.PETransfersInfo{Status: status}},
+	}
+}
+
+func TestReconcileService_Reconcile(t *testing.T) {
+	csv := "Date,Batch ID,TAR02 BMID,Transaction ID,DBMY Status,TAR02 STS\n" +
+		"2025-10-17,b1,e2e-accept,t1,PROCESSING,ACSP\n" +
+		"2025-10-17,b2,e2e-reject,t2,PROCESSING,RJCT\n" +
+		"2025-10-17,b3,e2e-match,t3,COMPLETED,ACSP\n" +
+		"2025-10-17,b4,e2e-wrong,t4,COMPLETED,RJCT\n" +
+		"2025-10-17,b5,e2e-missing,t5,PROCESSING,ACSP\n" +
+		"2025-10-17,b6,e2e-odd,t6,PROCESSING,PDNG\n" +
+		"2025-10-17,b7,e2e-actc,t7,PROCESSING,ACTC\n"
+
+	client := &fakeJira{
+		ticket: &jira.JiraTicket{Key: "TS-1", Attachments: []jira.Attachment{
+			{Filename: "recon.csv", URL: "u1"},
+			{Filename: "screenshot.png", URL: "u2"},
+		}},
+		contents: map[string]string{"u1": csv},
+	}
+	txns := fakeTransactions{
+		"e2e-accept": transferWithStatus("e2e-accept", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+		"e2e-reject": transferWithStatus("e2e-reject", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+		"e2e-match":  transferWithStatus("e2e-match", "COMPLETED", domain.CaseNone),
+		"e2e-wrong":  transferWithStatus("e2e-wrong", "COMPLETED", domain.CaseNone),
+		"e2e-odd":    transferWithStatus("e2e-odd", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+		"e2e-actc":   transferWithStatus("e2e-actc", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+	}
+
+	var reconciled []string
+	report, err := NewReconcileService(client, txns, "my").
+		OnRow(func(endToEndID string) { reconciled = append(reconciled, endToEndID) }).
+		Reconcile(context.Background(), "TS-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reconciled) != len(report.Rows) {
+		t.Errorf("expected OnRow for every row, got %v", reconciled)
+	}
+
+	want := map[string]struct {
+		status   ReconcileStatus
+		decision adapters.Decision
+	}{
+		"e2e-accept":  {ReconcileMismatch, adapters.DecisionAccept},
+		"e2e-reject":  {ReconcileMismatch, adapters.DecisionReject},
+		"e2e-match":   {ReconcileMatch, ""},
+		"e2e-wrong":   {ReconcileMismatch, ""},
+		"e2e-missing": {ReconcileError, ""},
+		"e2e-odd":     {ReconcileUnknown, ""},
+		"e2e-actc":    {ReconcileMismatch, adapters.DecisionAccept},
+	}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(report.Rows))
+	}
+	for _, row := range report.Rows {
+		w := want[row.EndToEndID]
+		if row.Status != w.status || row.Decision != w.decision {
+			t.Errorf("%s: got %s/%q, want %s/%q", row.EndToEndID, row.Status, row.Decision, w.status, w.decision)
+		}
+	}
+
+	if got := len(report.Mismatches()); got != 4 {
+		t.Errorf("expected 4 mismatches, got %d", got)
+	}
+
+	// The undecided mismatch, the unknown PayNet status and the unqueried row get no SQL
+	var ids []string
+	for _, result := range report.Results() {
+		ids = append(ids, result.InputID)
+	}
+	if got := strings.Join(ids, ","); got != "e2e-accept,e2e-reject,e2e-match,e2e-actc" {
+		t.Errorf("unexpected results for SQL generation: %s", got)
+	}
+
+	decisions := report.Decisions(true)
+	if decision, err := decisions.Decide(*txns["e2e-reject"]); err != nil || decision != adapters.DecisionReject {
+		t.Errorf("expected reject for e2e-reject, got %q (%v)", decision, err)
+	}
+	if _, err := decisions.Decide(*txns["e2e-odd"]); err == nil {
+		t.Error("expected no decision for a PayNet status that is neither ACSP nor RJCT")
+	}
+
+	var out bytes.Buffer
+	WriteReconcileReport(&out, report)
+	if !strings.Contains(out.String(), "e2e-wrong (recon.csv): PayNet RJCT but transaction is SUCCESS") {
+		t.Errorf("expected the mismatch to be listed, got:\n%s", out.String())
+	}
+}
+
+func TestReconcileService_ReconcilesRepeatedIDsOnce(t *testing.T) {
+	header := "Date,Batch ID,TAR02 BMID,Transaction ID,DBMY Status,TAR02 STS\n"
+	client := &fakeJira{
+		ticket: &jira.JiraTicket{Key: "TS-1", Attachments: []jira.Attachment{
+			{Filename: "recon.csv", URL: "u1"},
+			{Filename: "recon-updated.csv", URL: "u2"},
+		}},
+		contents: map[string]string{
+			"u1": header + "2025-10-17,b1,e2e-1,t1,PROCESSING,ACSP\n" + "2025-10-17,b2,e2e-2,t2,PROCESSING,RJCT\n",
+			"u2": header + "2025-10-17,b2,e2e-2,t2,PROCESSING,RJCT\n" + "2025-10-17,b3,e2e-3,t3,PROCESSING,ACSP\n" +
+				"2025-10-17,b1,e2e-1,t1,PROCESSING,ACSP\n",
+		},
+	}
+
+	queried := make(map[string]int)
+	txns := countingTransactions{queried: queried, results: fakeTransactions{
+		"e2e-1": transferWithStatus("e2e-1", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+		"e2e-2": transferWithStatus("e2e-2", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+		"e2e-3": transferWithStatus("e2e-3", "PROCESSING", domain.CaseCashoutRpp210Pe220Pc201),
+	}}
+
+	report, err := NewReconcileService(client, txns, "my").Reconcile(context.Background(), "TS-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, row := range report.Rows {
+		ids = append(ids, row.EndToEndID+"@"+row.Attachment)
+	}
+	if got := strings.Join(ids, ","); got != "e2e-1@recon.csv,e2e-2@recon.csv,e2e-3@recon-updated.csv" {
+		t.Errorf("expected each ID once in first-seen order, got %s", got)
+	}
+	for id, count := range queried {
+		if count != 1 {
+			t.Errorf("expected %s to be queried once, got %d", id, count)
+		}
+	}
+}
+
+// countingTransactions counts the queries of each input ID
+type countingTransactions struct {
+	queried map[string]int
+	results fakeTransactions
+}
+
+func (c countingTransactions) QueryTransactionWithEnv(inputID string, env string) *domain.TransactionResult {
+	c.queried[inputID]++
+	return c.results[inputID]
+}
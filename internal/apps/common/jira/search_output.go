@@ -0,0 +1,75 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"buddy/internal/clients/jira"
+	"buddy/internal/ui"
+)
+
+// Output formats of jira search
+const (
+	SearchOutputLinks = "links" // one hyperlinked ticket key per line
+	SearchOutputTable = "table"
+	SearchOutputJSON  = "json"
+)
+
+// summaryWidth is the widest summary shown in the table output
+const summaryWidth = 80
+
+// ParseSearchOutput validates a jira search --output flag value
+func ParseSearchOutput(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "":
+		return SearchOutputLinks, nil
+	case SearchOutputLinks, SearchOutputTable, SearchOutputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (expected links, table or json)", value)
+	}
+}
+
+// WriteTickets writes search results in format; baseURL is the JIRA site the
+// ticket links point to
+func WriteTickets(w io.Writer, tickets []jira.JiraTicket, format string, baseURL string) error {
+	switch format {
+	case SearchOutputJSON:
+		if tickets == nil {
+			tickets = []jira.JiraTicket{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tickets)
+
+	case SearchOutputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(tw, "KEY\tTYPE\tSTATUS\tPRIORITY\tASSIGNEE\tCREATED\tSUMMARY"); err != nil {
+			return err
+		}
+		for _, ticket := range tickets {
+			created := ""
+			if !ticket.CreatedAt.IsZero() {
+				created = ticket.CreatedAt.Format("2006-01-02")
+			}
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				ticket.Key, orDash(ticket.IssueType), orDash(ticket.Status), orDash(ticket.Priority),
+				orDash(ticket.Assignee), orDash(created), ui.TruncateText(ticket.Summary, summaryWidth)); err != nil {
+				return err
+			}
+		}
+		return tw.Flush()
+
+	default:
+		for _, ticket := range tickets {
+			ticketURL := fmt.Sprintf("%s/browse/%s", baseURL, ticket.Key)
+			if _, err := fmt.Fprintln(w, ui.CreateHyperlink(ticketURL, ticket.Key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"buddy/internal/clients/jira"
+)
+
+func TestWriteTickets(t *testing.T) {
+	tickets := []jira.JiraTicket{{
+		Key:       "TS-1",
+		Summary:   "Stuck cashout",
+		Status:    "Open",
+		Priority:  "High",
+		IssueType: "Task",
+		CreatedAt: time.Date(2025, 10, 17, 9, 0, 0, 0, time.UTC),
+		Fields:    map[string]any{"customfield_1": "x"},
+	}}
+
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := WriteTickets(&out, tickets, SearchOutputTable, ""); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], "KEY") {
+			t.Fatalf("unexpected table:\n%s", out.String())
+		}
+		if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "TS-1 Task Open High - 2025-10-17 Stuck cashout" {
+			t.Errorf("unexpected row: %q", lines[1])
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := WriteTickets(&out, tickets, SearchOutputJSON, ""); err != nil {
+			t.Fatal(err)
+		}
+		var decoded []jira.JiraTicket
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) != 1 || decoded[0].Key != "TS-1" || decoded[0].Fields["customfield_1"] != "x" {
+			t.Errorf("unexpected JSON: %s", out.String())
+		}
+
+		out.Reset()
+		if err := WriteTickets(&out, nil, SearchOutputJSON, ""); err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(out.String()) != "[]" {
+			t.Errorf("expected an empty array, got %s", out.String())
+		}
+	})
+
+	if _, err := ParseSearchOutput("csv"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -15,5 +15,6 @@ func GetCommands(appCtx *common.Context, clients *di.ClientSet) []*cobra.Command
 		NewEcoTxnCmd(appCtx, clients),
 		NewJiraCmd(appCtx, clients),
 		NewDoormanCmd(appCtx, clients),
+		NewSopCmd(appCtx, clients),
 	}
 }
@@ -26,7 +26,10 @@ func isEmptyStatements(statements domain.SQLStatements) bool {
 }
 
 func NewEcoTxnCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
-	var createDML string
+	var (
+		createDML  string
+		outputFlag string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "ecotxn [run-id]",
@@ -36,15 +39,29 @@ This command specifically queries to charge table and displays workflow_charge i
 
 Example:
   mybuddy ecotxn fd230a01dcd04282851b7b9dd6260c93
-  mybuddy ecotxn fd230a01dcd04282851b7b9dd6260c93 --create-dml "TS-4558"`,
+  mybuddy ecotxn fd230a01dcd04282851b7b9dd6260c93 --create-dml "TS-4558"
+  mybuddy ecotxn fd230a01dcd04282851b7b9dd6260c93 --output json
+
+With --output json or ndjson the result and generated SQL are printed to stdout
+using the versioned result schema; no DML files are written in that mode.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			runID := args[0]
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			if output.IsStructured() {
+				processEcoTransactionStructured(appCtx, clients, runID, output)
+				return
+			}
 			processEcoTransaction(appCtx, clients, runID, createDML)
 		},
 	}
 
 	cmd.Flags().StringVar(&createDML, "create-dml", "", "Auto-create Doorman DML tickets with ticket ID (e.g., \"TS-4558\")")
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
 
 	return cmd
 }
@@ -95,3 +112,26 @@ func processEcoTransaction(appCtx *common.Context, clients *di.ClientSet, runID
 		fmt.Printf("\nSkipping DML generation for NOT_FOUND case\n")
 	}
 }
+
+// processEcoTransactionStructured writes the result and its generated SQL as a
+// structured document to stdout. Decision prompts, if any, go to stderr.
+func processEcoTransactionStructured(appCtx *common.Context, clients *di.ClientSet, runID string, output adapters.OutputFormat) {
+	result := clients.TxnSvc.QueryEcoTransactionWithEnv(runID, "my")
+
+	results := []domain.TransactionResult{*result}
+	var statements *domain.SQLStatements
+	if result.Error == "" && result.CaseType != "" && result.CaseType != domain.CaseNone {
+		// A failed decision is recorded on the result, which is written without SQL
+		if generated, err := adapters.GenerateSQLStatementsWithDecisions(results, adapters.NewPromptDecisionsTo(os.Stderr)); err == nil {
+			statements = &generated
+		}
+	}
+
+	if err := adapters.WriteStructuredResults(os.Stdout, output, results, statements); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError writing result: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
+	if results[0].Error != "" {
+		os.Exit(1)
+	}
+}
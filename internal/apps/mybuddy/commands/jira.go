@@ -7,6 +7,7 @@ import (
 	"buddy/internal/apps/common"
 	"buddy/internal/apps/common/jira"
 	clients "buddy/internal/clients/jira"
+	"buddy/internal/config"
 	"buddy/internal/di"
 	"buddy/internal/ui"
 
@@ -24,6 +25,8 @@ func NewJiraCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
 	jiraCmd.AddCommand(jira.NewJiraViewCmd(appCtx))
 	jiraCmd.AddCommand(jira.NewJiraSearchCmd(appCtx))
 	jiraCmd.AddCommand(NewJiraDownloadAttachmentCmd(appCtx, clients))
+	jiraCmd.AddCommand(NewJiraReconcileCmd(appCtx, clients))
+	jiraCmd.AddCommand(NewJiraProcessCmd(appCtx, clients))
 
 	return jiraCmd
 }
@@ -53,7 +56,7 @@ With --interactive: Opens interactive picker to select and work with tickets`,
 
 			// Validate JIRA username is configured
 			if jiraConfig.Auth.Username == "" {
-				fmt.Printf("Error: JIRA_USERNAME not configured. Please set it in your .env.%s file\n", appCtx.Environment)
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
 				os.Exit(1)
 			}
 
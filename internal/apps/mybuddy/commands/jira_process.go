@@ -0,0 +1,237 @@
+package mybuddy
+
+import (
+	"fmt"
+	"os"
+
+	"buddy/internal/apps/common"
+	commondoorman "buddy/internal/apps/common/doorman"
+	"buddy/internal/apps/common/jira"
+	"buddy/internal/config"
+	"buddy/internal/di"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+	"buddy/internal/txn/service"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+// NewJiraProcessCmd creates a command that runs the on-call flow for a ticket end
+// to end: extract IDs, query, generate SQL, raise Doorman tickets and comment back
+func NewJiraProcessCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var (
+		assumeYes      bool
+		nonInteractive bool
+		decisionsFile  string
+		skipSQL        bool
+		skipDoorman    bool
+		skipAttach     bool
+		skipComment    bool
+		batchOpts      = service.DefaultBatchOptions()
+	)
+
+	cmd := &cobra.Command{
+		Use:   "process [ticket-id]",
+		Short: "Query, fix and report back on the transactions of a JIRA ticket",
+		Long: `Run the on-call flow for a JIRA ticket in one go:
+
+  1. Fetch the ticket.
+  2. Extract transaction IDs from its CSV attachments (end-to-end ID, or transaction
+     ID, of each row), else from its .txt attachments (one ID per line), else from
+     transaction IDs and E2E IDs in the description.
+  3. Query every transaction and write <ticket>_results.txt.
+  4. Generate the remediation SQL into the usual Deploy/Rollback files.
+  5. Create Doorman DML tickets with the ticket key as note.
+  6. Attach the results file and the Deploy/Rollback SQL files to the ticket.
+  7. Post a summary comment on the ticket with the cases found, the SQL files and
+     the Doorman tickets.
+
+Every step is confirmed before it runs unless --yes is set. Steps 4 to 7 can be
+skipped with --skip-sql, --skip-doorman, --skip-attach and --skip-comment; skipping
+SQL generation also skips the Doorman tickets.
+
+Decisions for interactive cases such as cashout_rpp210_pe220_pc201 are prompted
+for, or taken from --decisions <file> (same format as txn --decisions).
+--non-interactive implies --yes and fails when a decision is missing.
+
+Examples:
+  mybuddy jira process TS-1234
+  mybuddy jira process TS-1234 --skip-doorman --skip-comment
+  mybuddy jira process TS-1234 --decisions decisions.yaml --non-interactive`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ticketID := args[0]
+			prefix := appCtx.GetPrefix()
+			assumeYes = assumeYes || nonInteractive
+
+			// Check if JIRA client is initialized
+			if clients.Jira == nil {
+				fmt.Printf("Error: JIRA client not initialized. Please ensure JIRA is properly configured.\n")
+				os.Exit(1)
+			}
+
+			// Validate JIRA username is configured
+			if jiraConfig := clients.GetJiraConfig(appCtx.Environment); jiraConfig.Auth.Username == "" {
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
+				os.Exit(1)
+			}
+
+			if decisionsFile != "" || nonInteractive {
+				batchOpts.Decisions = adapters.NewDecisions(nonInteractive)
+				if decisionsFile != "" {
+					if err := batchOpts.Decisions.LoadDecisionsFile(decisionsFile); err != nil {
+						fmt.Printf("%sError: %v\n", prefix, err)
+						os.Exit(1)
+					}
+				}
+			}
+
+			// Step 1: fetch the ticket
+			fmt.Printf("%sFetching %s...\n", prefix, ticketID)
+			ticket, err := clients.Jira.GetIssueDetails(cmd.Context(), ticketID)
+			if err != nil {
+				fmt.Printf("%sError fetching JIRA ticket %s: %v\n", prefix, ticketID, err)
+				os.Exit(1)
+			}
+			if ticket == nil {
+				fmt.Printf("%sError: Ticket %s not found\n", prefix, ticketID)
+				os.Exit(1)
+			}
+			fmt.Printf("%s%s: %s [%s]\n", prefix, ticket.Key, ticket.Summary, ticket.Status)
+
+			// Step 2: extract transaction IDs
+			ids, source, err := jira.ExtractTransactionIDs(cmd.Context(), clients.Jira, ticket)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", prefix, err)
+				os.Exit(1)
+			}
+			summary := &jira.ProcessSummary{TicketID: ticket.Key, Source: source, IDs: ids}
+			fmt.Printf("%sFound %d transaction IDs in the %s\n", prefix, len(ids), source)
+
+			// Step 3: query the transactions
+			if !confirmStep(fmt.Sprintf("Query %d transactions?", len(ids)), assumeYes) {
+				return
+			}
+			batchOpts.Prefix = prefix
+			for i, result := range clients.TxnSvc.QueryTransactionsWithEnv(ids, appCtx.Environment, batchOpts) {
+				if result != nil {
+					summary.Results = append(summary.Results, *result)
+				} else {
+					fmt.Printf("%sError processing transaction ID: %s\n", prefix, ids[i])
+				}
+			}
+			if len(summary.Results) == 0 {
+				fmt.Printf("%sNo transactions could be queried.\n", prefix)
+				os.Exit(1)
+			}
+
+			outputPath := ticket.Key + "_results.txt"
+			resultsWritten := true
+			if err := adapters.WriteBatchResults(summary.Results, outputPath); err != nil {
+				fmt.Printf("%sError writing batch results: %v\n", prefix, err)
+				resultsWritten = false
+			} else {
+				fmt.Printf("%sResults written to %s\n", prefix, outputPath)
+			}
+			for _, line := range summary.CaseCounts() {
+				fmt.Printf("%s  %s\n", prefix, line)
+			}
+
+			// Step 4: generate the remediation SQL
+			var statements domain.SQLStatements
+			if !skipSQL && confirmStep("Generate remediation SQL?", assumeYes) {
+				// Transactions that could not be queried completely get no SQL
+				var complete []domain.TransactionResult
+				for _, result := range summary.Results {
+					if result.Error == "" && !result.QueryFailed() {
+						complete = append(complete, result)
+					}
+				}
+				if skipped := len(summary.Results) - len(complete); skipped > 0 {
+					fmt.Printf("%sSkipping SQL for %d transaction(s) that could not be queried\n", prefix, skipped)
+				}
+
+				if statements, err = adapters.GenerateSQLStatementsWithDecisions(complete, batchOpts.DecisionProvider()); err != nil {
+					fmt.Printf("%sError generating SQL: %v\n", prefix, err)
+					os.Exit(1)
+				}
+
+				// Clear previous SQL files to avoid appending to old runs
+				adapters.ClearSQLFiles()
+				if summary.SQLFiles, err = adapters.WriteSQLFiles(statements, ticket.Key); err != nil {
+					fmt.Printf("%sError writing SQL files: %v\n", prefix, err)
+					os.Exit(1)
+				}
+				summary.SQLGenerated = true
+
+				if len(summary.SQLFiles) > 0 {
+					fmt.Printf("%sSQL DML files generated: %v\n", prefix, summary.SQLFiles)
+				} else {
+					fmt.Printf("%sNo SQL fixes required for these transactions.\n", prefix)
+				}
+			}
+
+			// Step 5: create the Doorman tickets, noted with the JIRA key
+			if !skipDoorman && len(summary.SQLFiles) > 0 && confirmStep(fmt.Sprintf("Create Doorman DML tickets noted %s?", ticket.Key), assumeYes) {
+				summary.DoormanTickets = commondoorman.PromptForDoormanTicket(clients.Doorman, statements, true, ticket.Key)
+			}
+
+			// Step 6: attach the results and SQL files to the ticket
+			var files []string
+			if resultsWritten {
+				files = append(files, outputPath)
+			}
+			files = append(files, summary.SQLFiles...)
+			if !skipAttach && len(files) > 0 && confirmStep(fmt.Sprintf("Attach %d files to %s?", len(files), ticket.Key), assumeYes) {
+				for _, file := range files {
+					if _, err := clients.Jira.UploadAttachment(cmd.Context(), ticket.Key, file); err != nil {
+						fmt.Printf("%sError attaching %s to %s: %v\n", prefix, file, ticket.Key, err)
+						continue
+					}
+					summary.Attached = append(summary.Attached, file)
+				}
+				fmt.Printf("%sAttached %d files to %s\n", prefix, len(summary.Attached), ticket.Key)
+			}
+
+			// Step 7: post the summary back on the ticket
+			if !skipComment && confirmStep(fmt.Sprintf("Post a summary comment on %s?", ticket.Key), assumeYes) {
+				if err := clients.Jira.AddComment(cmd.Context(), ticket.Key, summary.SummaryComment("mybuddy"), false); err != nil {
+					fmt.Printf("%sError adding comment to %s: %v\n", prefix, ticket.Key, err)
+					os.Exit(1)
+				}
+				fmt.Printf("%sSummary posted on %s\n", prefix, ticket.Key)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Run every step without asking for confirmation")
+	cmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; implies --yes and fails when an interactive case has no decision")
+	cmd.Flags().StringVar(&decisionsFile, "decisions", "", "YAML or CSV file of accept/reject decisions for interactive cases, by transaction ID or case")
+	cmd.Flags().BoolVar(&skipSQL, "skip-sql", false, "Do not generate SQL files; also skips the Doorman tickets")
+	cmd.Flags().BoolVar(&skipDoorman, "skip-doorman", false, "Do not create Doorman DML tickets")
+	cmd.Flags().BoolVar(&skipAttach, "skip-attach", false, "Do not attach the results and SQL files to the ticket")
+	cmd.Flags().BoolVar(&skipComment, "skip-comment", false, "Do not post a summary comment on the ticket")
+	cmd.Flags().IntVar(&batchOpts.Concurrency, "concurrency", batchOpts.Concurrency, "Number of transactions to query in parallel")
+
+	return cmd
+}
+
+// confirmStep asks whether to run a step of jira process; assumeYes skips the prompt
+func confirmStep(label string, assumeYes bool) bool {
+	if assumeYes {
+		return true
+	}
+
+	fmt.Println()
+	prompt := promptui.Select{
+		Label: label,
+		Items: []string{"Yes", "No"},
+	}
+	_, result, err := prompt.Run()
+	if err != nil {
+		fmt.Printf("Prompt failed %v\n", err)
+		return false
+	}
+	return result == "Yes"
+}
@@ -0,0 +1,103 @@
+package mybuddy
+
+import (
+	"fmt"
+	"os"
+
+	"buddy/internal/apps/common"
+	"buddy/internal/apps/common/jira"
+	"buddy/internal/config"
+	"buddy/internal/di"
+	"buddy/internal/txn/adapters"
+
+	"github.com/spf13/cobra"
+)
+
+// NewJiraReconcileCmd creates a command that cross-checks transactions against
+// the PayNet status in a ticket's CSV attachments
+func NewJiraReconcileCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var nonInteractive bool
+
+	cmd := &cobra.Command{
+		Use:   "reconcile [ticket-id]",
+		Short: "Reconcile transactions against the PayNet status in JIRA CSV attachments",
+		Long: `Read the CSV attachments of a JIRA ticket, query the transaction of every
+end-to-end ID and compare its outcome with the PayNet status of the row.
+
+PayNet ACSP (or ACTC) means the payment succeeded and RJCT that it was rejected. Rows where
+our state disagrees with PayNet are listed as mismatches. For cases that need an
+accept or reject decision, such as cashout_rpp210_pe220_pc201, the PayNet status
+decides: ACSP resumes the transaction to success and RJCT rejects it.
+
+SQL is then generated for the identified cases and written to the usual
+Deploy/Rollback files. Other mismatches get no SQL, since their SOP fix could
+contradict PayNet, and neither do rows with any other PayNet status or whose
+transaction could not be queried; review them by hand. Cases PayNet could not decide are prompted
+for, unless --non-interactive is set, in which case no SQL is generated.
+
+Examples:
+  mybuddy jira reconcile TS-1234
+  mybuddy jira reconcile TS-1234 --non-interactive`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ticketID := args[0]
+
+			// Check if JIRA client is initialized
+			if clients.Jira == nil {
+				fmt.Printf("Error: JIRA client not initialized. Please ensure JIRA is properly configured.\n")
+				os.Exit(1)
+			}
+
+			// Validate JIRA username is configured
+			if jiraConfig := clients.GetJiraConfig(appCtx.Environment); jiraConfig.Auth.Username == "" {
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
+				os.Exit(1)
+			}
+
+			fmt.Printf("%sReconciling CSV attachments of %s against PayNet...\n", appCtx.GetPrefix(), ticketID)
+			reconcileService := jira.NewReconcileService(clients.Jira, clients.TxnSvc, appCtx.Environment).
+				OnRow(func(endToEndID string) {
+					fmt.Printf("%sReconciling %s...\n", appCtx.GetPrefix(), endToEndID)
+				})
+			report, err := reconcileService.Reconcile(cmd.Context(), ticketID)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			jira.WriteReconcileReport(os.Stdout, report)
+
+			results := report.Results()
+			if len(results) == 0 {
+				return
+			}
+
+			statements, err := adapters.GenerateSQLStatementsWithDecisions(results, report.Decisions(nonInteractive))
+			if err != nil {
+				fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			// Clear previous SQL files to avoid appending to old runs
+			adapters.ClearSQLFiles()
+			filesCreated, err := adapters.WriteSQLFiles(statements, ticketID)
+			if err != nil {
+				fmt.Printf("%sError writing SQL files: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			if len(filesCreated) == 0 {
+				fmt.Printf("%sNo SQL fixes required for these transactions.\n", appCtx.GetPrefix())
+				return
+			}
+			fmt.Printf("%sSQL DML files generated: %v\n", appCtx.GetPrefix(), filesCreated)
+
+			if !nonInteractive {
+				PromptForDoormanTicket(appCtx, clients, statements, false, "")
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; fail when PayNet cannot decide a case and skip the Doorman ticket prompt")
+
+	return cmd
+}
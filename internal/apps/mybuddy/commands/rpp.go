@@ -18,6 +18,7 @@ Useful for managing workflows in the RPP adapter system.`,
 	// Add subcommands
 	cmd.AddCommand(NewRppResumeCmd(appCtx, clients))
 	cmd.AddCommand(NewRppRtpCashinCmd(appCtx, clients))
+	cmd.AddCommand(NewRppQrInvalidateCmd(appCtx, clients))
 
 	return cmd
 }
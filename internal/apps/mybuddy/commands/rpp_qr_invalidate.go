@@ -0,0 +1,108 @@
+package mybuddy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"buddy/internal/apps/common"
+	"buddy/internal/di"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+	"buddy/internal/txn/utils"
+
+	"github.com/spf13/cobra"
+)
+
+func NewRppQrInvalidateCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "qr-invalidate [user-id-or-file]",
+		Short: "Invalidate the active QR code of users who changed their name",
+		Long: `Generate SQL that marks a user's ACTIVE QR code as INACTIVE, so the app
+generates a new QR code with the user's new name (SOP case
+user_name_change_qr_invalidation).
+
+The command looks up the ACTIVE rows in the RPP adapter qr_code table and pins
+the deploy and rollback SQL to them. Users without an ACTIVE QR code are skipped.
+
+Supported inputs:
+- Single user ID
+- File path containing multiple user IDs (one per line)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			processRppQrInvalidate(appCtx, clients, args[0])
+		},
+	}
+	return cmd
+}
+
+func processRppQrInvalidate(appCtx *common.Context, clients *di.ClientSet, input string) {
+	userIDs := []string{input}
+	if _, err := os.Stat(input); err == nil {
+		ids, err := utils.ReadTransactionIDsFromFile(input)
+		if err != nil {
+			fmt.Printf("%sError reading file: %v\n", appCtx.GetPrefix(), err)
+			return
+		}
+		userIDs = ids
+	}
+
+	ticket := &domain.DMLTicket{CaseType: domain.CaseUserNameChangeQrInvalidation}
+	for _, userID := range userIDs {
+		createdAts, err := activeQrCodes(clients, userID)
+		if err != nil {
+			fmt.Printf("%sError querying QR codes for user %s: %v\n", appCtx.GetPrefix(), userID, err)
+			continue
+		}
+		userTicket := adapters.GetDMLTicketForUserNameChangeQrInvalidation(userID, createdAts)
+		if userTicket == nil {
+			fmt.Printf("%sNo ACTIVE QR code found for user %s\n", appCtx.GetPrefix(), userID)
+			continue
+		}
+		fmt.Printf("%sFound %d ACTIVE QR code(s) for user %s\n", appCtx.GetPrefix(), len(createdAts), userID)
+		ticket.Deploy = append(ticket.Deploy, userTicket.Deploy...)
+		ticket.Rollback = append(ticket.Rollback, userTicket.Rollback...)
+	}
+
+	if len(ticket.Deploy) == 0 {
+		fmt.Printf("%sNo SQL generated\n", appCtx.GetPrefix())
+		return
+	}
+
+	statements, err := adapters.GenerateSQLFromTicket(*ticket)
+	if err != nil {
+		fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+		return
+	}
+	if err := adapters.VerifySQLStatements(statements); err != nil {
+		fmt.Printf("%sError verifying SQL: %v\n", appCtx.GetPrefix(), err)
+		return
+	}
+
+	fmt.Printf("\n%s--- RPP Deploy SQL ---\n", appCtx.GetPrefix())
+	for _, stmt := range statements.RPPDeployStatements {
+		fmt.Println(stmt)
+	}
+	fmt.Printf("\n%s--- RPP Rollback SQL ---\n", appCtx.GetPrefix())
+	for _, stmt := range statements.RPPRollbackStatements {
+		fmt.Println(stmt)
+	}
+}
+
+// activeQrCodes returns the created_at of every ACTIVE QR code of userID
+func activeQrCodes(clients *di.ClientSet, userID string) ([]string, error) {
+	query := fmt.Sprintf("SELECT user_id, status, created_at FROM qr_code WHERE user_id = '%s' AND status = 'ACTIVE'",
+		strings.ReplaceAll(userID, "'", "''"))
+	rows, err := clients.Doorman.QueryRppAdapter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdAts []string
+	for _, row := range rows {
+		if createdAt := utils.GetStringValue(row, "created_at"); createdAt != "" {
+			createdAts = append(createdAts, createdAt)
+		}
+	}
+	return createdAts, nil
+}
@@ -14,6 +14,8 @@ import (
 )
 
 func NewRppResumeCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var outputFlag string
+
 	cmd := &cobra.Command{
 		Use:   "resume [e2e-id-or-file]",
 		Short: "Resume stuck RPP workflows (state=210, attempt=0)",
@@ -25,22 +27,35 @@ SQL to move workflows from state 210 to state 222 for resuming.
 
 Supported inputs:
 - Single RPP E2E ID (format: YYYYMMDDGXSPMYXXXXXXXXXXXXXXXX)
-- File path containing multiple E2E IDs (one per line)`,
+- File path containing multiple E2E IDs (one per line)
+
+Output (--output):
+text (default) prints human-readable sections. json and ndjson print the
+versioned result schema to stdout, with status messages on stderr.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			input := args[0]
-			processRppResume(appCtx, clients, input)
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			processRppResume(appCtx, clients, input, output)
 		},
 	}
 
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
+
 	return cmd
 }
 
-func processRppResume(appCtx *common.Context, clients *di.ClientSet, input string) {
+func processRppResume(appCtx *common.Context, clients *di.ClientSet, input string, output adapters.OutputFormat) {
 	// Check if input is a file
 	if _, err := os.Stat(input); err == nil {
 		// Process as batch file using the new batch processor
-		batch.ProcessRPPResumeFile(appCtx, clients, input)
+		batch.ProcessRPPResumeFile(appCtx, clients, input, output)
+	} else if output.IsStructured() {
+		processSingleE2EStructured(appCtx, clients, input, output)
 	} else {
 		// Process as single E2E ID
 		fmt.Printf("%sResuming RPP workflow for E2E ID: %s\n", appCtx.GetPrefix(), input)
@@ -86,6 +101,10 @@ func generateRppResumeSQL(appCtx *common.Context, result domain.TransactionResul
 		fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
 		return
 	}
+	if err := adapters.VerifySQLStatements(statements); err != nil {
+		fmt.Printf("%sError verifying SQL: %v\n", appCtx.GetPrefix(), err)
+		return
+	}
 
 	// Output to console
 	if len(statements.RPPDeployStatements) > 0 {
@@ -101,3 +120,39 @@ func generateRppResumeSQL(appCtx *common.Context, result domain.TransactionResul
 		}
 	}
 }
+
+// processSingleE2EStructured writes the result, and the resume SQL when the E2E ID
+// matches the resume criteria, as a structured document to stdout
+func processSingleE2EStructured(appCtx *common.Context, clients *di.ClientSet, e2eID string, output adapters.OutputFormat) {
+	result := clients.TxnSvc.QueryTransactionWithEnv(e2eID, appCtx.Environment)
+	if result == nil {
+		fmt.Fprintf(os.Stderr, "%sError retrieving transaction details for E2E ID: %s\n", appCtx.GetPrefix(), e2eID)
+		os.Exit(1)
+	}
+
+	var statements *domain.SQLStatements
+	if result.Error == "" {
+		adapters.SOPRepo.IdentifyCase(result, "my")
+		if result.CaseType == domain.CaseRppNoResponseResume {
+			if ticket := adapters.GetDMLTicketForRppResume(*result); ticket != nil {
+				generated, err := adapters.GenerateSQLFromTicket(*ticket)
+				if err == nil {
+					err = adapters.VerifySQLStatements(generated)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+				} else {
+					statements = &generated
+				}
+			}
+		}
+	}
+
+	if err := adapters.WriteStructuredResults(os.Stdout, output, []domain.TransactionResult{*result}, statements); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError writing result: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
+	if result.Error != "" {
+		os.Exit(1)
+	}
+}
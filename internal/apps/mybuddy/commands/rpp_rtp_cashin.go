@@ -71,6 +71,10 @@ func generateRppRtpCashinSQL(appCtx *common.Context, result domain.TransactionRe
 		fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
 		return
 	}
+	if err := adapters.VerifySQLStatements(statements); err != nil {
+		fmt.Printf("%sError verifying SQL: %v\n", appCtx.GetPrefix(), err)
+		return
+	}
 
 	// Output SQL to console
 	if len(statements.PPEDeployStatements) > 0 {
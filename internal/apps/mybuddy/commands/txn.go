@@ -9,12 +9,22 @@ import (
 	"buddy/internal/di"
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
+	"buddy/internal/txn/service"
 
 	"github.com/spf13/cobra"
 )
 
 func NewTxnCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
-	var autoMode bool
+	var (
+		autoMode       bool
+		decisionsFile  string
+		nonInteractive bool
+		recordDir      string
+		replayDir      string
+		outputFlag     string
+		cacheOpts      service.QueryCacheOptions
+		batchOpts      = service.DefaultBatchOptions()
+	)
 
 	cmd := &cobra.Command{
 		Use:   "txn [transaction-id-or-e2e-id-or-file]",
@@ -26,31 +36,172 @@ and file paths containing multiple transaction IDs.
 Auto Mode (--auto):
 When processing a batch file, automatically resume transactions if the Jira ticket title
 contains "Debit Account confirmation" or "Credit Account confirmation". The Jira ID is
-extracted from the filename (e.g., TS-4583.txt -> TS-4583).`,
+extracted from the filename (e.g., TS-4583.txt -> TS-4583).
+
+Record/Replay (--record, --replay):
+--record <dir> saves every query and response made to Doorman into <dir>.
+--replay <dir> serves responses from a recorded directory instead of Doorman,
+so a teammate's result can be reproduced without production access.
+
+Concurrency (--concurrency, --rate):
+Batch files are queried with --concurrency workers in parallel, starting at most
+--rate transactions per second. Results keep the input order, and any interactive
+prompts are shown only after all transactions have been queried.
+
+Resume (--resume):
+Every completed transaction of a batch file is saved to <file>.buddy-state.ndjson.
+If a run is interrupted, rerun it with --resume to skip IDs that already completed;
+their saved results are merged back in before SQL generation. The checkpoint is
+removed once a run finishes with every transaction completed. A new run refuses to
+start while a checkpoint exists; pass --fresh to discard it and start over.
+
+Output (--output):
+text (default) prints human-readable sections. json and ndjson use a versioned
+schema with every adapter section, formatted workflow states, the identified case
+and the generated deploy/rollback SQL. A single ID is written to stdout; a batch
+file writes <file>_results.json or <file>_results.ndjson instead of _results.txt.
+
+Verify (txn verify):
+After the generated SQL is deployed, "txn verify <file>_results.json" re-queries each
+transaction and reports whether it reached the target state of its case.
+
+Query cache (--no-cache, --cache-dir, --cache-ttl):
+Identical queries within a run, such as the same workflow_execution lookup for
+many transactions, are sent to Doorman once and reused for --cache-ttl.
+--cache-dir keeps results on disk so later runs can reuse them; --no-cache
+always asks Doorman.
+
+Bulk population (--bulk-size):
+Batch files fetch transfers, payment-core transactions and workflows with
+WHERE ... IN (...) queries of --bulk-size IDs each before populating every
+transaction, so a 1000-ID file takes a few dozen Doorman round trips.
+--bulk-size 0 queries each transaction separately.
+
+Decisions (--decisions, --non-interactive):
+Cases such as cashout_rpp210_pe220_pc201 need an accept (resume to success) or
+reject decision before SQL can be generated, which is normally asked on stdin.
+--decisions <file> supplies them up front, keyed by transaction ID, E2E ID or case:
+  YAML (.yaml, .yml):
+    transactions:
+      20251017GXSPMYXXXXXXXXXXXXXXXX: reject
+    cases:
+      cashout_rpp210_pe220_pc201: accept
+  CSV (any other extension), one "<transaction-id-or-case>,<accept|reject>" per line.
+Transaction IDs take precedence over cases, and the file over --auto. Missing decisions
+are still prompted for, unless --non-interactive is set: then the run fails before any
+SQL is generated and no Doorman ticket is offered, so batches can run without a TTY.
+With --output json or ndjson the prompts are written to stderr, leaving stdout to the
+results.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			input := args[0]
-			processInput(appCtx, clients, input, autoMode)
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			batchOpts.Output = output
+
+			if err := clients.TxnSvc.ConfigureRecordReplay(recordDir, replayDir); err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			cacheOpts.Namespace = appCtx.Profile
+			if cacheOpts.Namespace == "" {
+				cacheOpts.Namespace = appCtx.Environment
+			}
+			if err := clients.TxnSvc.ConfigureQueryCache(cacheOpts); err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			if decisionsFile != "" || nonInteractive {
+				batchOpts.Decisions = adapters.NewDecisions(nonInteractive)
+				if decisionsFile != "" {
+					if err := batchOpts.Decisions.LoadDecisionsFile(decisionsFile); err != nil {
+						fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+						os.Exit(1)
+					}
+				}
+			}
+			processInput(appCtx, clients, input, autoMode, batchOpts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&autoMode, "auto", false, "Auto-resume mode for batch processing based on Jira ticket title")
+	cmd.Flags().StringVar(&decisionsFile, "decisions", "", "YAML or CSV file of accept/reject decisions for interactive cases, by transaction ID or case")
+	cmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; fail when an interactive case has no decision and skip the Doorman ticket prompt")
+	cmd.Flags().StringVar(&recordDir, "record", "", "Record Doorman queries and responses into this directory")
+	cmd.Flags().StringVar(&replayDir, "replay", "", "Replay Doorman responses from a recorded directory")
+	cmd.Flags().BoolVar(&cacheOpts.Disabled, "no-cache", false, "Send every query to Doorman instead of reusing results of identical queries")
+	cmd.Flags().StringVar(&cacheOpts.Dir, "cache-dir", "", "Keep query results in this directory so later runs can reuse them")
+	cmd.Flags().DurationVar(&cacheOpts.TTL, "cache-ttl", service.DefaultQueryCacheTTL, "How long a cached query result is reused")
+	cmd.Flags().IntVar(&batchOpts.Concurrency, "concurrency", batchOpts.Concurrency, "Number of transactions to query in parallel for batch files")
+	cmd.Flags().Float64Var(&batchOpts.RatePerSecond, "rate", batchOpts.RatePerSecond, "Maximum transactions started per second for batch files (0 for no limit)")
+	cmd.Flags().IntVar(&batchOpts.BulkSize, "bulk-size", batchOpts.BulkSize, "IDs fetched per IN (...) query for batch files (0 queries each transaction separately)")
+	cmd.Flags().BoolVar(&batchOpts.Resume, "resume", false, "Resume an interrupted batch run from its .buddy-state.ndjson checkpoint")
+	cmd.Flags().BoolVar(&batchOpts.Fresh, "fresh", false, "Discard an existing .buddy-state.ndjson checkpoint and start the batch run over")
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
+
+	cmd.AddCommand(NewTxnVerifyCmd(appCtx, clients))
 
 	return cmd
 }
 
-func processInput(appCtx *common.Context, clients *di.ClientSet, input string, autoMode bool) {
+// NewTxnVerifyCmd creates a command that re-checks transactions after their DML ran
+func NewTxnVerifyCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var (
+		caseFlag  string
+		batchOpts = service.DefaultBatchOptions()
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify [results-file-or-id-file-or-transaction-id]",
+		Short: "Check whether deployed remediation SQL took effect",
+		Long: `Re-query transactions after their DML was deployed and compare the new workflow
+states with the target states declared for the SOP case that generated the SQL.
+
+Each transaction is reported as LANDED (reached a target state), STILL_STUCK (still
+matches the original case), MOVED (now matches a different SOP case), UNCONFIRMED
+(matches no case but has not reached a target state) or ERROR.
+
+Pass the results file written by --output json or ndjson to use the recorded case of
+each transaction, or a file of IDs or a single ID together with --case:
+  mybuddy txn verify TS-4583.txt_results.json
+  mybuddy txn verify TS-4583.txt --case <case>
+  mybuddy txn verify <transaction-id> --case <case>
+
+Exits non-zero unless every transaction landed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !batch.VerifyFixes(appCtx, clients, args[0], caseFlag, batchOpts) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&caseFlag, "case", "", "SOP case whose SQL was deployed (overrides the case recorded in a results file)")
+	cmd.Flags().IntVar(&batchOpts.Concurrency, "concurrency", batchOpts.Concurrency, "Number of transactions to query in parallel")
+	cmd.Flags().Float64Var(&batchOpts.RatePerSecond, "rate", batchOpts.RatePerSecond, "Maximum transactions started per second (0 for no limit)")
+
+	return cmd
+}
+
+func processInput(appCtx *common.Context, clients *di.ClientSet, input string, autoMode bool, batchOpts service.BatchOptions) {
 	// Check if input is a file
 	if _, err := os.Stat(input); err == nil {
 		// Process as batch file using the new batch processor
-		batch.ProcessTransactionFile(appCtx, clients, input, autoMode)
+		batch.ProcessTransactionFile(appCtx, clients, input, autoMode, batchOpts)
+	} else if batchOpts.Output.IsStructured() {
+		processSingleTransactionStructured(appCtx, clients, input, batchOpts)
 	} else {
 		// Process as single transaction ID
-		processSingleTransaction(appCtx, clients, input)
+		processSingleTransaction(appCtx, clients, input, batchOpts)
 	}
 }
 
-func processSingleTransaction(appCtx *common.Context, clients *di.ClientSet, transactionID string) {
+func processSingleTransaction(appCtx *common.Context, clients *di.ClientSet, transactionID string, batchOpts service.BatchOptions) {
 	// Use the injected transaction service
 	txnService := clients.TxnSvc
 
@@ -69,21 +220,55 @@ func processSingleTransaction(appCtx *common.Context, clients *di.ClientSet, tra
 	paymentEngineNotFound := result.PaymentEngine != nil && result.PaymentEngine.Transfers.Status == domain.NotFoundStatus
 	partnerpayEngineNotFound := result.PartnerpayEngine != nil && result.PartnerpayEngine.Charge.Status == domain.NotFoundStatus
 
-	if paymentEngineNotFound || partnerpayEngineNotFound || result.Error != "" {
+	if paymentEngineNotFound || partnerpayEngineNotFound || result.Error != "" || result.QueryFailed() {
 		fmt.Printf("Failed to retrieve complete transaction details: %+v\n", *result)
 		return
 	}
 
 	// 3. Generate SQL
-	// The txn package handles interactive prompts (for RPP cases) inside GenerateSQLStatements
+	// Interactive cases (for RPP) are decided by the run's decision provider
 	results := []domain.TransactionResult{*result}
-	statements := adapters.GenerateSQLStatements(results)
+	statements, err := adapters.GenerateSQLStatementsWithDecisions(results, batchOpts.DecisionProvider())
+	if err != nil {
+		fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
 
 	// 4. Output SQL to console
 	printSQLToConsole(appCtx, statements)
 
 	// 5. Prompt to create Doorman DML
-	PromptForDoormanTicket(appCtx, clients, statements, false, "")
+	if batchOpts.Interactive() {
+		PromptForDoormanTicket(appCtx, clients, statements, false, "")
+	}
+}
+
+// processSingleTransactionStructured writes the result and its generated SQL as a
+// structured document to stdout. No Doorman ticket is offered in this mode, and
+// decision prompts for interactive cases go to stderr.
+func processSingleTransactionStructured(appCtx *common.Context, clients *di.ClientSet, transactionID string, batchOpts service.BatchOptions) {
+	result := clients.TxnSvc.QueryTransactionWithEnv(transactionID, "my")
+	if result == nil {
+		fmt.Fprintf(os.Stderr, "%sError retrieving transaction details for ID: %s\n", appCtx.GetPrefix(), transactionID)
+		os.Exit(1)
+	}
+
+	results := []domain.TransactionResult{*result}
+	var statements *domain.SQLStatements
+	if result.Error == "" && !result.QueryFailed() {
+		// A failed decision is recorded on the result, which is written without SQL
+		if generated, err := adapters.GenerateSQLStatementsWithDecisions(results, batchOpts.DecisionProvider()); err == nil {
+			statements = &generated
+		}
+	}
+
+	if err := adapters.WriteStructuredResults(os.Stdout, batchOpts.Output, results, statements); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError writing result: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
+	if results[0].Error != "" || results[0].QueryFailed() {
+		os.Exit(1)
+	}
 }
 
 func printSQLToConsole(appCtx *common.Context, statements domain.SQLStatements) {
@@ -15,5 +15,6 @@ func GetCommands(appCtx *common.Context, clients *di.ClientSet) []*cobra.Command
 		NewPayNowCmd(appCtx, clients),
 		NewDatadogCmd(appCtx, clients),
 		NewDoormanCmd(appCtx, clients),
+		NewSopCmd(appCtx, clients),
 	}
 }
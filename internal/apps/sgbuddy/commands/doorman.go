@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"buddy/internal/apps/common"
+	commondoorman "buddy/internal/apps/common/doorman"
+	"buddy/internal/clients/doorman"
 	"buddy/internal/di"
 	"buddy/internal/logging"
 
@@ -23,6 +26,7 @@ func NewDoormanCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command
 
 	doormanCmd.AddCommand(NewDoormanCreateDMLCmd(appCtx, clients))
 	doormanCmd.AddCommand(NewDoormanQueryCmd(appCtx, clients))
+	doormanCmd.AddCommand(NewDoormanStatusCmd(appCtx, clients))
 
 	return doormanCmd
 }
@@ -78,12 +82,12 @@ func NewDoormanCreateDMLCmd(appCtx *common.Context, clients *di.ClientSet) *cobr
 				os.Exit(1)
 			}
 
-			// Construct ticket URL (hardcoded for Singapore environment)
-			ticketURL := fmt.Sprintf("https://doorman.sgbank.pr/rds/dml/%s", ticketID)
+			ticketURL := clients.Doorman.TicketURL(ticketID)
 
 			logger.Info("Ticket created successfully!")
 			logger.Info("Ticket ID: %s", ticketID)
 			logger.Info("Ticket URL: %s", ticketURL)
+			logger.Info("Track it with: %s doorman status %s --wait 30m", appCtx.BinaryName, ticketID)
 		},
 	}
 
@@ -241,3 +245,65 @@ func printJSONResults(rows []map[string]interface{}) {
 		fmt.Println(string(jsonBytes))
 	}
 }
+
+// NewDoormanStatusCmd creates a command to check or wait on DML ticket status
+func NewDoormanStatusCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var (
+		wait     time.Duration
+		interval time.Duration
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status [ticket-id...]",
+		Short: "Show the status of Doorman DML tickets",
+		Long: `Show whether Doorman DML tickets are pending, approved, executed or rejected.
+
+Without ticket IDs, your most recent tickets are listed. With --wait, unsettled
+tickets are polled every --interval until they are approved, executed or rejected,
+or until the wait runs out.
+
+Exits non-zero if a ticket could not be fetched or was rejected, or if --wait
+ran out before every ticket settled.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := logging.NewDefaultLogger("doorman")
+
+			if clients.Doorman == nil {
+				logger.Error("Doorman client not initialized")
+				os.Exit(1)
+			}
+
+			ticketIDs := args
+			if len(ticketIDs) == 0 {
+				tickets, err := clients.Doorman.ListTickets(doorman.TicketFilter{Limit: limit})
+				if err != nil {
+					logger.Error("Failed to list tickets: %v", err)
+					os.Exit(1)
+				}
+				if len(tickets) == 0 {
+					fmt.Println("No DML tickets found")
+					return
+				}
+				for _, ticket := range tickets {
+					ticketIDs = append(ticketIDs, fmt.Sprintf("%d", ticket.ID))
+				}
+			}
+
+			if wait > 0 {
+				fmt.Printf("%sWaiting up to %s for %d tickets to settle...\n", appCtx.GetPrefix(), wait, len(ticketIDs))
+			}
+			states := commondoorman.WatchTickets(clients.Doorman, ticketIDs, wait, interval, os.Stdout)
+			commondoorman.WriteTicketStates(os.Stdout, states)
+
+			if !commondoorman.TicketsSucceeded(states, wait > 0) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&wait, "wait", 0, "Poll until every ticket is approved, executed or rejected, for at most this long (e.g. 30m)")
+	cmd.Flags().DurationVar(&interval, "interval", commondoorman.DefaultTicketPollInterval, "How often to poll while waiting")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Number of recent tickets to show when no ticket IDs are given")
+
+	return cmd
+}
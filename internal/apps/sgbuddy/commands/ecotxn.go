@@ -1,6 +1,7 @@
 package sgbuddy
 
 import (
+	"fmt"
 	"os"
 
 	"buddy/internal/apps/common"
@@ -15,6 +16,7 @@ import (
 func NewEcoTxnCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
 	var publish bool
 	var createDML string
+	var outputFlag string
 
 	cmd := &cobra.Command{
 		Use:   "ecotxn <transaction-id>",
@@ -28,11 +30,24 @@ For publishing transactions (generating SQL scripts):
   sgbuddy ecotxn <transaction-id> --publish
 
 For auto-creating DML tickets:
-  sgbuddy ecotxn <transaction-id> --publish --create-dml "TSE-1234"`,
+  sgbuddy ecotxn <transaction-id> --publish --create-dml "TSE-1234"
+
+For machine-readable output of the view (versioned result schema):
+  sgbuddy ecotxn <transaction-id> --output json`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			input := args[0]
 
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			if publish && output.IsStructured() {
+				fmt.Printf("%sError: --output %s is not supported with --publish\n", appCtx.GetPrefix(), output)
+				os.Exit(1)
+			}
+
 			if publish {
 				// Publish mode - generate SQL scripts
 				if utils.IsSimpleFilePath(input) {
@@ -44,7 +59,7 @@ For auto-creating DML tickets:
 				}
 			} else {
 				// Default to view mode if no subcommand specified
-				processEcoTxnView(appCtx, clients, input)
+				processEcoTxnView(appCtx, clients, input, output)
 			}
 		},
 	}
@@ -52,6 +67,7 @@ For auto-creating DML tickets:
 	// Add flags
 	cmd.Flags().BoolVar(&publish, "publish", false, "Generate SQL deployment and rollback scripts")
 	cmd.Flags().StringVar(&createDML, "create-dml", "", "Auto-create Doorman DML tickets with ticket ID (e.g., \"TSE-1234\")")
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
 
 	// Add subcommands
 	cmd.AddCommand(NewEcoTxnViewCmd(appCtx, clients))
@@ -60,6 +76,8 @@ For auto-creating DML tickets:
 }
 
 func NewEcoTxnViewCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var outputFlag string
+
 	cmd := &cobra.Command{
 		Use:   "view <transaction-id>",
 		Short: "View ecosystem transaction information from PartnerPay Engine",
@@ -67,27 +85,41 @@ func NewEcoTxnViewCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Comm
 and Payment Core databases.
 
 For a single transaction:
-  sgbuddy ecotxn view de05f9e39aa0485cad6f559f02de9675`,
+  sgbuddy ecotxn view de05f9e39aa0485cad6f559f02de9675
+  sgbuddy ecotxn view de05f9e39aa0485cad6f559f02de9675 --output json`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			input := args[0]
-			processEcoTxnView(appCtx, clients, input)
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			processEcoTxnView(appCtx, clients, input, output)
 		},
 	}
 
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
+
 	return cmd
 }
 
-func processEcoTxnView(appCtx *common.Context, clients *di.ClientSet, input string) {
+func processEcoTxnView(appCtx *common.Context, clients *di.ClientSet, input string, output adapters.OutputFormat) {
 	// Check if input is a file or a single transaction ID
 	if utils.IsSimpleFilePath(input) {
 		// Process batch file with Singapore environment
-		service.ProcessEcoBatchFileWithEnv(input, "sg")
+		service.ProcessEcoBatchFileWithOutput(input, "sg", output)
 	} else {
 		// Process single transaction with Singapore environment
 		txnService := service.GetTransactionQueryService()
 		result := txnService.QueryEcoTransactionWithEnv(input, "sg")
 
+		// The view never generates SQL, so neither does its structured form
+		if output.IsStructured() {
+			writeStructuredResult(appCtx, *result, output, nil)
+			return
+		}
+
 		// Check for errors
 		if result.Error != "" {
 			os.Exit(1)
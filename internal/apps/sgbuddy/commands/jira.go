@@ -7,6 +7,7 @@ import (
 	"buddy/internal/apps/common"
 	"buddy/internal/apps/common/jira"
 	clients "buddy/internal/clients/jira"
+	"buddy/internal/config"
 	"buddy/internal/di"
 	"buddy/internal/ui"
 
@@ -48,7 +49,7 @@ Only tickets with status NOT IN (COMPLETED, CLOSED) will be shown.`,
 
 			// Validate JIRA username is configured
 			if jiraConfig.Auth.Username == "" {
-				fmt.Printf("Error: JIRA_USERNAME not configured. Please set it in your .env.%s file\n", appCtx.Environment)
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
 				os.Exit(1)
 			}
 
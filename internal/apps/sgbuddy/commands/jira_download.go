@@ -6,6 +6,7 @@ import (
 
 	"buddy/internal/apps/common"
 	"buddy/internal/apps/common/jira"
+	"buddy/internal/config"
 	"buddy/internal/di"
 
 	"github.com/spf13/cobra"
@@ -44,7 +45,7 @@ Examples:
 
 			// Validate JIRA username is configured
 			if jiraConfig.Auth.Username == "" {
-				fmt.Printf("Error: JIRA_USERNAME not configured. Please set it in your .env.%s file\n", appCtx.Environment)
+				fmt.Printf("Error: %s\n", config.MissingCredentialHint("JIRA_USERNAME"))
 				os.Exit(1)
 			}
 
@@ -0,0 +1,95 @@
+package sgbuddy
+
+import (
+	"fmt"
+	"os"
+
+	"buddy/internal/apps/common"
+	"buddy/internal/di"
+	"buddy/internal/txn/adapters"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSopCmd creates the sop command group
+func NewSopCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	sopCmd := &cobra.Command{
+		Use:   "sop",
+		Short: "Inspect SOP case rules",
+		Long:  `Inspect how SOP case rules evaluate against transactions`,
+	}
+
+	sopCmd.AddCommand(NewSopExplainCmd(appCtx, clients))
+	sopCmd.AddCommand(NewSopListCmd(appCtx))
+
+	return sopCmd
+}
+
+// NewSopExplainCmd creates a command that traces SOP rule evaluation for a transaction
+func NewSopExplainCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "explain [transaction-id]",
+		Short: "Explain which SOP rules came closest to matching a transaction",
+		Long: `Query a transaction and evaluate every SOP rule against it condition by condition.
+
+Prints the identified case followed by the rules with the most satisfied conditions,
+showing the value resolved for each field and whether the condition passed.
+Useful for triaging transactions that resolve to NOT_FOUND.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			transactionID := args[0]
+
+			fmt.Printf("%sQuerying transaction: %s\n", appCtx.GetPrefix(), transactionID)
+			result := clients.TxnSvc.QueryTransactionWithEnv(transactionID, "sg")
+			if result == nil || result.Error != "" {
+				if result != nil {
+					fmt.Printf("%sError: %s\n", appCtx.GetPrefix(), result.Error)
+				}
+				os.Exit(1)
+			}
+
+			adapters.WriteResult(os.Stdout, *result, 1)
+
+			traces := adapters.SOPRepo.ExplainRules(result, "sg")
+			adapters.WriteRuleExplanation(os.Stdout, *result, traces, top)
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 3, "Number of near-miss rules to show (0 for all)")
+
+	return cmd
+}
+
+// NewSopListCmd creates a command that lists every SOP case with its rules and template
+func NewSopListCmd(appCtx *common.Context) *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List SOP cases with their rules, country gating and templates",
+		Long: `List every SOP case that applies to this environment, in summary order.
+
+For each case prints the rules that identify it with their conditions and country
+gating, whether a SQL template is registered, and whether the template prompts
+for a choice before generating SQL. Cases gated only to another country are left out.
+
+Cases that an operator decision leads to, or that the operator raises by hand,
+need no rule of their own; interactive cases always do.
+
+With --check, exits non-zero when a case is missing a rule or a template.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			incomplete := adapters.WriteCaseCoverage(os.Stdout, "sg", adapters.SOPRepo.CaseCoverage("sg"))
+			if check && incomplete > 0 {
+				fmt.Printf("\n%s%d case(s) missing a rule or template\n", appCtx.GetPrefix(), incomplete)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Exit non-zero when a case is missing a rule or template")
+
+	return cmd
+}
@@ -1,11 +1,15 @@
 package sgbuddy
 
 import (
+	"fmt"
 	"os"
 
 	"buddy/internal/apps/common"
+	"buddy/internal/apps/common/batch"
+	commondoorman "buddy/internal/apps/common/doorman"
 	"buddy/internal/di"
 	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
 	"buddy/internal/txn/service"
 	"buddy/internal/txn/utils"
 
@@ -13,41 +17,257 @@ import (
 )
 
 func NewTxnCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var (
+		recordDir      string
+		replayDir      string
+		outputFlag     string
+		decisionsFile  string
+		nonInteractive bool
+		cacheOpts      service.QueryCacheOptions
+		batchOpts      = service.DefaultBatchOptions()
+	)
+
 	cmd := &cobra.Command{
 		Use:   "txn [transaction-id-or-file-path]",
 		Short: "Query Singapore transaction status from payment systems",
 		Long: `Query transaction status from Singapore payment-engine, payment-core, and fast-adapter databases.
 
-For a single transaction:
+For a single transaction, with the remediation SQL of its SOP case and an offer
+to raise Doorman DML tickets:
   sgbuddy txn 9392fb12b6c64db18e779ae60bdf4307
 
 For multiple transactions from a file:
   sgbuddy txn file-path.txt
 
-Each line in the file should contain a single transaction ID.`,
+Each line in the file should contain a single transaction ID.
+
+To record the Doorman queries of a run, or replay a recorded run offline:
+  sgbuddy txn 9392fb12b6c64db18e779ae60bdf4307 --record ./fixtures/9392fb12
+  sgbuddy txn 9392fb12b6c64db18e779ae60bdf4307 --replay ./fixtures/9392fb12
+
+To query a large file in parallel (results keep the input order):
+  sgbuddy txn file-path.txt --concurrency 8 --rate 10
+
+Batch files fetch their rows with IN (...) queries of --bulk-size IDs before
+populating each transaction. To query every transaction separately instead:
+  sgbuddy txn file-path.txt --bulk-size 0
+
+Completed transactions are saved to file-path.txt.buddy-state.ndjson. To continue
+an interrupted run without re-querying them:
+  sgbuddy txn file-path.txt --resume
+
+The checkpoint is removed once a run finishes with every transaction completed. A
+new run refuses to start while a checkpoint exists; to discard it and start over:
+  sgbuddy txn file-path.txt --fresh
+
+For machine-readable output (versioned schema with adapter sections, formatted
+workflow states, the identified case and generated SQL):
+  sgbuddy txn 9392fb12b6c64db18e779ae60bdf4307 --output json
+  sgbuddy txn file-path.txt --output ndjson   # writes file-path.txt-output.ndjson
+
+After the generated SQL is deployed, check that each fix took effect:
+  sgbuddy txn verify file-path.txt-output.json
+
+Identical queries within a run are answered once and reused for --cache-ttl.
+Use --cache-dir to reuse results across runs, or --no-cache to always ask Doorman:
+  sgbuddy txn file-path.txt --cache-dir ~/.cache/buddy/queries
+
+Decisions (--decisions, --non-interactive):
+FAST transfers with no final status (fast_cashout_pending_pe220_pc201,
+fast_cashin_unknown_pe220) need a reject decision before SQL is generated, which
+is normally asked on stdin. --decisions <file> supplies them up front, keyed by
+transaction ID or case, in the same YAML or CSV format as mybuddy txn --decisions:
+  cases:
+    fast_cashout_pending_pe220_pc201: reject
+With --non-interactive a missing decision fails the run before any SQL is
+generated and no Doorman ticket is offered, so batches can run without a TTY.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			input := args[0]
 
+			output, err := adapters.ParseOutputFormat(outputFlag)
+			if err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+			batchOpts.Output = output
+
+			if err := service.GetTransactionQueryService().ConfigureRecordReplay(recordDir, replayDir); err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			cacheOpts.Namespace = appCtx.Profile
+			if cacheOpts.Namespace == "" {
+				cacheOpts.Namespace = appCtx.Environment
+			}
+			if err := service.GetTransactionQueryService().ConfigureQueryCache(cacheOpts); err != nil {
+				fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+				os.Exit(1)
+			}
+
+			if decisionsFile != "" || nonInteractive {
+				batchOpts.Decisions = adapters.NewDecisions(nonInteractive)
+				if decisionsFile != "" {
+					if err := batchOpts.Decisions.LoadDecisionsFile(decisionsFile); err != nil {
+						fmt.Printf("%sError: %v\n", appCtx.GetPrefix(), err)
+						os.Exit(1)
+					}
+				}
+			}
+
 			// Check if input is a file or a single transaction ID
 			if utils.IsSimpleFilePath(input) {
 				// Process batch file with Singapore environment
-				service.ProcessBatchFileWithEnv(input, "sg")
+				batchOpts.Prefix = appCtx.GetPrefix()
+				if err := service.ProcessBatchFileWithOptions(input, "sg", batchOpts); err != nil {
+					os.Exit(1)
+				}
 			} else {
 				// Process single transaction with Singapore environment
 				txnService := service.GetTransactionQueryService()
 				result := txnService.QueryTransactionWithEnv(input, "sg")
 
-				// Check for errors
-				if result.Error != "" {
-					os.Exit(1)
+				if output.IsStructured() {
+					writeStructuredResult(appCtx, *result, output, batchOpts.DecisionProvider())
+					return
 				}
 
-				// Print the result
-				adapters.WriteResult(os.Stdout, *result, 1)
+				processSingleTransaction(appCtx, clients, *result, batchOpts)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&decisionsFile, "decisions", "", "YAML or CSV file of accept/reject decisions for interactive cases, by transaction ID or case")
+	cmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt; fail when an interactive case has no decision and skip the Doorman ticket prompt")
+	cmd.Flags().StringVar(&recordDir, "record", "", "Record Doorman queries and responses into this directory")
+	cmd.Flags().StringVar(&replayDir, "replay", "", "Replay Doorman responses from a recorded directory")
+	cmd.Flags().BoolVar(&cacheOpts.Disabled, "no-cache", false, "Send every query to Doorman instead of reusing results of identical queries")
+	cmd.Flags().StringVar(&cacheOpts.Dir, "cache-dir", "", "Keep query results in this directory so later runs can reuse them")
+	cmd.Flags().DurationVar(&cacheOpts.TTL, "cache-ttl", service.DefaultQueryCacheTTL, "How long a cached query result is reused")
+	cmd.Flags().IntVar(&batchOpts.Concurrency, "concurrency", batchOpts.Concurrency, "Number of transactions to query in parallel for batch files")
+	cmd.Flags().Float64Var(&batchOpts.RatePerSecond, "rate", batchOpts.RatePerSecond, "Maximum transactions started per second for batch files (0 for no limit)")
+	cmd.Flags().IntVar(&batchOpts.BulkSize, "bulk-size", batchOpts.BulkSize, "IDs fetched per IN (...) query for batch files (0 queries each transaction separately)")
+	cmd.Flags().BoolVar(&batchOpts.Resume, "resume", false, "Resume an interrupted batch run from its .buddy-state.ndjson checkpoint")
+	cmd.Flags().BoolVar(&batchOpts.Fresh, "fresh", false, "Discard an existing .buddy-state.ndjson checkpoint and start the batch run over")
+	cmd.Flags().StringVar(&outputFlag, "output", string(adapters.OutputText), "Output format: text, json or ndjson")
+
+	cmd.AddCommand(NewTxnVerifyCmd(appCtx, clients))
+
+	return cmd
+}
+
+// NewTxnVerifyCmd creates a command that re-checks transactions after their DML ran
+func NewTxnVerifyCmd(appCtx *common.Context, clients *di.ClientSet) *cobra.Command {
+	var (
+		caseFlag  string
+		batchOpts = service.DefaultBatchOptions()
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify [results-file-or-id-file-or-transaction-id]",
+		Short: "Check whether deployed remediation SQL took effect",
+		Long: `Re-query transactions after their DML was deployed and compare the new workflow
+states with the target states declared for the SOP case that generated the SQL.
+
+Each transaction is reported as LANDED (reached a target state), STILL_STUCK (still
+matches the original case), MOVED (now matches a different SOP case), UNCONFIRMED
+(matches no case but has not reached a target state) or ERROR.
+
+Pass the results file written by --output json or ndjson to use the recorded case of
+each transaction, or a file of IDs or a single ID together with --case:
+  sgbuddy txn verify TS-4583.txt-output.json
+  sgbuddy txn verify TS-4583.txt --case <case>
+  sgbuddy txn verify <transaction-id> --case <case>
+
+Exits non-zero unless every transaction landed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !batch.VerifyFixes(appCtx, clients, args[0], caseFlag, batchOpts) {
+				os.Exit(1)
 			}
 		},
 	}
 
+	cmd.Flags().StringVar(&caseFlag, "case", "", "SOP case whose SQL was deployed (overrides the case recorded in a results file)")
+	cmd.Flags().IntVar(&batchOpts.Concurrency, "concurrency", batchOpts.Concurrency, "Number of transactions to query in parallel")
+	cmd.Flags().Float64Var(&batchOpts.RatePerSecond, "rate", batchOpts.RatePerSecond, "Maximum transactions started per second (0 for no limit)")
+
 	return cmd
 }
+
+// processSingleTransaction prints result followed by the SQL of its SOP case, then
+// offers to raise Doorman DML tickets for it unless the run is non-interactive
+func processSingleTransaction(appCtx *common.Context, clients *di.ClientSet, result domain.TransactionResult, batchOpts service.BatchOptions) {
+	adapters.WriteResult(os.Stdout, result, 1)
+
+	if result.Error != "" || result.QueryFailed() {
+		os.Exit(1)
+	}
+
+	// Interactive cases (FAST with no final status) are decided by the run's decision provider
+	statements, err := adapters.GenerateSQLStatementsWithDecisions([]domain.TransactionResult{result}, batchOpts.DecisionProvider())
+	if err != nil {
+		fmt.Printf("%sError generating SQL: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
+
+	printSQLToConsole(appCtx, statements)
+
+	if batchOpts.Interactive() {
+		commondoorman.PromptForDoormanTicket(clients.Doorman, statements, false, "")
+	}
+}
+
+// printSQLToConsole prints the deploy and rollback statements of each database
+func printSQLToConsole(appCtx *common.Context, statements domain.SQLStatements) {
+	sections := []struct {
+		title      string
+		statements []string
+	}{
+		{"PC Deploy SQL", statements.PCDeployStatements},
+		{"PC Rollback SQL", statements.PCRollbackStatements},
+		{"PE Deploy SQL", statements.PEDeployStatements},
+		{"PE Rollback SQL", statements.PERollbackStatements},
+		{"PPE Deploy SQL", statements.PPEDeployStatements},
+		{"PPE Rollback SQL", statements.PPERollbackStatements},
+	}
+
+	hasOutput := false
+	for _, section := range sections {
+		if len(section.statements) == 0 {
+			continue
+		}
+		hasOutput = true
+		fmt.Printf("\n%s--- %s ---\n", appCtx.GetPrefix(), section.title)
+		for _, stmt := range section.statements {
+			fmt.Println(stmt)
+		}
+	}
+
+	if !hasOutput {
+		fmt.Printf("\n%sNo SQL statements generated. Transaction may not require remediation or case conditions were not met.\n", appCtx.GetPrefix())
+	}
+}
+
+// writeStructuredResult writes a single result as a structured document to stdout,
+// including its generated SQL unless decisions is nil, and exits non-zero on error.
+// decisions answers interactive cases; with structured output its prompts go to stderr.
+func writeStructuredResult(appCtx *common.Context, result domain.TransactionResult, output adapters.OutputFormat, decisions adapters.DecisionProvider) {
+	results := []domain.TransactionResult{result}
+	var statements *domain.SQLStatements
+	if decisions != nil && result.Error == "" && !result.QueryFailed() {
+		// A failed decision is recorded on the result, which is written without SQL
+		if generated, err := adapters.GenerateSQLStatementsWithDecisions(results, decisions); err == nil {
+			statements = &generated
+		}
+	}
+
+	if err := adapters.WriteStructuredResults(os.Stdout, output, results, statements); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError writing result: %v\n", appCtx.GetPrefix(), err)
+		os.Exit(1)
+	}
+	if results[0].Error != "" || results[0].QueryFailed() {
+		os.Exit(1)
+	}
+}
@@ -23,23 +23,10 @@ var (
 	BuildEnvironment string
 )
 
-// ValidateConstants ensures all required constants are set
+// ValidateConstants ensures the constants every build needs are set. Credentials
+// are optional here: they are normally supplied at runtime through the
+// credential chain in internal/config, and only fall back to these values.
 func ValidateConstants() error {
-	if JiraDomain == "" {
-		return fmt.Errorf("JIRA_DOMAIN not set at build time")
-	}
-	if JiraUsername == "" {
-		return fmt.Errorf("JIRA_USERNAME not set at build time")
-	}
-	if JiraApiKey == "" {
-		return fmt.Errorf("JIRA_API_KEY not set at build time")
-	}
-	if DoormanUsername == "" {
-		return fmt.Errorf("DOORMAN_USERNAME not set at build time")
-	}
-	if DoormanPassword == "" {
-		return fmt.Errorf("DOORMAN_PASSWORD not set at build time")
-	}
 	if BuildEnvironment == "" {
 		return fmt.Errorf("BUILD_ENVIRONMENT not set at build time")
 	}
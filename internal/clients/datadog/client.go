@@ -30,11 +30,15 @@ type DatadogConfig struct {
 }
 
 func NewDatadogClient(env string) *DatadogClient {
+	return NewDatadogClientWithBaseURL(config.Get("DATADOG_BASE_URL", "https://api.datadoghq.com"))
+}
+
+// NewDatadogClientWithBaseURL creates a client for the Datadog site at baseURL
+func NewDatadogClientWithBaseURL(baseURL string) *DatadogClient {
 	logger := logging.NewDefaultLogger("datadog")
 
 	apiKey := config.Get("DD_API_KEY", "")
 	appKey := config.Get("DD_APPLICATION_KEY", "")
-	baseURL := config.Get("DATADOG_BASE_URL", "https://api.datadoghq.com")
 
 	httpClient := &http.Client{Timeout: 30 * time.Second}
 	apiCfg := datadogapi.NewConfiguration()
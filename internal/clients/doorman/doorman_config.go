@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,25 +25,21 @@ type AuthInfo struct {
 
 // DBInfo holds database connection information
 type DBInfo struct {
-	ClusterName  string
-	InstanceName string
-	Schema       string
+	ClusterName  string `yaml:"cluster"`
+	InstanceName string `yaml:"instance"`
+	Schema       string `yaml:"schema"`
 }
 
 // DoormanConfig holds environment-specific configuration
 type DoormanConfig struct {
-	Host string
-	Auth AuthInfo
+	Host string   `yaml:"host"`
+	Auth AuthInfo `yaml:"-"`
 
 	// Account ID for API requests
-	AccountID string
-
-	// Database cluster/instance names
-	PaymentEngine    DBInfo
-	PaymentCore      DBInfo
-	FastAdapter      DBInfo
-	RppAdapter       DBInfo
-	PartnerpayEngine DBInfo
+	AccountID string `yaml:"account_id"`
+
+	// Database cluster/instance names keyed by service name (e.g. payment_engine)
+	Services map[string]DBInfo `yaml:"services"`
 }
 
 // DoormanClient singleton with configuration
@@ -50,72 +48,27 @@ type DoormanClient struct {
 	httpClient    *http.Client
 	mu            sync.RWMutex
 	authenticated bool
+
+	// generation counts sessions, so a stale session is only discarded once
+	generation int
+	// sessions persists the session cookie across runs; nil disables it
+	sessions *sessionStore
+
+	// retry and breaker guard read-only queries; the zero values send each
+	// query once with no circuit breaking
+	retry   RetryPolicy
+	breaker *circuitBreaker
+	sleep   func(time.Duration)
+	// recovered counts the queries that succeeded after retrying
+	recovered retryCounter
 }
 
 // Ensure DoormanClient implements DoormanInterface
 var _ DoormanInterface = (*DoormanClient)(nil)
 
-// Environment-specific configurations
-var configs = map[string]DoormanConfig{
-	"sg": {
-		Host: "https://doorman.sgbank.pr",
-		Auth: AuthInfo{
-			Username: config.Get("DOORMAN_USERNAME", ""),
-			Password: config.Get("DOORMAN_PASSWORD", ""),
-		},
-		AccountID: "748118206017", // Singapore environment account ID
-		PaymentEngine: DBInfo{
-			ClusterName:  "sg-prd-m-payment-engine",
-			InstanceName: "sg-prd-m-payment-engine",
-			Schema:       "prod_payment_engine_db01",
-		},
-		PaymentCore: DBInfo{
-			ClusterName:  "sg-prd-m-payment-core",
-			InstanceName: "sg-prd-m-payment-core",
-			Schema:       "prod_payment_core_db01",
-		},
-		FastAdapter: DBInfo{
-			ClusterName:  "sg-prd-m-fast-adapter",
-			InstanceName: "sg-prd-m-fast-adapter",
-			Schema:       "prod_fast_adapter_db01",
-		},
-		RppAdapter: DBInfo{}, // Not available in SG
-		PartnerpayEngine: DBInfo{
-			ClusterName:  "sg-prd-m-partnerpay-engine",
-			InstanceName: "sg-prd-m-partnerpay-engine",
-			Schema:       "prod_partnerpay_engine_db01",
-		},
-	},
-	"my": {
-		Host: "https://doorman.infra.prd.g-bank.app",
-		Auth: AuthInfo{
-			Username: config.Get("DOORMAN_USERNAME", ""),
-			Password: config.Get("DOORMAN_PASSWORD", ""),
-		},
-		AccountID: "559634300081", // Malaysia environment account ID
-		PaymentEngine: DBInfo{
-			ClusterName:  "prd-payments-payment-engine-rds-mysql",
-			InstanceName: "prd-payments-payment-engine-rds-mysql",
-			Schema:       "payment_engine",
-		},
-		PaymentCore: DBInfo{
-			ClusterName:  "prd-payments-payment-core-rds-mysql",
-			InstanceName: "prd-payments-payment-core-rds-mysql",
-			Schema:       "payment_core",
-		},
-		FastAdapter: DBInfo{}, // Not available in MY
-		RppAdapter: DBInfo{
-			ClusterName:  "prd-payments-rpp-adapter-rds-mysql",
-			InstanceName: "prd-payments-rpp-adapter-rds-mysql",
-			Schema:       "rpp_adapter",
-		},
-		PartnerpayEngine: DBInfo{
-			ClusterName:  "prd-payments-partnerpay-engine-rds-mysql",
-			InstanceName: "prd-payments-partnerpay-engine-rds-mysql",
-			Schema:       "partnerpay_engine",
-		},
-	},
-}
+// Environment-specific configurations, loaded from the embedded doorman_config.yaml
+// and merged with the user override by LoadConfigOverride
+var configs = mustLoadConfigs(defaultConfigYAML)
 
 var Doorman DoormanInterface
 
@@ -129,17 +82,30 @@ func NewDoormanClient(env string) DoormanInterface {
 	if !exists {
 		panic(fmt.Sprintf("country %s is not supported", env))
 	}
+	cfg.Auth = AuthInfo{
+		Username: config.Get("DOORMAN_USERNAME", ""),
+		Password: config.Get("DOORMAN_PASSWORD", ""),
+	}
 
 	Doorman = &DoormanClient{
 		config: cfg,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		sessions: newSessionStore(SessionCachePath()),
+		retry:    DefaultRetryPolicy,
+		breaker:  newCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
 	}
 
 	return Doorman
 }
 
+// HasEnvironment reports whether env is configured in the Doorman config
+func HasEnvironment(env string) bool {
+	_, exists := configs[env]
+	return exists
+}
+
 // GetDoormanClient returns the initialized DoormanClient instance
 // Deprecated: Use clients.Doorman directly after initialization
 func GetDoormanClient(env string) (DoormanInterface, error) {
@@ -154,7 +120,9 @@ func (c *DoormanClient) GetConfig() DoormanConfig {
 	return c.config
 }
 
-// Authenticate performs authentication with doorman service
+// Authenticate performs authentication with doorman service. A session saved by
+// an earlier run is reused when it has not expired; requests sent through do
+// log in again if Doorman rejects it.
 func (c *DoormanClient) Authenticate() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -164,6 +132,22 @@ func (c *DoormanClient) Authenticate() error {
 	}
 
 	cfg := c.config
+	if cfg.Auth.Username == "" {
+		return errors.New(config.MissingCredentialHint("DOORMAN_USERNAME"))
+	}
+	if cfg.Auth.Password == "" {
+		return errors.New(config.MissingCredentialHint("DOORMAN_PASSWORD"))
+	}
+
+	// Set up cookie jar if not already set
+	if c.httpClient.Jar == nil {
+		c.httpClient.Jar, _ = cookiejar.New(nil)
+	}
+
+	if c.restoreSession() {
+		return nil
+	}
+
 	loginURL, _ := url.JoinPath(cfg.Host, "/api/login/ldap/signin")
 
 	loginReq := struct {
@@ -179,11 +163,6 @@ func (c *DoormanClient) Authenticate() error {
 	req, _ := http.NewRequest(http.MethodPost, loginURL, bytes.NewReader(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set up cookie jar if not already set
-	if c.httpClient.Jar == nil {
-		c.httpClient.Jar, _ = cookiejar.New(nil)
-	}
-
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("authentication failed: network error during login: %w", err)
@@ -201,15 +180,77 @@ func (c *DoormanClient) Authenticate() error {
 	}
 
 	c.authenticated = true
+	c.generation++
+	if c.sessions != nil {
+		if err := c.sessions.save(cfg.Host, cfg.Auth.Username, resp.Cookies()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to cache Doorman session: %v\n", err)
+		}
+	}
 	return nil
 }
 
-// ExecuteQuery executes a query against the specified database cluster
+// restoreSession loads a cached session cookie into the jar. Callers hold c.mu.
+func (c *DoormanClient) restoreSession() bool {
+	if c.sessions == nil {
+		return false
+	}
+	cookies := c.sessions.load(c.config.Host, c.config.Auth.Username)
+	if len(cookies) == 0 {
+		return false
+	}
+	hostURL, err := url.Parse(c.config.Host)
+	if err != nil {
+		return false
+	}
+
+	c.httpClient.Jar.SetCookies(hostURL, cookies)
+	c.authenticated = true
+	c.generation++
+	return true
+}
+
+// ExecuteQuery executes a query against the specified database cluster.
+// Read-only queries that fail transiently are retried with backoff; any other
+// statement is sent once. A *QueryError reports how many attempts were made.
 func (c *DoormanClient) ExecuteQuery(cluster, instance, schema, query string) ([]map[string]interface{}, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
+	maxAttempts := 1
+	if isReadOnlyQuery(query) {
+		maxAttempts = c.retry.attempts()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.allow(cluster); err != nil {
+			return nil, &QueryError{Cluster: cluster, Attempts: attempt - 1, Err: err}
+		}
+
+		rows, err := c.executeQueryOnce(cluster, instance, schema, query)
+		c.breaker.record(cluster, err)
+		if err == nil {
+			c.recovered.recovered(attempt)
+			return rows, nil
+		}
+
+		transient := isTransient(err)
+		if !transient || attempt >= maxAttempts {
+			return nil, &QueryError{Cluster: cluster, Attempts: attempt, Retryable: transient, Err: err}
+		}
+
+		sleep := c.sleep
+		if sleep == nil {
+			sleep = time.Sleep
+		}
+		sleep(c.retry.backoff(attempt))
 	}
+}
+
+// RetryStats returns the queries that have succeeded after retrying since the
+// client was created; failed queries are reported through QueryError instead
+func (c *DoormanClient) RetryStats() RetryStats {
+	return c.recovered.stats()
+}
 
+// executeQueryOnce sends a single query request
+func (c *DoormanClient) executeQueryOnce(cluster, instance, schema, query string) ([]map[string]interface{}, error) {
 	cfg := c.GetConfig()
 	queryURL, _ := url.JoinPath(cfg.Host, "/api/rds/query/execute")
 
@@ -229,10 +270,14 @@ func (c *DoormanClient) ExecuteQuery(cluster, instance, schema, query string) ([
 
 	reqBody, _ := json.Marshal(queryReq)
 
-	req, _ := http.NewRequest(http.MethodPost, queryURL, bytes.NewReader(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, queryURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -242,11 +287,8 @@ func (c *DoormanClient) ExecuteQuery(cluster, instance, schema, query string) ([
 
 	if resp.StatusCode >= 300 {
 		// Read the response body to get more details about the error
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, errors.New("doorman query failed: " + resp.Status + " (failed to read error response)")
-		}
-		return nil, errors.New("doorman query failed: " + resp.Status + " - " + string(body))
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &statusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
 	}
 
 	var response struct {
@@ -277,48 +319,38 @@ func (c *DoormanClient) ExecuteQuery(cluster, instance, schema, query string) ([
 	return data, nil
 }
 
+// QueryService queries the database of any service configured for this environment
+func (c *DoormanClient) QueryService(serviceName, query string) ([]map[string]interface{}, error) {
+	dbInfo, err := c.getServiceDBInfo(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+}
+
 // QueryPaymentEngine queries the payment engine database
 func (c *DoormanClient) QueryPaymentEngine(query string) ([]map[string]interface{}, error) {
-	cfg := c.GetConfig()
-	dbInfo := cfg.PaymentEngine
-	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+	return c.QueryService("payment_engine", query)
 }
 
 // QueryPaymentCore queries the payment core database
 func (c *DoormanClient) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
-	cfg := c.GetConfig()
-	dbInfo := cfg.PaymentCore
-	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+	return c.QueryService("payment_core", query)
 }
 
 // QueryFastAdapter queries the fast adapter database (Singapore only)
 func (c *DoormanClient) QueryFastAdapter(query string) ([]map[string]interface{}, error) {
-	cfg := c.GetConfig()
-	dbInfo := cfg.FastAdapter
-	if dbInfo.ClusterName == "" {
-		return nil, errors.New("fast adapter is not available in this environment")
-	}
-	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+	return c.QueryService("fast_adapter", query)
 }
 
 // QueryRppAdapter queries the rpp adapter database (Malaysia only)
 func (c *DoormanClient) QueryRppAdapter(query string) ([]map[string]interface{}, error) {
-	cfg := c.GetConfig()
-	dbInfo := cfg.RppAdapter
-	if dbInfo.ClusterName == "" {
-		return nil, errors.New("rpp adapter is not available in this environment")
-	}
-	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+	return c.QueryService("rpp_adapter", query)
 }
 
 // QueryPartnerpayEngine queries the partnerpay engine database (Malaysia only)
 func (c *DoormanClient) QueryPartnerpayEngine(query string) ([]map[string]interface{}, error) {
-	cfg := c.GetConfig()
-	dbInfo := cfg.PartnerpayEngine
-	if dbInfo.ClusterName == "" {
-		return nil, errors.New("partnerpay engine is not available in this environment")
-	}
-	return c.ExecuteQuery(dbInfo.ClusterName, dbInfo.InstanceName, dbInfo.Schema, query)
+	return c.QueryService("partnerpay_engine", query)
 }
 
 // CreateTicketRequest represents the request structure for creating a DML ticket
@@ -398,29 +430,13 @@ type TicketResult struct {
 // getServiceDBInfo returns the DBInfo for a given service name
 func (c *DoormanClient) getServiceDBInfo(serviceName string) (DBInfo, error) {
 	cfg := c.GetConfig()
-	switch serviceName {
-	case "payment_engine":
-		return cfg.PaymentEngine, nil
-	case "payment_core":
-		return cfg.PaymentCore, nil
-	case "fast_adapter":
-		if cfg.FastAdapter.ClusterName == "" {
-			return DBInfo{}, errors.New("fast adapter is not available in this environment")
-		}
-		return cfg.FastAdapter, nil
-	case "rpp_adapter":
-		if cfg.RppAdapter.ClusterName == "" {
-			return DBInfo{}, errors.New("rpp adapter is not available in this environment")
-		}
-		return cfg.RppAdapter, nil
-	case "partnerpay_engine":
-		if cfg.PartnerpayEngine.ClusterName == "" {
-			return DBInfo{}, errors.New("partnerpay engine is not available in this environment")
-		}
-		return cfg.PartnerpayEngine, nil
-	default:
-		return DBInfo{}, fmt.Errorf("unknown service: %s", serviceName)
+	if dbInfo, ok := cfg.Services[serviceName]; ok && dbInfo.ClusterName != "" {
+		return dbInfo, nil
+	}
+	if knownServices[serviceName] {
+		return DBInfo{}, fmt.Errorf("%s is not available in this environment", strings.ReplaceAll(serviceName, "_", " "))
 	}
+	return DBInfo{}, fmt.Errorf("unknown service: %s", serviceName)
 }
 
 // CreateTicket creates a DML ticket in doorman for the specified service
@@ -439,10 +455,6 @@ func (c *DoormanClient) CreateTicket(serviceName, originalQuery, rollbackQuery,
 		return "", fmt.Errorf("validation error: note is required")
 	}
 
-	if err := c.Authenticate(); err != nil {
-		return "", fmt.Errorf("authentication error: %w", err)
-	}
-
 	dbInfo, err := c.getServiceDBInfo(serviceName)
 	if err != nil {
 		return "", fmt.Errorf("validation error: %w", err)
@@ -471,15 +483,20 @@ func (c *DoormanClient) CreateTicket(serviceName, originalQuery, rollbackQuery,
 		return "", fmt.Errorf("failed to marshal create ticket request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, createTicketURL, bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	// A session rejected by Doorman never reached ticket creation, so do may
+	// safely resend the request after logging in again. Other failures are not
+	// retried: a ticket that was created but whose response was lost would be
+	// created twice.
+	resp, err := c.do(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, createTicketURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("network error: failed to send request: %w", err)
+		return "", err
 	}
 	defer func() {
 		_ = resp.Body.Close()
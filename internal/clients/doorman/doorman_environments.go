@@ -0,0 +1,141 @@
+package doorman
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed doorman_config.yaml
+var defaultConfigYAML []byte
+
+// ConfigEnvVar names an environment variable pointing at a Doorman config override file
+const ConfigEnvVar = "BUDDY_DOORMAN_CONFIG"
+
+// knownServices are the services the built-in query methods and DML prompts use.
+// Asking for one that an environment does not configure reports it as unavailable
+// rather than unknown.
+var knownServices = map[string]bool{
+	"payment_engine":    true,
+	"payment_core":      true,
+	"fast_adapter":      true,
+	"rpp_adapter":       true,
+	"partnerpay_engine": true,
+}
+
+// configFile is the top-level layout of a Doorman config YAML file
+type configFile struct {
+	Environments map[string]DoormanConfig `yaml:"environments"`
+}
+
+func mustLoadConfigs(data []byte) map[string]DoormanConfig {
+	envs, err := parseConfigs(data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded Doorman config is invalid: %v", err))
+	}
+	return envs
+}
+
+// parseConfigs decodes Doorman environments from YAML, rejecting unknown keys
+func parseConfigs(data []byte) (map[string]DoormanConfig, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file configFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse Doorman config YAML: %w", err)
+	}
+
+	for env, cfg := range file.Environments {
+		for name, dbInfo := range cfg.Services {
+			if dbInfo.ClusterName == "" || dbInfo.Schema == "" {
+				return nil, fmt.Errorf("service %s in environment %s needs a cluster and a schema", name, env)
+			}
+			if dbInfo.InstanceName == "" {
+				// Doorman instances are named after their cluster unless stated otherwise
+				dbInfo.InstanceName = dbInfo.ClusterName
+				cfg.Services[name] = dbInfo
+			}
+		}
+	}
+
+	return file.Environments, nil
+}
+
+// mergeConfigs overlays override onto base by environment name. Host and account
+// ID replace the base values when set; services replace the base service of the
+// same name and new ones are added.
+func mergeConfigs(base, override map[string]DoormanConfig) map[string]DoormanConfig {
+	merged := make(map[string]DoormanConfig, len(base)+len(override))
+	for env, cfg := range base {
+		services := make(map[string]DBInfo, len(cfg.Services))
+		for name, dbInfo := range cfg.Services {
+			services[name] = dbInfo
+		}
+		cfg.Services = services
+		merged[env] = cfg
+	}
+
+	for env, cfg := range override {
+		current, exists := merged[env]
+		if !exists {
+			current = DoormanConfig{Services: make(map[string]DBInfo)}
+		}
+		if cfg.Host != "" {
+			current.Host = cfg.Host
+		}
+		if cfg.AccountID != "" {
+			current.AccountID = cfg.AccountID
+		}
+		for name, dbInfo := range cfg.Services {
+			current.Services[name] = dbInfo
+		}
+		merged[env] = current
+	}
+
+	return merged
+}
+
+// ConfigOverridePath returns the path of the Doorman config override file and
+// whether it was explicitly requested through BUDDY_DOORMAN_CONFIG.
+func ConfigOverridePath() (string, bool) {
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, true
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, ".config", "buddy", "doorman.yaml"), false
+}
+
+// LoadConfigOverride merges the override file, if one exists, into the
+// environment configs used by NewDoormanClient. A missing default override file
+// is not an error.
+func LoadConfigOverride() error {
+	path, explicit := ConfigOverridePath()
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("doorman config override %s is not readable: %w", path, err)
+	}
+
+	override, err := parseConfigs(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	configs = mergeConfigs(mustLoadConfigs(defaultConfigYAML), override)
+	return nil
+}
@@ -0,0 +1,115 @@
+package doorman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedConfigs(t *testing.T) {
+	envs := mustLoadConfigs(defaultConfigYAML)
+
+	for env, services := range map[string][]string{
+		"sg": {"payment_engine", "payment_core", "fast_adapter", "partnerpay_engine", "pairing_service"},
+		"my": {"payment_engine", "payment_core", "rpp_adapter", "partnerpay_engine"},
+	} {
+		cfg, ok := envs[env]
+		if !ok {
+			t.Fatalf("embedded config has no %s environment", env)
+		}
+		if cfg.Host == "" || cfg.AccountID == "" {
+			t.Errorf("%s: host and account_id are required", env)
+		}
+		for _, service := range services {
+			if dbInfo := cfg.Services[service]; dbInfo.ClusterName == "" || dbInfo.InstanceName == "" || dbInfo.Schema == "" {
+				t.Errorf("%s: service %s is incomplete: %+v", env, service, dbInfo)
+			}
+		}
+	}
+}
+
+func TestParseConfigs_Validation(t *testing.T) {
+	if _, err := parseConfigs([]byte("environments:\n  sg:\n    hots: x\n")); err == nil {
+		t.Error("expected unknown key to be rejected")
+	}
+	if _, err := parseConfigs([]byte("environments:\n  sg:\n    services:\n      ledger:\n        cluster: c\n")); err == nil || !strings.Contains(err.Error(), "ledger") {
+		t.Errorf("expected missing schema to be reported, got %v", err)
+	}
+
+	envs, err := parseConfigs([]byte("environments:\n  sg:\n    services:\n      ledger:\n        cluster: c\n        schema: s\n"))
+	if err != nil {
+		t.Fatalf("parseConfigs: %v", err)
+	}
+	if got := envs["sg"].Services["ledger"].InstanceName; got != "c" {
+		t.Errorf("expected instance to default to the cluster, got %q", got)
+	}
+}
+
+func TestLoadConfigOverride(t *testing.T) {
+	original := configs
+	defer func() { configs = original }()
+
+	path := filepath.Join(t.TempDir(), "doorman.yaml")
+	override := `environments:
+  sg:
+    host: https://doorman-uat.example
+    services:
+      payment_engine:
+        cluster: sg-uat-payment-engine
+        schema: uat_payment_engine
+      ledger:
+        cluster: sg-uat-ledger
+        schema: ledger
+  sg-staging:
+    host: https://doorman-staging.example
+    account_id: "1"
+`
+	if err := os.WriteFile(path, []byte(override), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigEnvVar, path)
+
+	if err := LoadConfigOverride(); err != nil {
+		t.Fatalf("LoadConfigOverride: %v", err)
+	}
+
+	sg := configs["sg"]
+	if sg.Host != "https://doorman-uat.example" || sg.AccountID != original["sg"].AccountID {
+		t.Errorf("expected host to be overridden and account ID kept, got %s / %s", sg.Host, sg.AccountID)
+	}
+	if sg.Services["payment_engine"].ClusterName != "sg-uat-payment-engine" {
+		t.Errorf("expected payment_engine to be overridden, got %+v", sg.Services["payment_engine"])
+	}
+	if sg.Services["payment_core"] != original["sg"].Services["payment_core"] {
+		t.Error("services missing from the override should keep their defaults")
+	}
+	if _, ok := sg.Services["ledger"]; !ok {
+		t.Error("expected new service to be added")
+	}
+	if _, ok := configs["sg-staging"]; !ok {
+		t.Error("expected new environment to be added")
+	}
+	if original["sg"].Services["ledger"].ClusterName != "" {
+		t.Error("the override must not modify the embedded defaults")
+	}
+
+	t.Setenv(ConfigEnvVar, filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := LoadConfigOverride(); err == nil {
+		t.Error("expected an explicitly requested missing override to fail")
+	}
+}
+
+func TestGetServiceDBInfo(t *testing.T) {
+	client := &DoormanClient{config: mustLoadConfigs(defaultConfigYAML)["my"]}
+
+	if dbInfo, err := client.getServiceDBInfo("rpp_adapter"); err != nil || dbInfo.Schema != "rpp_adapter" {
+		t.Errorf("unexpected rpp_adapter lookup: %+v, %v", dbInfo, err)
+	}
+	if _, err := client.getServiceDBInfo("fast_adapter"); err == nil || err.Error() != "fast adapter is not available in this environment" {
+		t.Errorf("expected fast adapter to be unavailable, got %v", err)
+	}
+	if _, err := client.getServiceDBInfo("ledger"); err == nil || !strings.Contains(err.Error(), "unknown service") {
+		t.Errorf("expected unknown service error, got %v", err)
+	}
+}
@@ -10,6 +10,10 @@ type DoormanInterface interface {
 	// Environment-specific query methods
 	// Note: Not all environments support all services - implementations should return clear errors for unsupported services
 
+	// QueryService queries the database of any service configured for the environment,
+	// including services without a dedicated method (e.g. pairing_service)
+	QueryService(serviceName, query string) ([]map[string]interface{}, error)
+
 	// Payment Engine queries - available in both Singapore and Malaysia
 	QueryPaymentEngine(query string) ([]map[string]interface{}, error)
 
@@ -32,4 +36,13 @@ type DoormanInterface interface {
 	// note: Additional notes for the ticket
 	// Returns the ticket ID on success
 	CreateTicket(serviceName, originalQuery, rollbackQuery, note string) (string, error)
+
+	// GetTicket fetches a DML ticket by ID, including its current status
+	GetTicket(ticketID string) (*TicketResult, error)
+
+	// ListTickets lists DML tickets matching the filter, newest first
+	ListTickets(filter TicketFilter) ([]TicketResult, error)
+
+	// TicketURL returns the Doorman page of a DML ticket on the configured host
+	TicketURL(ticketID string) string
 }
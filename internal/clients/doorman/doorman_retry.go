@@ -0,0 +1,237 @@
+package doorman
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Doorman while a cluster's
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open: too many recent failures")
+
+// RetryPolicy controls how often a failed read-only query is retried
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first; values below 1 mean one
+	BaseDelay   time.Duration // delay before the first retry, doubled on each further retry
+	MaxDelay    time.Duration // upper bound for a single delay
+}
+
+// DefaultRetryPolicy retries a transient failure twice, waiting roughly 0.5s then 1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the delay before retry number attempt (1-based): exponential
+// growth with jitter between half and the full delay, so parallel workers that
+// failed together do not retry in lockstep
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// QueryError describes a query that failed after every attempt it was allowed
+type QueryError struct {
+	Cluster   string
+	Attempts  int
+	Retryable bool // the last failure was transient, e.g. a 5xx or a network error
+	Err       error
+}
+
+func (e *QueryError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+	}
+	return e.Err.Error()
+}
+
+func (e *QueryError) Unwrap() error { return e.Err }
+
+// QueryAttempts returns how many times the query was sent
+func (e *QueryError) QueryAttempts() int { return e.Attempts }
+
+// RetryStats counts the read-only queries that succeeded only after retrying
+type RetryStats struct {
+	Queries int // queries that needed more than one attempt
+	Retries int // extra attempts those queries made
+}
+
+// Sub returns the retries recorded since an earlier snapshot
+func (s RetryStats) Sub(earlier RetryStats) RetryStats {
+	return RetryStats{Queries: s.Queries - earlier.Queries, Retries: s.Retries - earlier.Retries}
+}
+
+// retryCounter accumulates RetryStats across goroutines
+type retryCounter struct {
+	queries atomic.Int64
+	retries atomic.Int64
+}
+
+// recovered records a query that succeeded on attempt after failing before
+func (c *retryCounter) recovered(attempt int) {
+	if attempt <= 1 {
+		return
+	}
+	c.queries.Add(1)
+	c.retries.Add(int64(attempt - 1))
+}
+
+func (c *retryCounter) stats() RetryStats {
+	return RetryStats{Queries: int(c.queries.Load()), Retries: int(c.retries.Load())}
+}
+
+// statusError is a non-2xx response from Doorman
+type statusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	if e.Body == "" {
+		return "doorman query failed: " + e.Status
+	}
+	return "doorman query failed: " + e.Status + " - " + e.Body
+}
+
+// isTransient reports whether err is worth retrying: network failures, timeouts,
+// throttling and server errors. SQL errors and bad requests are not.
+func isTransient(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusTooManyRequests || status.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// isReadOnlyQuery reports whether query only reads data, so sending it twice is harmless
+func isReadOnlyQuery(query string) bool {
+	trimmed := strings.TrimSpace(query)
+	for strings.HasPrefix(trimmed, "(") {
+		trimmed = strings.TrimSpace(trimmed[1:])
+	}
+	fields := strings.Fields(trimmed)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN":
+		return true
+	default:
+		return false
+	}
+}
+
+// Circuit breaker defaults
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// circuitBreaker stops sending queries to a cluster after threshold consecutive
+// transient failures. After cooldown a single probe is let through; its outcome
+// closes the circuit or re-opens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	clusters  map[string]*circuitState
+}
+
+type circuitState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		clusters:  make(map[string]*circuitState),
+	}
+}
+
+// allow returns ErrCircuitOpen if queries to cluster should not be sent now.
+// A nil breaker allows everything.
+func (b *circuitBreaker) allow(cluster string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.clusters[cluster]
+	if state == nil || state.failures < b.threshold {
+		return nil
+	}
+	if state.probing || b.now().Sub(state.openedAt) < b.cooldown {
+		return fmt.Errorf("%s: %w", cluster, ErrCircuitOpen)
+	}
+	state.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of one attempt against cluster
+func (b *circuitBreaker) record(cluster string, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.clusters[cluster]
+	if state == nil {
+		state = &circuitState{}
+		b.clusters[cluster] = state
+	}
+
+	if err == nil || !isTransient(err) {
+		// Doorman answered, even if only to reject the SQL: the cluster is reachable
+		*state = circuitState{}
+		return
+	}
+
+	state.failures++
+	state.probing = false
+	if state.failures >= b.threshold {
+		state.openedAt = b.now()
+	}
+}
@@ -0,0 +1,166 @@
+package doorman
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer fails the first failures query requests with status, then succeeds
+func newFlakyServer(t *testing.T, failures int32, status int) (*DoormanClient, *atomic.Int32, *[]time.Duration) {
+	t.Helper()
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login/ldap/signin":
+			w.WriteHeader(http.StatusOK)
+		case "/api/rds/query/execute":
+			if calls.Add(1) <= failures {
+				http.Error(w, "upstream unavailable", status)
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":200,"result":{"headers":["id"],"rows":[[1]]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	var delays []time.Duration
+	client := &DoormanClient{
+		config:     DoormanConfig{Host: server.URL, Auth: AuthInfo{Username: "oncall", Password: "secret"}},
+		httpClient: server.Client(),
+		retry:      RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second},
+		breaker:    newCircuitBreaker(DefaultBreakerThreshold, time.Minute),
+		sleep:      func(d time.Duration) { delays = append(delays, d) },
+	}
+	return client, &calls, &delays
+}
+
+func TestExecuteQuery_RetriesTransientFailures(t *testing.T) {
+	client, calls, delays := newFlakyServer(t, 2, http.StatusServiceUnavailable)
+
+	rows, err := client.ExecuteQuery("pe", "pe", "payment_engine", "SELECT 1")
+	if err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	}
+	if len(rows) != 1 || calls.Load() != 3 {
+		t.Errorf("expected 1 row after 3 calls, got %d rows after %d calls", len(rows), calls.Load())
+	}
+	if stats := client.RetryStats(); stats != (RetryStats{Queries: 1, Retries: 2}) {
+		t.Errorf("expected the recovered query to be counted, got %+v", stats)
+	}
+	if len(*delays) != 2 {
+		t.Fatalf("expected 2 backoff sleeps, got %v", *delays)
+	}
+	if d := (*delays)[0]; d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("first delay %v outside jitter range", d)
+	}
+	if d := (*delays)[1]; d < 100*time.Millisecond || d > 200*time.Millisecond {
+		t.Errorf("second delay %v outside jitter range", d)
+	}
+}
+
+func TestExecuteQuery_ReportsAttemptsWhenExhausted(t *testing.T) {
+	client, calls, _ := newFlakyServer(t, 10, http.StatusBadGateway)
+
+	_, err := client.ExecuteQuery("pe", "pe", "payment_engine", "SELECT 1")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("expected a QueryError, got %v", err)
+	}
+	if queryErr.Attempts != 3 || !queryErr.Retryable || calls.Load() != 3 {
+		t.Errorf("unexpected failure: %+v after %d calls", queryErr, calls.Load())
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("expected attempts in message, got %q", err)
+	}
+}
+
+func TestExecuteQuery_DoesNotRetryPermanentOrWriteFailures(t *testing.T) {
+	client, calls, _ := newFlakyServer(t, 10, http.StatusBadRequest)
+	if _, err := client.ExecuteQuery("pe", "pe", "payment_engine", "SELECT bad"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a 400 to be sent once, got %d calls", calls.Load())
+	}
+
+	client, calls, _ = newFlakyServer(t, 10, http.StatusServiceUnavailable)
+	if _, err := client.ExecuteQuery("pe", "pe", "payment_engine", "UPDATE transfer SET status = 'FAILED'"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a write to be sent once, got %d calls", calls.Load())
+	}
+}
+
+func TestExecuteQuery_CircuitBreakerOpensPerCluster(t *testing.T) {
+	client, calls, _ := newFlakyServer(t, 100, http.StatusServiceUnavailable)
+	client.retry = RetryPolicy{MaxAttempts: 1}
+
+	for i := 0; i < DefaultBreakerThreshold; i++ {
+		_, _ = client.ExecuteQuery("pe", "pe", "payment_engine", "SELECT 1")
+	}
+	sent := calls.Load()
+
+	_, err := client.ExecuteQuery("pe", "pe", "payment_engine", "SELECT 1")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if calls.Load() != sent {
+		t.Error("an open circuit must not contact Doorman")
+	}
+
+	// Other clusters are unaffected
+	_, err = client.ExecuteQuery("pc", "pc", "payment_core", "SELECT 1")
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Error("breaker must be per cluster")
+	}
+}
+
+func TestCircuitBreaker_ProbeAfterCooldown(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	transient := &statusError{StatusCode: http.StatusServiceUnavailable}
+
+	breaker.record("pe", transient)
+	breaker.record("pe", transient)
+	if err := breaker.allow("pe"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := breaker.allow("pe"); err != nil {
+		t.Fatalf("expected a probe after cooldown, got %v", err)
+	}
+	if err := breaker.allow("pe"); !errors.Is(err, ErrCircuitOpen) {
+		t.Error("only one probe may run at a time")
+	}
+
+	breaker.record("pe", nil)
+	if err := breaker.allow("pe"); err != nil {
+		t.Errorf("expected a successful probe to close the circuit, got %v", err)
+	}
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT * FROM transfer":         true,
+		"  (select 1) union (select 2)":  true,
+		"show tables":                    true,
+		"UPDATE transfer SET status = 1": false,
+		"DELETE FROM transfer":           false,
+		"":                               false,
+	} {
+		if got := isReadOnlyQuery(query); got != want {
+			t.Errorf("isReadOnlyQuery(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
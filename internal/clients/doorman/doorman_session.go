@@ -0,0 +1,247 @@
+package doorman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionCacheEnvVar names an environment variable pointing at the Doorman
+// session cache file. Set it to "off" to log in on every run.
+const SessionCacheEnvVar = "BUDDY_DOORMAN_SESSION"
+
+// defaultSessionLifetime is assumed for session cookies that carry no expiry
+const defaultSessionLifetime = 8 * time.Hour
+
+// cachedCookie is the persisted form of a session cookie
+type cachedCookie struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Path    string    `json:"path,omitempty"`
+	Domain  string    `json:"domain,omitempty"`
+	Expires time.Time `json:"expires"`
+}
+
+// cachedSession is the login session of one user against one Doorman host
+type cachedSession struct {
+	Username string         `json:"username"`
+	Cookies  []cachedCookie `json:"cookies"`
+	SavedAt  time.Time      `json:"saved_at"`
+}
+
+// sessionStore persists Doorman session cookies, keyed by host, so later runs
+// can skip the login round trip
+type sessionStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// SessionCachePath returns the path of the Doorman session cache, or "" when
+// caching is turned off
+func SessionCachePath() string {
+	if path := os.Getenv(SessionCacheEnvVar); path != "" {
+		if strings.EqualFold(path, "off") {
+			return ""
+		}
+		return path
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "buddy", "doorman_session.json")
+}
+
+// newSessionStore returns a store at path, or nil when path is empty
+func newSessionStore(path string) *sessionStore {
+	if path == "" {
+		return nil
+	}
+	return &sessionStore{path: path}
+}
+
+func (s *sessionStore) readAll() map[string]cachedSession {
+	sessions := make(map[string]cachedSession)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return sessions
+	}
+	// A corrupt cache is treated as empty; the next login rewrites it
+	_ = json.Unmarshal(data, &sessions)
+	return sessions
+}
+
+func (s *sessionStore) writeAll(sessions map[string]cachedSession) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write through a temp file so concurrent runs never read a partial cache
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".doorman_session-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// load returns the unexpired cookies saved for username on host
+func (s *sessionStore) load(host, username string) []*http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.readAll()[host]
+	if !ok || session.Username != username {
+		return nil
+	}
+
+	now := time.Now()
+	var cookies []*http.Cookie
+	for _, c := range session.Cookies {
+		if !c.Expires.IsZero() && now.After(c.Expires) {
+			return nil
+		}
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain})
+	}
+	return cookies
+}
+
+// save records the cookies a login set for username on host
+func (s *sessionStore) save(host, username string, cookies []*http.Cookie) error {
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session := cachedSession{Username: username, SavedAt: now}
+	for _, c := range cookies {
+		expires := c.Expires
+		if c.MaxAge > 0 {
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		if expires.IsZero() {
+			expires = now.Add(defaultSessionLifetime)
+		}
+		session.Cookies = append(session.Cookies, cachedCookie{
+			Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, Expires: expires,
+		})
+	}
+
+	sessions := s.readAll()
+	sessions[host] = session
+	return s.writeAll(sessions)
+}
+
+// forget drops the session saved for host
+func (s *sessionStore) forget(host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := s.readAll()
+	if _, ok := sessions[host]; !ok {
+		return nil
+	}
+	delete(sessions, host)
+	return s.writeAll(sessions)
+}
+
+// isSessionExpired reports whether Doorman rejected a request because the
+// session is no longer valid: a 401, or a redirect to the login page
+func isSessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		return isLoginLocation(resp.Header.Get("Location"))
+	}
+	// The HTTP client follows redirects, so a redirect to login surfaces as the
+	// login page itself
+	return resp.Request != nil && resp.Request.URL != nil && isLoginLocation(resp.Request.URL.Path)
+}
+
+func isLoginLocation(location string) bool {
+	if location == "" {
+		return false
+	}
+	if u, err := url.Parse(location); err == nil {
+		location = u.Path
+	}
+	return strings.Contains(strings.ToLower(location), "login")
+}
+
+// do sends the request built by newRequest with the current session. If Doorman
+// reports the session expired, it logs in again and resends the request once.
+func (c *DoormanClient) do(newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.Authenticate(); err != nil {
+			return nil, fmt.Errorf("authentication error: %w", err)
+		}
+		generation := c.sessionGeneration()
+
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("network error: failed to send request: %w", err)
+		}
+		if attempt > 0 || !isSessionExpired(resp) {
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		c.invalidateSession(generation)
+	}
+}
+
+func (c *DoormanClient) sessionGeneration() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// invalidateSession discards the session seen at generation so the next
+// Authenticate logs in again. Callers that saw an older session do nothing,
+// so concurrent requests that all hit the expiry share one re-login.
+func (c *DoormanClient) invalidateSession(generation int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation || !c.authenticated {
+		return
+	}
+	c.authenticated = false
+	if c.sessions != nil {
+		_ = c.sessions.forget(c.config.Host)
+	}
+}
@@ -0,0 +1,150 @@
+package doorman
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// sessionServer issues a fresh session token on every login and only accepts
+// the most recent one
+type sessionServer struct {
+	*httptest.Server
+	logins  atomic.Int32
+	current atomic.Value
+}
+
+func newSessionServer(t *testing.T, redirectOnExpiry bool) *sessionServer {
+	t.Helper()
+	s := &sessionServer{}
+	s.current.Store("")
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login/ldap/signin":
+			token := "token-" + string(rune('0'+s.logins.Add(1)))
+			s.current.Store(token)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: token, Path: "/"})
+		case "/login":
+			_, _ = w.Write([]byte("<html>sign in</html>"))
+		case "/api/rds/query/execute":
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != s.current.Load().(string) {
+				if redirectOnExpiry {
+					http.Redirect(w, r, "/login", http.StatusFound)
+				} else {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":200,"result":{"headers":["id"],"rows":[[1]]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *sessionServer) newClient(store *sessionStore) *DoormanClient {
+	return &DoormanClient{
+		config:     DoormanConfig{Host: s.URL, AccountID: "acct-1", Auth: AuthInfo{Username: "oncall", Password: "secret"}},
+		httpClient: &http.Client{},
+		sessions:   store,
+	}
+}
+
+func TestDoormanClient_ReusesCachedSession(t *testing.T) {
+	server := newSessionServer(t, false)
+	store := newSessionStore(filepath.Join(t.TempDir(), "session.json"))
+
+	if _, err := server.newClient(store).ExecuteQuery("c", "i", "s", "SELECT 1"); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, err := server.newClient(store).ExecuteQuery("c", "i", "s", "SELECT 1"); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if got := server.logins.Load(); got != 1 {
+		t.Errorf("expected the second run to reuse the cached session, got %d logins", got)
+	}
+
+	info, err := os.Stat(store.path)
+	if err != nil {
+		t.Fatalf("session cache not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected session cache mode 0600, got %04o", perm)
+	}
+}
+
+func TestDoormanClient_ReauthenticatesExpiredSession(t *testing.T) {
+	for name, redirect := range map[string]bool{"unauthorized": false, "login redirect": true} {
+		t.Run(name, func(t *testing.T) {
+			server := newSessionServer(t, redirect)
+			store := newSessionStore(filepath.Join(t.TempDir(), "session.json"))
+
+			client := server.newClient(store)
+			if _, err := client.ExecuteQuery("c", "i", "s", "SELECT 1"); err != nil {
+				t.Fatalf("first query: %v", err)
+			}
+
+			// Doorman expires the session, e.g. after a long batch
+			server.current.Store("expired")
+
+			rows, err := client.ExecuteQuery("c", "i", "s", "SELECT 1")
+			if err != nil {
+				t.Fatalf("expected transparent re-authentication, got %v", err)
+			}
+			if len(rows) != 1 {
+				t.Errorf("expected 1 row, got %d", len(rows))
+			}
+			if got := server.logins.Load(); got != 2 {
+				t.Errorf("expected 2 logins, got %d", got)
+			}
+
+			// The replacement session is what later runs pick up
+			if _, err := server.newClient(store).ExecuteQuery("c", "i", "s", "SELECT 1"); err != nil {
+				t.Fatalf("run after re-login: %v", err)
+			}
+			if got := server.logins.Load(); got != 2 {
+				t.Errorf("expected the refreshed session to be cached, got %d logins", got)
+			}
+		})
+	}
+}
+
+func TestDoormanClient_StaleCachedSession(t *testing.T) {
+	server := newSessionServer(t, false)
+	path := filepath.Join(t.TempDir(), "session.json")
+	stale := map[string]cachedSession{
+		server.URL: {Username: "oncall", Cookies: []cachedCookie{{Name: "session", Value: "from-yesterday", Path: "/"}}},
+	}
+	data, _ := json.Marshal(stale)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := server.newClient(newSessionStore(path)).ExecuteQuery("c", "i", "s", "SELECT 1"); err != nil {
+		t.Fatalf("expected a stale cached session to be replaced, got %v", err)
+	}
+	if got := server.logins.Load(); got != 1 {
+		t.Errorf("expected 1 login, got %d", got)
+	}
+}
+
+func TestSessionStore_IgnoresOtherUser(t *testing.T) {
+	store := newSessionStore(filepath.Join(t.TempDir(), "session.json"))
+	if err := store.save("https://doorman", "alice", []*http.Cookie{{Name: "session", Value: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if cookies := store.load("https://doorman", "bob"); cookies != nil {
+		t.Errorf("expected no session for another user, got %v", cookies)
+	}
+	if cookies := store.load("https://doorman", "alice"); len(cookies) != 1 {
+		t.Errorf("expected alice's session, got %v", cookies)
+	}
+}
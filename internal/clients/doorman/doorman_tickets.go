@@ -0,0 +1,164 @@
+package doorman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DML ticket statuses reported by Doorman
+const (
+	TicketStatusPending   = "PENDING"
+	TicketStatusApproved  = "APPROVED"
+	TicketStatusExecuting = "EXECUTING"
+	TicketStatusExecuted  = "EXECUTED"
+	TicketStatusRejected  = "REJECTED"
+	TicketStatusFailed    = "FAILED"
+	TicketStatusCancelled = "CANCELLED"
+)
+
+// TicketFilter narrows ListTickets; empty fields are not filtered on
+type TicketFilter struct {
+	Submitter string // defaults to the authenticated user
+	Status    string
+	Limit     int // 0 uses the Doorman default page size
+}
+
+// ticketResponse is the envelope Doorman wraps ticket lookups in. It is the
+// envelope of the /api/rds/dml/create_ticket response (see CreateTicketResponse)
+// without the requestID, and every ticket is the same TicketResult object that
+// create_ticket returns.
+type ticketResponse struct {
+	Code    int            `json:"code"`
+	Errors  interface{}    `json:"errors"`
+	Message interface{}    `json:"message"`
+	Result  []TicketResult `json:"result"`
+}
+
+// IsTicketSettled reports whether a ticket has stopped waiting on a person:
+// it was approved, executed, rejected, failed or cancelled
+func IsTicketSettled(status string) bool {
+	switch strings.ToUpper(status) {
+	case TicketStatusApproved, TicketStatusExecuted, TicketStatusRejected, TicketStatusFailed, TicketStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTicketRejected reports whether a ticket will never run its DML
+func IsTicketRejected(status string) bool {
+	switch strings.ToUpper(status) {
+	case TicketStatusRejected, TicketStatusFailed, TicketStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetTicket fetches a DML ticket by ID from GET /api/rds/dml/get_ticket?ticketID=<id>.
+// The result holds the one ticket, or is empty when the ID does not exist.
+func (c *DoormanClient) GetTicket(ticketID string) (*TicketResult, error) {
+	if ticketID == "" {
+		return nil, fmt.Errorf("validation error: ticketID is required")
+	}
+	if _, err := strconv.Atoi(ticketID); err != nil {
+		return nil, fmt.Errorf("validation error: ticket ID %q is not numeric", ticketID)
+	}
+
+	params := url.Values{}
+	params.Set("ticketID", ticketID)
+
+	tickets, err := c.getTickets("/api/rds/dml/get_ticket", params)
+	if err != nil {
+		return nil, fmt.Errorf("get ticket %s: %w", ticketID, err)
+	}
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("get ticket %s: ticket not found", ticketID)
+	}
+	return &tickets[0], nil
+}
+
+// TicketURL returns the Doorman page of a DML ticket on the configured host
+func (c *DoormanClient) TicketURL(ticketID string) string {
+	ticketURL, _ := url.JoinPath(c.GetConfig().Host, "/rds/dml", ticketID)
+	return ticketURL
+}
+
+// ListTickets lists DML tickets in the configured account matching filter from
+// GET /api/rds/dml/list_tickets with the accountID, submitter, status and limit
+// query parameters, in the ticketResponse envelope.
+func (c *DoormanClient) ListTickets(filter TicketFilter) ([]TicketResult, error) {
+	cfg := c.GetConfig()
+
+	params := url.Values{}
+	params.Set("accountID", cfg.AccountID)
+	submitter := filter.Submitter
+	if submitter == "" {
+		submitter = cfg.Auth.Username
+	}
+	if submitter != "" {
+		params.Set("submitter", submitter)
+	}
+	if filter.Status != "" {
+		params.Set("status", strings.ToUpper(filter.Status))
+	}
+	if filter.Limit > 0 {
+		params.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	tickets, err := c.getTickets("/api/rds/dml/list_tickets", params)
+	if err != nil {
+		return nil, fmt.Errorf("list tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// getTickets performs an authenticated GET against a ticket endpoint
+func (c *DoormanClient) getTickets(path string, params url.Values) ([]TicketResult, error) {
+	cfg := c.GetConfig()
+	endpoint, err := url.JoinPath(cfg.Host, path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid doorman host: %w", err)
+	}
+
+	resp, err := c.do(func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("api error: %s (failed to read error response)", resp.Status)
+		}
+		return nil, fmt.Errorf("api error: %s - %s", resp.Status, string(body))
+	}
+
+	var response ticketResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if response.Code != 200 {
+		errorMsg := fmt.Sprintf("request failed with code: %d", response.Code)
+		if response.Errors != nil {
+			errorMsg += fmt.Sprintf(", errors: %v", response.Errors)
+		}
+		if response.Message != nil {
+			errorMsg += fmt.Sprintf(", message: %v", response.Message)
+		}
+		return nil, fmt.Errorf("api error: %s", errorMsg)
+	}
+
+	return response.Result, nil
+}
@@ -0,0 +1,161 @@
+package doorman
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// getTicketResponse is a get_ticket body in the wire format of create_ticket
+// responses, with every TicketResult field and null errors, message and peakTime,
+// so decoding is tested against raw JSON rather than the client's own types.
+const getTicketResponse = `{
+  "code": 200,
+  "errors": null,
+  "message": null,
+  "result": [{
+    "id": 43008,
+    "submitter": "oncall",
+    "status": "EXECUTED",
+    "owners": ["oncall"],
+    "oncallUsers": [],
+    "env": "prd",
+    "accountID": "acct-1",
+    "accountName": "payments",
+    "dbsManaged": false,
+    "clusterName": "prd-payments-payment-engine-rds-mysql",
+    "clusterType": "rds",
+    "clusterID": 12,
+    "instanceName": "prd-payments-payment-engine-rds-mysql-instance",
+    "instanceID": 34,
+    "oncallGroup": "payments",
+    "techFamily": "mysql",
+    "pagePath": "",
+    "note": "Fix stuck transfer",
+    "batch": 0,
+    "schema": "payment_engine",
+    "evaluateRows": 1,
+    "affectRows": 1,
+    "percentage": 100,
+    "originalQuery": "UPDATE workflow_execution SET state = 222 WHERE run_id = 'abc';",
+    "rollbackQuery": "UPDATE workflow_execution SET state = 220 WHERE run_id = 'abc';",
+    "subQuery": "",
+    "subMinID": 0,
+    "subMaxID": 0,
+    "encrypted": false,
+    "pattern": "",
+    "eoApprover": "",
+    "dbaApprover": "",
+    "toolLabel": "",
+    "database": "",
+    "fileDir": "",
+    "fileType": "",
+    "fileSize": 0,
+    "pauseLabel": 0,
+    "warningMsg": "",
+    "remark": "",
+    "peakTime": null,
+    "archived": false,
+    "createdAt": "2025-01-15T03:04:05Z",
+    "skipWhereClause": false,
+    "skipRollbackQuery": false,
+    "skipRollbackQueryReason": ""
+  }]
+}`
+
+func newTestTicketServer(t *testing.T) (*DoormanClient, *http.Request) {
+	t.Helper()
+	var lastRequest http.Request
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login/ldap/signin":
+			w.WriteHeader(http.StatusOK)
+		case "/api/rds/dml/get_ticket":
+			lastRequest = *r
+			if r.URL.Query().Get("ticketID") == "404" {
+				_ = json.NewEncoder(w).Encode(ticketResponse{Code: 200})
+				return
+			}
+			_, _ = w.Write([]byte(getTicketResponse))
+		case "/api/rds/dml/list_tickets":
+			lastRequest = *r
+			_, _ = w.Write([]byte(`{"code":200,"errors":null,"message":null,"result":[{"id":2,"status":"PENDING"},{"id":1,"status":"REJECTED"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := &DoormanClient{
+		config:     DoormanConfig{Host: server.URL, AccountID: "acct-1", Auth: AuthInfo{Username: "oncall", Password: "secret"}},
+		httpClient: server.Client(),
+	}
+	return client, &lastRequest
+}
+
+func TestDoormanClient_GetTicket(t *testing.T) {
+	client, lastRequest := newTestTicketServer(t)
+
+	ticket, err := client.GetTicket("43008")
+	if err != nil {
+		t.Fatalf("GetTicket: %v", err)
+	}
+	if ticket.ID != 43008 || !IsTicketSettled(ticket.Status) {
+		t.Errorf("unexpected ticket: %+v", ticket)
+	}
+	if ticket.Schema != "payment_engine" || ticket.AffectRows != 1 || ticket.PeakTime != nil || len(ticket.Owners) != 1 {
+		t.Errorf("unexpected ticket fields: %+v", ticket)
+	}
+	if got := lastRequest.URL.Query().Get("ticketID"); got != "43008" {
+		t.Errorf("expected ticketID=43008, got %q", got)
+	}
+
+	if _, err := client.GetTicket("404"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, err := client.GetTicket("abc"); err == nil {
+		t.Error("expected validation error for non-numeric ticket ID")
+	}
+}
+
+func TestDoormanClient_ListTickets(t *testing.T) {
+	client, lastRequest := newTestTicketServer(t)
+
+	tickets, err := client.ListTickets(TicketFilter{Status: "pending", Limit: 5})
+	if err != nil {
+		t.Fatalf("ListTickets: %v", err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("expected 2 tickets, got %d", len(tickets))
+	}
+
+	query := lastRequest.URL.Query()
+	for key, want := range map[string]string{"accountID": "acct-1", "submitter": "oncall", "status": "PENDING", "limit": "5"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestTicketStatusClassification(t *testing.T) {
+	for status, settled := range map[string]bool{"PENDING": false, "EXECUTING": false, "approved": true, "EXECUTED": true, "REJECTED": true} {
+		if got := IsTicketSettled(status); got != settled {
+			t.Errorf("IsTicketSettled(%q) = %v, want %v", status, got, settled)
+		}
+	}
+	if IsTicketRejected(TicketStatusExecuted) || !IsTicketRejected("rejected") {
+		t.Error("unexpected IsTicketRejected result")
+	}
+}
+
+func TestDoormanClient_TicketURL(t *testing.T) {
+	for _, host := range []string{"https://doorman.sgbank.pr", "https://doorman.sgbank.pr/"} {
+		client := &DoormanClient{config: DoormanConfig{Host: host}}
+		if got := client.TicketURL("43008"); got != "https://doorman.sgbank.pr/rds/dml/43008" {
+			t.Errorf("host %s: unexpected ticket URL %s", host, got)
+		}
+	}
+}
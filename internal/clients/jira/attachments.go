@@ -1,8 +1,12 @@
 package jira
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -90,3 +94,76 @@ func (c *JiraClient) resolveSavePath(savePath, filename string) string {
 
 	return savePath
 }
+
+// UploadAttachment attaches the file at filePath to an issue and returns the
+// attachments JIRA created
+func (c *JiraClient) UploadAttachment(ctx context.Context, issueKey string, filePath string) ([]Attachment, error) {
+	apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/attachments", c.config.Domain, issueKey)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to open attachment")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to create attachment form")
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to read attachment")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to create attachment form")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, &body)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to create upload request")
+	}
+
+	c.setAuthHeaders(req)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// JIRA rejects multipart uploads without this header as a CSRF safeguard
+	req.Header.Set("X-Atlassian-Token", "no-check")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "upload request failed")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleAPIError(resp)
+	}
+
+	var uploaded []struct {
+		ID       string `json:"id"`
+		Filename string `json:"filename"`
+		MimeType string `json:"mimeType"`
+		Content  string `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode upload response")
+	}
+
+	var attachments []Attachment
+	for _, att := range uploaded {
+		attachments = append(attachments, Attachment{
+			ID:       att.ID,
+			Filename: att.Filename,
+			MimeType: att.MimeType,
+			URL:      att.Content,
+			Content:  att.Content,
+		})
+	}
+
+	c.logger.Info("Uploaded %s to ticket: %s", filepath.Base(filePath), issueKey)
+	return attachments, nil
+}
@@ -0,0 +1,85 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ADFDoc is a document in JIRA's Atlassian Document Format
+type ADFDoc struct {
+	Version int       `json:"version"`
+	Type    string    `json:"type"`
+	Content []ADFNode `json:"content"`
+}
+
+// ADFNode is a block or inline node of an ADF document
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []ADFNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+}
+
+// NewADFDoc creates an ADF document from block nodes
+func NewADFDoc(blocks ...ADFNode) ADFDoc {
+	return ADFDoc{Version: 1, Type: "doc", Content: blocks}
+}
+
+// ADFParagraph creates a paragraph holding plain text
+func ADFParagraph(text string) ADFNode {
+	return ADFNode{Type: "paragraph", Content: []ADFNode{adfText(text)}}
+}
+
+// ADFCodeBlock creates a code block; language may be empty
+func ADFCodeBlock(language, text string) ADFNode {
+	node := ADFNode{Type: "codeBlock", Content: []ADFNode{adfText(text)}}
+	if language != "" {
+		node.Attrs = map[string]any{"language": language}
+	}
+	return node
+}
+
+// ADFBulletList creates a bullet list with one plain-text item per entry
+func ADFBulletList(items ...string) ADFNode {
+	list := ADFNode{Type: "bulletList"}
+	for _, item := range items {
+		list.Content = append(list.Content, ADFNode{Type: "listItem", Content: []ADFNode{ADFParagraph(item)}})
+	}
+	return list
+}
+
+// adfText creates an inline text node
+func adfText(text string) ADFNode {
+	return ADFNode{Type: "text", Text: text}
+}
+
+// AddComment posts a comment with an ADF body on an issue. Internal comments
+// are only visible to agents on service desk projects.
+func (c *JiraClient) AddComment(ctx context.Context, issueKey string, body ADFDoc, internal bool) error {
+	apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/comment", c.config.Domain, issueKey)
+
+	comment := commentRequest{Body: body}
+	if internal {
+		comment.Properties = []commentProperty{{Key: "sd.public.comment", Value: map[string]any{"internal": true}}}
+	}
+
+	if err := c.sendJSON(ctx, "POST", apiURL, comment, http.StatusCreated, nil); err != nil {
+		return err
+	}
+
+	c.logger.Info("Added comment to ticket: %s", issueKey)
+	return nil
+}
+
+// commentRequest is the payload of a new comment
+type commentRequest struct {
+	Body       ADFDoc            `json:"body"`
+	Properties []commentProperty `json:"properties,omitempty"`
+}
+
+// commentProperty is an entity property set on a comment
+type commentProperty struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
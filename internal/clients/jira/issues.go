@@ -0,0 +1,83 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"buddy/internal/errors"
+)
+
+// CreateIssue creates an issue from its fields, e.g. project, summary, issuetype
+// and custom fields, and returns the key of the new issue. ADFDoc values can be
+// used for rich-text fields.
+func (c *JiraClient) CreateIssue(ctx context.Context, fields map[string]any) (string, error) {
+	apiURL := fmt.Sprintf("%s/rest/api/3/issue", c.config.Domain)
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.sendJSON(ctx, "POST", apiURL, map[string]any{"fields": fields}, http.StatusCreated, &created); err != nil {
+		return "", err
+	}
+
+	c.logger.Info("Created ticket: %s", created.Key)
+	return created.Key, nil
+}
+
+// AssignIssue assigns an issue to the user with accountID; an empty accountID
+// unassigns it
+func (c *JiraClient) AssignIssue(ctx context.Context, issueKey string, accountID string) error {
+	apiURL := fmt.Sprintf("%s/rest/api/3/issue/%s/assignee", c.config.Domain, issueKey)
+
+	var assignee struct {
+		AccountID *string `json:"accountId"`
+	}
+	if accountID != "" {
+		assignee.AccountID = &accountID
+	}
+
+	if err := c.sendJSON(ctx, "PUT", apiURL, assignee, http.StatusNoContent, nil); err != nil {
+		return err
+	}
+
+	c.logger.Info("Assigned ticket %s to %s", issueKey, accountID)
+	return nil
+}
+
+// sendJSON sends payload as JSON and checks the response status. When out is
+// not nil, the response body is decoded into it.
+func (c *JiraClient) sendJSON(ctx context.Context, method, apiURL string, payload any, wantStatus int, out any) error {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to marshal JIRA request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to create JIRA request")
+	}
+
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "JIRA request failed")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != wantStatus {
+		return c.handleAPIError(resp)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode JIRA response")
+		}
+	}
+	return nil
+}
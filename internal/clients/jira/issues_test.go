@@ -0,0 +1,160 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buddy/internal/logging"
+)
+
+// newTestJiraServer serves the write endpoints of one issue and records the
+// request bodies by method and path
+func newTestJiraServer(t *testing.T) (*JiraClient, map[string]string) {
+	t.Helper()
+	requests := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rest/api/3/issue":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"1","key":"SHIPRM-1"}`))
+		case "POST /rest/api/3/issue/TS-1/comment":
+			w.WriteHeader(http.StatusCreated)
+		case "PUT /rest/api/3/issue/TS-1/assignee":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /rest/api/3/issue/TS-1/transitions":
+			_, _ = w.Write([]byte(`{"transitions":[{"id":"11","name":"Start","to":{"name":"In Progress"}},{"id":"21","name":"Resolve","to":{"name":"Done"}}]}`))
+		case "POST /rest/api/3/issue/TS-1/transitions":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /rest/api/3/issue/TS-1/attachments":
+			if r.Header.Get("X-Atlassian-Token") != "no-check" || !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id":"9","filename":"PE_Deploy.sql","mimeType":"application/sql","content":"https://jira/attachment/9"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := &JiraClient{
+		config:     JiraConfig{Domain: server.URL, Auth: JiraAuthInfo{Username: "oncall", APIKey: "secret"}},
+		httpClient: server.Client(),
+		logger:     logging.NewDefaultLogger("jira"),
+	}
+	return client, requests
+}
+
+func TestJiraClient_CreateIssue(t *testing.T) {
+	client, requests := newTestJiraServer(t)
+
+	key, err := client.CreateIssue(context.Background(), map[string]any{
+		"summary":     "Deregister PayNow",
+		"description": NewADFDoc(ADFParagraph("Delink PayNow account")),
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if key != "SHIPRM-1" {
+		t.Errorf("expected SHIPRM-1, got %q", key)
+	}
+
+	var sent struct {
+		Fields struct {
+			Description ADFDoc `json:"description"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(requests["POST /rest/api/3/issue"]), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Fields.Description.Type != "doc" || sent.Fields.Description.Content[0].Content[0].Text != "Delink PayNow account" {
+		t.Errorf("unexpected description: %+v", sent.Fields.Description)
+	}
+}
+
+func TestJiraClient_AddComment(t *testing.T) {
+	client, requests := newTestJiraServer(t)
+	path := "POST /rest/api/3/issue/TS-1/comment"
+
+	if err := client.AddComment(context.Background(), "TS-1", NewADFDoc(ADFParagraph("done")), false); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if strings.Contains(requests[path], "sd.public.comment") {
+		t.Errorf("expected a public comment, got %s", requests[path])
+	}
+
+	if err := client.AddComment(context.Background(), "TS-1", NewADFDoc(ADFParagraph("done")), true); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if !strings.Contains(requests[path], `{"key":"sd.public.comment","value":{"internal":true}}`) {
+		t.Errorf("expected an internal comment, got %s", requests[path])
+	}
+}
+
+func TestJiraClient_AssignIssue(t *testing.T) {
+	client, requests := newTestJiraServer(t)
+	path := "PUT /rest/api/3/issue/TS-1/assignee"
+
+	if err := client.AssignIssue(context.Background(), "TS-1", "acct-1"); err != nil {
+		t.Fatalf("AssignIssue: %v", err)
+	}
+	if requests[path] != `{"accountId":"acct-1"}` {
+		t.Errorf("unexpected assignee request: %s", requests[path])
+	}
+
+	if err := client.AssignIssue(context.Background(), "TS-1", ""); err != nil {
+		t.Fatalf("AssignIssue: %v", err)
+	}
+	if requests[path] != `{"accountId":null}` {
+		t.Errorf("expected unassign request, got %s", requests[path])
+	}
+}
+
+func TestJiraClient_TransitionTo(t *testing.T) {
+	client, requests := newTestJiraServer(t)
+
+	if err := client.TransitionTo(context.Background(), "TS-1", "in progress"); err != nil {
+		t.Fatalf("TransitionTo: %v", err)
+	}
+	if got := requests["POST /rest/api/3/issue/TS-1/transitions"]; got != `{"transition":{"id":"11"}}` {
+		t.Errorf("unexpected transition request: %s", got)
+	}
+
+	err := client.TransitionTo(context.Background(), "TS-1", "Blocked")
+	if err == nil || !strings.Contains(err.Error(), "available: In Progress, Done") {
+		t.Errorf("expected an error listing the available statuses, got %v", err)
+	}
+}
+
+func TestJiraClient_UploadAttachment(t *testing.T) {
+	client, requests := newTestJiraServer(t)
+
+	path := filepath.Join(t.TempDir(), "PE_Deploy.sql")
+	if err := os.WriteFile(path, []byte("UPDATE transfer SET status = 'COMPLETED';"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	attachments, err := client.UploadAttachment(context.Background(), "TS-1", path)
+	if err != nil {
+		t.Fatalf("UploadAttachment: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].ID != "9" || attachments[0].URL != "https://jira/attachment/9" {
+		t.Errorf("unexpected attachments: %+v", attachments)
+	}
+
+	body := requests["POST /rest/api/3/issue/TS-1/attachments"]
+	if !strings.Contains(body, `filename="PE_Deploy.sql"`) || !strings.Contains(body, "UPDATE transfer") {
+		t.Errorf("expected the file in the multipart body, got %s", body)
+	}
+}
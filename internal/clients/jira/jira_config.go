@@ -14,6 +14,15 @@ type JiraConfig struct {
 	MaxItems int    // Maximum items per request
 }
 
+// siteOverrides holds the Jira site chosen by the active profile, per environment
+var siteOverrides = map[string]JiraConfig{}
+
+// SetSiteOverride makes GetJiraConfig use the domain and project of override for
+// env wherever they are set. The DI container calls it with the active profile.
+func SetSiteOverride(env string, override JiraConfig) {
+	siteOverrides[env] = override
+}
+
 // GetJiraConfig returns the JIRA configuration for the specified environment
 func GetJiraConfig(env string) JiraConfig {
 	var cfg JiraConfig
@@ -45,5 +54,14 @@ func GetJiraConfig(env string) JiraConfig {
 		panic(fmt.Sprintf("country %s is not supported", env))
 	}
 
+	if override, ok := siteOverrides[env]; ok {
+		if override.Domain != "" {
+			cfg.Domain = override.Domain
+		}
+		if override.Project != "" {
+			cfg.Project = override.Project
+		}
+	}
+
 	return cfg
 }
@@ -12,14 +12,19 @@ type JiraInterface interface {
 	GetAssignedIssues(ctx context.Context, projectKey string, emails []string) ([]JiraTicket, error)
 	GetIssueDetails(ctx context.Context, issueKey string) (*JiraTicket, error)
 	SearchIssues(ctx context.Context, searchTerm string) ([]JiraTicket, error)
-	ExecuteJQL(ctx context.Context, jql string) ([]JiraTicket, error)
+	ExecuteJQL(ctx context.Context, jql string, opts SearchOptions) ([]JiraTicket, error)
 
 	// Attachment operations
 	GetAttachmentContent(ctx context.Context, attachmentURL string) ([]byte, error)
 	DownloadAttachment(ctx context.Context, attachment Attachment, savePath string) error
 	ParseCSVAttachment(content string) ([]CSVRow, error)
+	UploadAttachment(ctx context.Context, issueKey string, filePath string) ([]Attachment, error)
 
 	// Ticket lifecycle operations
+	CreateIssue(ctx context.Context, fields map[string]any) (string, error)
+	AddComment(ctx context.Context, issueKey string, body ADFDoc, internal bool) error
+	AssignIssue(ctx context.Context, issueKey string, accountID string) error
+	TransitionTo(ctx context.Context, issueKey string, statusName string) error
 	CloseTicket(ctx context.Context, issueKey string, reasonType string) error
 }
 
@@ -36,6 +41,10 @@ type JiraTicket struct {
 	DueAt       *time.Time   `json:"due_at,omitempty"`
 	Attachments []Attachment `json:"attachments,omitempty"`
 	IssueType   string       `json:"issue_type"`
+
+	// Raw values of the extra fields and expanded sections of a search
+	Fields   map[string]any `json:"fields,omitempty"`
+	Expanded map[string]any `json:"expanded,omitempty"`
 }
 
 // Attachment represents a JIRA attachment
@@ -12,21 +12,35 @@ import (
 	"buddy/internal/errors"
 )
 
+// DefaultSearchMaxResults caps the tickets a search returns across all pages
+// when SearchOptions.MaxResults is not set
+const DefaultSearchMaxResults = 1000
+
+// defaultSearchFields are the fields JiraTicket is built from
+var defaultSearchFields = []string{"assignee", "summary", "issuetype", "key", "priority", "status", "created", "duedate", "customfield_10060", "description", "attachment"}
+
+// SearchOptions controls what a JQL search returns
+type SearchOptions struct {
+	Fields     []string // extra fields to return raw in JiraTicket.Fields
+	Expand     []string // sections to expand, e.g. renderedFields or changelog, returned in JiraTicket.Expanded
+	MaxResults int      // cap on tickets across all pages; DefaultSearchMaxResults if 0
+}
+
 // GetAssignedIssues fetches issues assigned to specified emails or currentUser()
 func (c *JiraClient) GetAssignedIssues(ctx context.Context, projectKey string, emails []string) ([]JiraTicket, error) {
 	jql := c.buildAssignedIssuesJQL(projectKey, emails)
-	return c.executeSearch(ctx, jql)
+	return c.executeSearch(ctx, jql, SearchOptions{})
 }
 
 // SearchIssues searches for issues matching the search term in summary or description
 func (c *JiraClient) SearchIssues(ctx context.Context, searchTerm string) ([]JiraTicket, error) {
 	jql := c.buildSearchJQL(searchTerm)
-	return c.executeSearch(ctx, jql)
+	return c.executeSearch(ctx, jql, SearchOptions{})
 }
 
 // ExecuteJQL executes a raw JQL query and returns matching tickets
-func (c *JiraClient) ExecuteJQL(ctx context.Context, jql string) ([]JiraTicket, error) {
-	return c.executeSearch(ctx, jql)
+func (c *JiraClient) ExecuteJQL(ctx context.Context, jql string, opts SearchOptions) ([]JiraTicket, error) {
+	return c.executeSearch(ctx, jql, opts)
 }
 
 // GetIssueDetails fetches full details for a specific issue
@@ -105,25 +119,79 @@ func (c *JiraClient) escapeJQLTerm(term string) string {
 	return term
 }
 
-// executeSearch executes a JQL search and returns tickets
-func (c *JiraClient) executeSearch(ctx context.Context, jql string) ([]JiraTicket, error) {
+// executeSearch executes a JQL search and returns tickets, following the
+// nextPageToken cursor page by page until the last page or opts.MaxResults
+func (c *JiraClient) executeSearch(ctx context.Context, jql string, opts SearchOptions) ([]JiraTicket, error) {
 	apiURL, err := url.Parse(c.config.Domain + "/rest/api/3/search/jql")
 	if err != nil {
 		return nil, errors.Validation("invalid JIRA domain")
 	}
 
-	requestPayload := map[string]any{
-		"jql":        jql,
-		"fields":     []string{"assignee", "summary", "issuetype", "key", "priority", "status", "created", "duedate", "customfield_10060", "description", "attachment"},
-		"maxResults": c.config.MaxItems,
+	maxResults := opts.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultSearchMaxResults
+	}
+	pageSize := c.config.MaxItems
+	if pageSize <= 0 {
+		pageSize = 50
 	}
 
+	fields := append(append([]string{}, defaultSearchFields...), opts.Fields...)
+
+	var tickets []JiraTicket
+	nextPageToken := ""
+	for {
+		requestPayload := map[string]any{
+			"jql":        jql,
+			"fields":     fields,
+			"maxResults": min(pageSize, maxResults-len(tickets)),
+		}
+		if len(opts.Expand) > 0 {
+			requestPayload["expand"] = strings.Join(opts.Expand, ",")
+		}
+		if nextPageToken != "" {
+			requestPayload["nextPageToken"] = nextPageToken
+		}
+
+		page, err := c.searchPage(ctx, apiURL.String(), requestPayload)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, raw := range page.Issues {
+			ticket, err := c.convertSearchIssue(raw, opts)
+			if err != nil {
+				return nil, err
+			}
+			tickets = append(tickets, *ticket)
+		}
+
+		if page.IsLast || page.NextPageToken == "" || len(page.Issues) == 0 {
+			return tickets, nil
+		}
+		if len(tickets) >= maxResults {
+			c.logger.Warn("JIRA search stopped at %d tickets; more match %q", len(tickets), jql)
+			return tickets, nil
+		}
+		nextPageToken = page.NextPageToken
+	}
+}
+
+// searchPage is one page of JQL search results
+type searchPage struct {
+	Issues        []json.RawMessage `json:"issues"`
+	NextPageToken string            `json:"nextPageToken"`
+	IsLast        bool              `json:"isLast"`
+}
+
+// searchPage fetches one page of JQL search results
+func (c *JiraClient) searchPage(ctx context.Context, apiURL string, requestPayload map[string]any) (*searchPage, error) {
 	reqBody, err := json.Marshal(requestPayload)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to marshal search payload")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL.String(), bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to create JIRA request")
 	}
@@ -142,22 +210,52 @@ func (c *JiraClient) executeSearch(ctx context.Context, jql string) ([]JiraTicke
 		return nil, c.handleAPIError(resp)
 	}
 
-	var searchResponse struct {
-		Issues []jiraIssueResponse `json:"issues"`
+	var page searchPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode JIRA search response")
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&searchResponse); err != nil {
-		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode JIRA search response")
+	return &page, nil
+}
+
+// convertSearchIssue converts one issue of a search page to a JiraTicket, keeping
+// the raw values of the extra fields and expanded sections requested in opts
+func (c *JiraClient) convertSearchIssue(raw json.RawMessage, opts SearchOptions) (*JiraTicket, error) {
+	var issue jiraIssueResponse
+	if err := json.Unmarshal(raw, &issue); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode JIRA issue")
 	}
 
-	tickets := make([]JiraTicket, len(searchResponse.Issues))
-	for i, issue := range searchResponse.Issues {
-		ticket, err := c.convertIssueResponse(&issue)
-		if err != nil {
-			return nil, err
+	ticket, err := c.convertIssueResponse(&issue)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Fields) == 0 && len(opts.Expand) == 0 {
+		return ticket, nil
+	}
+
+	var sections map[string]any
+	if err := json.Unmarshal(raw, &sections); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to decode JIRA issue")
+	}
+
+	if fields, ok := sections["fields"].(map[string]any); ok && len(opts.Fields) > 0 {
+		ticket.Fields = make(map[string]any, len(opts.Fields))
+		for _, field := range opts.Fields {
+			if value, ok := fields[field]; ok {
+				ticket.Fields[field] = value
+			}
+		}
+	}
+
+	for _, section := range opts.Expand {
+		if value, ok := sections[section]; ok {
+			if ticket.Expanded == nil {
+				ticket.Expanded = make(map[string]any)
+			}
+			ticket.Expanded[section] = value
 		}
-		tickets[i] = *ticket
 	}
 
-	return tickets, nil
+	return ticket, nil
 }
@@ -0,0 +1,113 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"buddy/internal/logging"
+)
+
+// newTestSearchServer serves total issues TS-1..TS-<total> in pages of the
+// requested size and records every search request
+func newTestSearchServer(t *testing.T, total int) (*JiraClient, *[]map[string]any) {
+	t.Helper()
+	var requests []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/search/jql" {
+			http.NotFound(w, r)
+			return
+		}
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		requests = append(requests, payload)
+
+		start := 0
+		if token, ok := payload["nextPageToken"].(string); ok {
+			_, _ = fmt.Sscanf(token, "page-%d", &start)
+		}
+		end := min(start+int(payload["maxResults"].(float64)), total)
+
+		var issues []map[string]any
+		for i := start; i < end; i++ {
+			issues = append(issues, map[string]any{
+				"key":            fmt.Sprintf("TS-%d", i+1),
+				"fields":         map[string]any{"summary": "stuck transfer", "customfield_1": i + 1},
+				"renderedFields": map[string]any{"summary": "<p>stuck transfer</p>"},
+			})
+		}
+		page := map[string]any{"issues": issues, "isLast": end >= total}
+		if end < total {
+			page["nextPageToken"] = fmt.Sprintf("page-%d", end)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &JiraClient{
+		config:     JiraConfig{Domain: server.URL, MaxItems: 2},
+		httpClient: server.Client(),
+		logger:     logging.NewDefaultLogger("jira"),
+	}
+	return client, &requests
+}
+
+func TestJiraClient_ExecuteJQL_Pagination(t *testing.T) {
+	t.Run("follows the cursor to the last page", func(t *testing.T) {
+		client, requests := newTestSearchServer(t, 5)
+
+		tickets, err := client.ExecuteJQL(context.Background(), "project = TS", SearchOptions{})
+		if err != nil {
+			t.Fatalf("ExecuteJQL: %v", err)
+		}
+		if len(tickets) != 5 || tickets[4].Key != "TS-5" {
+			t.Errorf("expected TS-1..TS-5, got %d tickets", len(tickets))
+		}
+		if len(*requests) != 3 {
+			t.Errorf("expected 3 pages, got %d", len(*requests))
+		}
+		if tickets[0].Fields != nil || tickets[0].Expanded != nil {
+			t.Errorf("expected no raw fields without options, got %+v", tickets[0])
+		}
+	})
+
+	t.Run("stops at the max results cap", func(t *testing.T) {
+		client, requests := newTestSearchServer(t, 10)
+
+		tickets, err := client.ExecuteJQL(context.Background(), "project = TS", SearchOptions{MaxResults: 3})
+		if err != nil {
+			t.Fatalf("ExecuteJQL: %v", err)
+		}
+		if len(tickets) != 3 {
+			t.Errorf("expected 3 tickets, got %d", len(tickets))
+		}
+		if got := (*requests)[1]["maxResults"]; got != float64(1) {
+			t.Errorf("expected the last page to request 1 ticket, got %v", got)
+		}
+	})
+
+	t.Run("returns requested fields and expanded sections", func(t *testing.T) {
+		client, requests := newTestSearchServer(t, 1)
+
+		tickets, err := client.ExecuteJQL(context.Background(), "key = TS-1", SearchOptions{
+			Fields: []string{"customfield_1"},
+			Expand: []string{"renderedFields", "changelog"},
+		})
+		if err != nil {
+			t.Fatalf("ExecuteJQL: %v", err)
+		}
+		if got := (*requests)[0]["expand"]; got != "renderedFields,changelog" {
+			t.Errorf("unexpected expand: %v", got)
+		}
+		if tickets[0].Summary != "stuck transfer" || tickets[0].Fields["customfield_1"] != float64(1) {
+			t.Errorf("unexpected ticket: %+v", tickets[0])
+		}
+		if _, ok := tickets[0].Expanded["renderedFields"]; !ok || len(tickets[0].Expanded) != 1 {
+			t.Errorf("expected only renderedFields to be expanded, got %+v", tickets[0].Expanded)
+		}
+	})
+}
@@ -23,7 +23,41 @@ func (c *JiraClient) CloseTicket(ctx context.Context, issueKey string, reasonTyp
 		return errors.NotFound("close transition")
 	}
 
-	return c.executeTransition(ctx, issueKey, targetTransitionID)
+	if err := c.executeTransition(ctx, issueKey, targetTransitionID); err != nil {
+		return err
+	}
+
+	c.logger.Info("Successfully closed ticket: %s", issueKey)
+	return nil
+}
+
+// TransitionTo moves an issue to statusName using the available transition whose
+// name or target status matches it, ignoring case
+func (c *JiraClient) TransitionTo(ctx context.Context, issueKey string, statusName string) error {
+	transitions, err := c.getAvailableTransitions(ctx, issueKey)
+	if err != nil {
+		return err
+	}
+
+	targetTransitionID := ""
+	var available []string
+	for _, t := range transitions {
+		if strings.EqualFold(t.To.Name, statusName) || strings.EqualFold(t.Name, statusName) {
+			targetTransitionID = t.ID
+			break
+		}
+		available = append(available, t.To.Name)
+	}
+	if targetTransitionID == "" {
+		return errors.New(errors.ErrorTypeNotFound, fmt.Sprintf("no transition to %q from the current status; available: %s", statusName, strings.Join(available, ", ")))
+	}
+
+	if err := c.executeTransition(ctx, issueKey, targetTransitionID); err != nil {
+		return err
+	}
+
+	c.logger.Info("Moved ticket %s to %s", issueKey, statusName)
+	return nil
 }
 
 // getAvailableTransitions fetches available transitions for an issue
@@ -106,7 +140,6 @@ func (c *JiraClient) executeTransition(ctx context.Context, issueKey, transition
 		return c.handleAPIError(resp)
 	}
 
-	c.logger.Info("Successfully closed ticket: %s", issueKey)
 	return nil
 }
 
@@ -114,4 +147,7 @@ func (c *JiraClient) executeTransition(ctx context.Context, issueKey, transition
 type transitionInfo struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"buddy/internal/buildinfo"
 	"fmt"
+	"os"
 )
 
 type Config struct {
@@ -12,11 +13,18 @@ type Config struct {
 var globalConfig *Config
 
 func LoadConfig() error {
-	// Validate that all required constants were set at build time
+	// Validate that the build environment was set at build time
 	if err := buildinfo.ValidateConstants(); err != nil {
 		return fmt.Errorf("build-time validation failed: %w", err)
 	}
 
+	// Refuse a credentials file other users can read before anything reads from it
+	if path, _ := CredentialsFilePath(); path != "" {
+		if err := NewFileCredentialProvider(path).Check(); err != nil {
+			return err
+		}
+	}
+
 	globalConfig = &Config{Environment: buildinfo.BuildEnvironment}
 	return nil
 }
@@ -28,37 +36,16 @@ func GetEnvironment() string {
 	return "unknown"
 }
 
+// Get returns a credential or setting from the credential chain (environment,
+// credentials file, helper, keyring, build-time constants), or defaultValue
 func Get(key, defaultValue string) string {
-	// Return compile-time constants instead of os.Getenv
-	switch key {
-	case "JIRA_DOMAIN":
-		if buildinfo.JiraDomain != "" {
-			return buildinfo.JiraDomain
-		}
-	case "JIRA_USERNAME":
-		if buildinfo.JiraUsername != "" {
-			return buildinfo.JiraUsername
-		}
-	case "JIRA_API_KEY":
-		if buildinfo.JiraApiKey != "" {
-			return buildinfo.JiraApiKey
-		}
-	case "DOORMAN_USERNAME":
-		if buildinfo.DoormanUsername != "" {
-			return buildinfo.DoormanUsername
-		}
-	case "DOORMAN_PASSWORD":
-		if buildinfo.DoormanPassword != "" {
-			return buildinfo.DoormanPassword
-		}
-	case "DD_API_KEY":
-		if buildinfo.DatadogApiKey != "" {
-			return buildinfo.DatadogApiKey
-		}
-	case "DD_APPLICATION_KEY":
-		if buildinfo.DatadogAppKey != "" {
-			return buildinfo.DatadogAppKey
-		}
+	value, err := Credentials().Lookup(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return defaultValue
+	}
+	if value == "" {
+		return defaultValue
 	}
-	return defaultValue
+	return value
 }
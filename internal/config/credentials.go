@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"buddy/internal/buildinfo"
+	"buddy/internal/errors"
+)
+
+// CredentialsFileEnvVar names an environment variable pointing at the credentials file
+const CredentialsFileEnvVar = "BUDDY_CREDENTIALS"
+
+// CredentialHelperEnvVar names an external command that prints credentials. It is
+// run as "<command> get <KEY>" and should print the value on stdout, or exit
+// non-zero without output when it does not have the key.
+const CredentialHelperEnvVar = "BUDDY_CREDENTIAL_HELPER"
+
+// keyringService is the service name credentials are stored under in the OS keyring
+const keyringService = "buddy"
+
+// CredentialProvider is one source in the credential chain. Implement it to
+// plug in another secret store and add it with RegisterCredentialProvider.
+type CredentialProvider interface {
+	// Name identifies the provider in error messages
+	Name() string
+	// Lookup returns the value for key; ok is false when the provider does not have it
+	Lookup(key string) (value string, ok bool, err error)
+}
+
+// CredentialChain asks each provider in turn and caches what it finds
+type CredentialChain struct {
+	mu        sync.Mutex
+	providers []CredentialProvider
+	cache     map[string]string
+}
+
+// NewCredentialChain creates a chain that consults providers in order
+func NewCredentialChain(providers ...CredentialProvider) *CredentialChain {
+	return &CredentialChain{providers: providers, cache: make(map[string]string)}
+}
+
+// Lookup returns the value from the first provider that has key, or "" if none do
+func (c *CredentialChain) Lookup(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, ok := c.cache[key]; ok {
+		return value, nil
+	}
+
+	for _, provider := range c.providers {
+		value, ok, err := provider.Lookup(key)
+		if err != nil {
+			return "", errors.Wrap(err, errors.ErrorTypeConfiguration,
+				fmt.Sprintf("failed to read %s from %s", key, provider.Name()))
+		}
+		if ok && value != "" {
+			c.cache[key] = value
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// Insert adds a provider before the build-time fallback, which always stays last
+func (c *CredentialChain) Insert(provider CredentialProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	position := len(c.providers)
+	if position > 0 {
+		if _, isFallback := c.providers[position-1].(buildInfoProvider); isFallback {
+			position--
+		}
+	}
+	c.providers = append(c.providers[:position], append([]CredentialProvider{provider}, c.providers[position:]...)...)
+	c.cache = make(map[string]string)
+}
+
+var (
+	credentialChain     *CredentialChain
+	credentialChainOnce sync.Once
+)
+
+// Credentials returns the process-wide credential chain: environment variables,
+// the credentials file, BUDDY_CREDENTIAL_HELPER, the OS keyring and finally any
+// values baked in at build time.
+func Credentials() *CredentialChain {
+	credentialChainOnce.Do(func() {
+		if credentialChain != nil {
+			return
+		}
+		providers := []CredentialProvider{envProvider{}}
+		if path, _ := CredentialsFilePath(); path != "" {
+			providers = append(providers, NewFileCredentialProvider(path))
+		}
+		if helper := os.Getenv(CredentialHelperEnvVar); helper != "" {
+			providers = append(providers, NewHelperCredentialProvider(helper))
+		}
+		if keyring := NewKeyringCredentialProvider(); keyring != nil {
+			providers = append(providers, keyring)
+		}
+		providers = append(providers, buildInfoProvider{})
+		credentialChain = NewCredentialChain(providers...)
+	})
+	return credentialChain
+}
+
+// SetCredentials replaces the process-wide credential chain
+func SetCredentials(chain *CredentialChain) {
+	credentialChainOnce.Do(func() {})
+	credentialChain = chain
+}
+
+// RegisterCredentialProvider adds a provider to the process-wide chain, ahead
+// of the build-time fallback
+func RegisterCredentialProvider(provider CredentialProvider) {
+	Credentials().Insert(provider)
+}
+
+// MissingCredentialHint explains where a missing credential can be configured
+func MissingCredentialHint(key string) string {
+	path, _ := CredentialsFilePath()
+	return fmt.Sprintf("%s is not configured: export it, or add %s=... to %s (chmod 600)", key, key, path)
+}
+
+// envProvider reads credentials from environment variables of the same name
+type envProvider struct{}
+
+func (envProvider) Name() string { return "environment" }
+
+func (envProvider) Lookup(key string) (string, bool, error) {
+	value, ok := os.LookupEnv(key)
+	return value, ok, nil
+}
+
+// CredentialsFilePath returns the path of the credentials file and whether it
+// was explicitly requested through BUDDY_CREDENTIALS.
+func CredentialsFilePath() (string, bool) {
+	if path := os.Getenv(CredentialsFileEnvVar); path != "" {
+		return path, true
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, ".config", "buddy", "credentials"), false
+}
+
+// FileCredentialProvider reads KEY=value lines from a file that only its owner
+// may read. Blank lines and lines starting with # are ignored.
+type FileCredentialProvider struct {
+	path   string
+	once   sync.Once
+	values map[string]string
+	err    error
+}
+
+// NewFileCredentialProvider creates a provider for the credentials file at path
+func NewFileCredentialProvider(path string) *FileCredentialProvider {
+	return &FileCredentialProvider{path: path}
+}
+
+func (p *FileCredentialProvider) Name() string { return p.path }
+
+func (p *FileCredentialProvider) Lookup(key string) (string, bool, error) {
+	p.once.Do(p.load)
+	if p.err != nil {
+		return "", false, p.err
+	}
+	value, ok := p.values[key]
+	return value, ok, nil
+}
+
+// Check reports an error if the file exists but is unsafe or unreadable
+func (p *FileCredentialProvider) Check() error {
+	p.once.Do(p.load)
+	return p.err
+}
+
+func (p *FileCredentialProvider) load() {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			p.err = err
+		}
+		return
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		p.err = fmt.Errorf("credentials file %s is accessible by other users (mode %04o); run: chmod 600 %s",
+			p.path, info.Mode().Perm(), p.path)
+		return
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.values, p.err = parseCredentials(data)
+}
+
+// parseCredentials decodes KEY=value lines; values may be wrapped in quotes
+func parseCredentials(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// commandProvider runs an external command to look up each credential
+type commandProvider struct {
+	name string
+	args func(key string) []string
+}
+
+func (p commandProvider) Name() string { return p.name }
+
+func (p commandProvider) Lookup(key string) (string, bool, error) {
+	args := p.args(key)
+	var stdout bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if _, exited := err.(*exec.ExitError); exited {
+			// A non-zero exit means the store does not have the key
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	return value, value != "", nil
+}
+
+// NewHelperCredentialProvider creates a provider that runs "<command> get <KEY>".
+// command is split on whitespace, so it may carry its own arguments.
+func NewHelperCredentialProvider(command string) CredentialProvider {
+	fields := strings.Fields(command)
+	return commandProvider{
+		name: "credential helper " + command,
+		args: func(key string) []string {
+			return append(append([]string{}, fields...), "get", key)
+		},
+	}
+}
+
+// NewKeyringCredentialProvider returns a provider backed by the OS keyring, or
+// nil if the platform's keyring tool is not installed. Credentials are stored
+// under service "buddy" with the key as the account, e.g. on macOS:
+//
+//	security add-generic-password -s buddy -a DOORMAN_PASSWORD -w
+func NewKeyringCredentialProvider() CredentialProvider {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err != nil {
+			return nil
+		}
+		return commandProvider{
+			name: "macOS keychain",
+			args: func(key string) []string {
+				return []string{"security", "find-generic-password", "-s", keyringService, "-a", key, "-w"}
+			},
+		}
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil
+		}
+		return commandProvider{
+			name: "secret service keyring",
+			args: func(key string) []string {
+				return []string{"secret-tool", "lookup", "service", keyringService, "account", key}
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// buildInfoProvider serves values baked in with -ldflags, kept as a fallback so
+// existing builds keep working
+type buildInfoProvider struct{}
+
+func (buildInfoProvider) Name() string { return "build-time constants" }
+
+func (buildInfoProvider) Lookup(key string) (string, bool, error) {
+	var value string
+	switch key {
+	case "JIRA_DOMAIN":
+		value = buildinfo.JiraDomain
+	case "JIRA_USERNAME":
+		value = buildinfo.JiraUsername
+	case "JIRA_API_KEY":
+		value = buildinfo.JiraApiKey
+	case "DOORMAN_USERNAME":
+		value = buildinfo.DoormanUsername
+	case "DOORMAN_PASSWORD":
+		value = buildinfo.DoormanPassword
+	case "DD_API_KEY":
+		value = buildinfo.DatadogApiKey
+	case "DD_APPLICATION_KEY":
+		value = buildinfo.DatadogAppKey
+	}
+	return value, value != "", nil
+}
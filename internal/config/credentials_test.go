@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// staticProvider serves fixed values, standing in for a keyring or helper
+type staticProvider map[string]string
+
+func (staticProvider) Name() string { return "static" }
+
+func (p staticProvider) Lookup(key string) (string, bool, error) {
+	value, ok := p[key]
+	return value, ok, nil
+}
+
+func writeCredentials(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCredentialChain_Order(t *testing.T) {
+	path := writeCredentials(t, "# team credentials\nDOORMAN_USERNAME=file-user\nexport DOORMAN_PASSWORD=\"file pass\"\n", 0o600)
+	t.Setenv("DOORMAN_USERNAME", "env-user")
+
+	chain := NewCredentialChain(envProvider{}, NewFileCredentialProvider(path), staticProvider{"JIRA_API_KEY": "keyring-key"}, buildInfoProvider{})
+
+	for key, want := range map[string]string{
+		"DOORMAN_USERNAME": "env-user",
+		"DOORMAN_PASSWORD": "file pass",
+		"JIRA_API_KEY":     "keyring-key",
+		"DD_API_KEY":       "",
+	} {
+		got, err := chain.Lookup(key)
+		if err != nil || got != want {
+			t.Errorf("Lookup(%s) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+}
+
+func TestCredentialChain_Insert(t *testing.T) {
+	chain := NewCredentialChain(envProvider{}, buildInfoProvider{})
+	chain.Insert(staticProvider{"DD_API_KEY": "plugged"})
+
+	if _, ok := chain.providers[len(chain.providers)-1].(buildInfoProvider); !ok {
+		t.Error("build-time constants must stay the last resort")
+	}
+	if got, _ := chain.Lookup("DD_API_KEY"); got != "plugged" {
+		t.Errorf("expected plugged provider to be consulted, got %q", got)
+	}
+}
+
+func TestFileCredentialProvider_RejectsOpenPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := writeCredentials(t, "DOORMAN_PASSWORD=secret\n", 0o644)
+
+	provider := NewFileCredentialProvider(path)
+	if err := provider.Check(); err == nil || !strings.Contains(err.Error(), "chmod 600") {
+		t.Errorf("expected permission error, got %v", err)
+	}
+	if _, err := NewCredentialChain(provider).Lookup("DOORMAN_PASSWORD"); err == nil {
+		t.Error("expected lookup through an insecure file to fail")
+	}
+}
+
+func TestFileCredentialProvider_Missing(t *testing.T) {
+	provider := NewFileCredentialProvider(filepath.Join(t.TempDir(), "credentials"))
+	if err := provider.Check(); err != nil {
+		t.Errorf("a missing credentials file is not an error, got %v", err)
+	}
+	if _, ok, _ := provider.Lookup("DOORMAN_PASSWORD"); ok {
+		t.Error("expected no value from a missing file")
+	}
+}
+
+func TestParseCredentials_Invalid(t *testing.T) {
+	if _, err := parseCredentials([]byte("DOORMAN_PASSWORD\n")); err == nil {
+		t.Error("expected a line without = to be rejected")
+	}
+}
+
+func TestHelperCredentialProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script uses sh")
+	}
+	script := filepath.Join(t.TempDir(), "helper")
+	content := "#!/bin/sh\n[ \"$1\" = get ] && [ \"$2\" = JIRA_API_KEY ] && echo from-helper && exit 0\nexit 1\n"
+	if err := os.WriteFile(script, []byte(content), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewHelperCredentialProvider(script)
+	if value, ok, err := provider.Lookup("JIRA_API_KEY"); err != nil || !ok || value != "from-helper" {
+		t.Errorf("unexpected helper result: %q, %v, %v", value, ok, err)
+	}
+	if _, ok, err := provider.Lookup("DD_API_KEY"); err != nil || ok {
+		t.Errorf("expected helper to report a missing key, got %v, %v", ok, err)
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-//go:embed workflow_states.yaml fast_adapter_states.yaml
+//go:embed workflow_states.yaml fast_adapter_states.yaml profiles.yaml
 var configFS embed.FS
 
 // WorkflowStates represents the workflow state configuration
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"buddy/internal/errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnvVar selects a profile when --profile is not given
+const ProfileEnvVar = "BUDDY_PROFILE"
+
+// ProfilesEnvVar names an environment variable pointing at a profiles override file
+const ProfilesEnvVar = "BUDDY_PROFILES"
+
+// Profile selects the backends a binary talks to. Country decides the SOP rules
+// and commands; the other fields default to the country's production sites.
+type Profile struct {
+	Name        string `yaml:"-"`
+	Country     string `yaml:"country"`      // my or sg
+	Doorman     string `yaml:"doorman"`      // environment in the Doorman config
+	JiraDomain  string `yaml:"jira_domain"`  // e.g. https://gxbank.atlassian.net
+	JiraProject string `yaml:"jira_project"` // default project key, e.g. TS
+	DatadogURL  string `yaml:"datadog_url"`  // Datadog API base URL
+}
+
+// profilesFile is the top-level layout of a profiles YAML file
+type profilesFile struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// parseProfiles decodes profiles from YAML, rejecting unknown keys
+func parseProfiles(data []byte) (map[string]Profile, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file profilesFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration, "failed to parse profiles YAML")
+	}
+
+	for name, profile := range file.Profiles {
+		if profile.Country != "my" && profile.Country != "sg" {
+			return nil, errors.Configuration(fmt.Sprintf("profile %s has country %q, expected my or sg", name, profile.Country))
+		}
+		profile.Name = name
+		if profile.Doorman == "" {
+			profile.Doorman = profile.Country
+		}
+		file.Profiles[name] = profile
+	}
+
+	return file.Profiles, nil
+}
+
+// ProfilesOverridePath returns the path of the profiles override file and
+// whether it was explicitly requested through BUDDY_PROFILES.
+func ProfilesOverridePath() (string, bool) {
+	if path := os.Getenv(ProfilesEnvVar); path != "" {
+		return path, true
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, ".config", "buddy", "profiles.yaml"), false
+}
+
+// LoadProfiles returns the embedded profiles, with profiles from the override
+// file added or replacing those of the same name
+func LoadProfiles() (map[string]Profile, error) {
+	data, err := configFS.ReadFile("profiles.yaml")
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration, "failed to read embedded profiles")
+	}
+	profiles, err := parseProfiles(data)
+	if err != nil {
+		return nil, err
+	}
+
+	path, explicit := ProfilesOverridePath()
+	if path == "" {
+		return profiles, nil
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return profiles, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration,
+			fmt.Sprintf("profiles override %s is not readable", path))
+	}
+
+	overrides, err := parseProfiles(data)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration, path)
+	}
+	for name, profile := range overrides {
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// ResolveProfile picks the profile for a binary built for country. An empty name
+// falls back to BUDDY_PROFILE and then to the country's production profile.
+func ResolveProfile(name, country string) (Profile, error) {
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if name == "" {
+		// The production profile may be customised in the override file
+		if profile, ok := profiles[DefaultProfile(country).Name]; ok && profile.Country == country {
+			return profile, nil
+		}
+		return DefaultProfile(country), nil
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for candidate := range profiles {
+			names = append(names, candidate)
+		}
+		sort.Strings(names)
+		return Profile{}, errors.Configuration(fmt.Sprintf("unknown profile %q (available: %v)", name, names))
+	}
+	if profile.Country != country {
+		return Profile{}, errors.Configuration(fmt.Sprintf("profile %s is for %s, but this binary is built for %s", name, profile.Country, country))
+	}
+	return profile, nil
+}
+
+// DefaultProfile is the production profile of a country
+func DefaultProfile(country string) Profile {
+	return Profile{Name: country + "-prd", Country: country, Doorman: country}
+}
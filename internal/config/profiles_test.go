@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveProfile(t *testing.T) {
+	// No override file: only the embedded profiles apply
+	t.Setenv(ProfilesEnvVar, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	profile, err := ResolveProfile("", "my")
+	if err != nil || profile.Name != "my-prd" || profile.Doorman != "my" {
+		t.Errorf("expected the production profile by default, got %+v, %v", profile, err)
+	}
+
+	profile, err = ResolveProfile("sg-uat", "sg")
+	if err != nil || profile.Doorman != "sg-uat" || profile.Country != "sg" {
+		t.Errorf("unexpected sg-uat profile: %+v, %v", profile, err)
+	}
+
+	if _, err := ResolveProfile("sg-uat", "my"); err == nil || !strings.Contains(err.Error(), "built for my") {
+		t.Errorf("expected country mismatch error, got %v", err)
+	}
+	if _, err := ResolveProfile("nope", "my"); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("expected unknown profile error, got %v", err)
+	}
+
+	t.Setenv(ProfileEnvVar, "my-stg")
+	if profile, err := ResolveProfile("", "my"); err != nil || profile.Name != "my-stg" {
+		t.Errorf("expected %s to select my-stg, got %+v, %v", ProfileEnvVar, profile, err)
+	}
+}
+
+func TestLoadProfiles_Override(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	override := `profiles:
+  my-stg:
+    country: my
+    doorman: my-staging
+    jira_domain: https://gxbank-sandbox.atlassian.net
+  my-uat:
+    country: my
+`
+	if err := os.WriteFile(path, []byte(override), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ProfilesEnvVar, path)
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles: %v", err)
+	}
+	if stg := profiles["my-stg"]; stg.Doorman != "my-staging" || stg.JiraDomain == "" {
+		t.Errorf("expected my-stg to be overridden, got %+v", stg)
+	}
+	if uat := profiles["my-uat"]; uat.Name != "my-uat" || uat.Doorman != "my" {
+		t.Errorf("expected my-uat to default its Doorman environment to the country, got %+v", uat)
+	}
+	if _, ok := profiles["sg-prd"]; !ok {
+		t.Error("embedded profiles should be kept")
+	}
+
+	if err := os.WriteFile(path, []byte("profiles:\n  x:\n    country: id\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(); err == nil {
+		t.Error("expected an unsupported country to be rejected")
+	}
+}
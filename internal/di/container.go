@@ -8,6 +8,7 @@ import (
 	"buddy/internal/clients/doorman"
 	"buddy/internal/clients/jira"
 	"buddy/internal/config"
+	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/service"
 )
 
@@ -25,24 +26,48 @@ func NewContainer() *Container {
 	return &Container{}
 }
 
-// InitializeForEnvironment initializes all services for the given environment
+// InitializeForEnvironment initializes all services for the production profile
+// of the given environment
 func (c *Container) InitializeForEnvironment(env string) error {
+	return c.InitializeForProfile(config.DefaultProfile(env))
+}
+
+// InitializeForProfile initializes all services for a named profile. The
+// profile's country drives SOP rules and the transaction service; Doorman, Jira
+// and Datadog are pointed at the backends the profile names.
+func (c *Container) InitializeForProfile(profile config.Profile) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	env := profile.Country
+
 	// Initialize configuration loader first (configs are now embedded)
 	if err := config.InitializeConfigLoader(); err != nil {
 		return fmt.Errorf("failed to initialize configuration: %w", err)
 	}
 
+	// Apply user SOP rules override, if any, before the transaction service picks up SOPRepo
+	if err := adapters.LoadSOPRulesOverride(); err != nil {
+		return fmt.Errorf("failed to load SOP rules: %w", err)
+	}
+
+	// Apply user Doorman config override, if any, before the client picks its environment
+	if err := doorman.LoadConfigOverride(); err != nil {
+		return fmt.Errorf("failed to load Doorman config: %w", err)
+	}
+
 	// Initialize Doorman client
-	doormanClient := doorman.NewDoormanClient(env)
+	if !doorman.HasEnvironment(profile.Doorman) {
+		return fmt.Errorf("profile %s uses Doorman environment %s, which is not configured (add it to %s)", profile.Name, profile.Doorman, doormanOverrideHint())
+	}
+	doormanClient := doorman.NewDoormanClient(profile.Doorman)
 	if doormanClient == nil {
-		return fmt.Errorf("failed to initialize Doorman client for environment: %s", env)
+		return fmt.Errorf("failed to initialize Doorman client for environment: %s", profile.Doorman)
 	}
 	c.doormanClient = doormanClient
 
 	// Initialize Jira client
+	jira.SetSiteOverride(env, jira.JiraConfig{Domain: profile.JiraDomain, Project: profile.JiraProject})
 	jiraClient := jira.NewJiraClient(env)
 	if jiraClient == nil {
 		return fmt.Errorf("failed to initialize Jira client for environment: %s", env)
@@ -52,6 +77,9 @@ func (c *Container) InitializeForEnvironment(env string) error {
 
 	// Initialize Datadog client
 	datadogClient := datadog.NewDatadogClient(env)
+	if profile.DatadogURL != "" {
+		datadogClient = datadog.NewDatadogClientWithBaseURL(profile.DatadogURL)
+	}
 	if datadogClient == nil {
 		return fmt.Errorf("failed to initialize Datadog client for environment: %s", env)
 	}
@@ -67,6 +95,14 @@ func (c *Container) InitializeForEnvironment(env string) error {
 	return nil
 }
 
+// doormanOverrideHint names the Doorman config override file for error messages
+func doormanOverrideHint() string {
+	if path, _ := doorman.ConfigOverridePath(); path != "" {
+		return path
+	}
+	return "$" + doorman.ConfigEnvVar
+}
+
 // DoormanClient returns the Doorman client instance
 func (c *Container) DoormanClient() doorman.DoormanInterface {
 	c.mu.RLock()
@@ -0,0 +1,322 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"buddy/internal/errors"
+	"buddy/internal/txn/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decision is how an interactive SOP case is remediated
+type Decision string
+
+const (
+	DecisionAccept Decision = "accept" // resume the transaction to success
+	DecisionReject Decision = "reject" // manually reject the transaction
+)
+
+// decisionCases maps each decision of an interactive case to the case whose
+// template generates its SQL
+type decisionCases map[Decision]domain.Case
+
+// interactiveCases lists cases whose SQL depends on an operator decision. A
+// decision with no case generates no SQL.
+var interactiveCases = map[domain.Case]decisionCases{
+	domain.CaseCashoutRpp210Pe220Pc201: {
+		DecisionAccept: domain.CaseRpp210Pe220Pc201Accept,
+		DecisionReject: domain.CaseRpp210Pe220Pc201Reject,
+	},
+	// FAST never reported an outcome, so the reject SQL may only be raised once
+	// FAST operations confirm the payment was not settled. A settled payment has
+	// no SOP fix and is left to FAST operations.
+	domain.CaseFastCashoutPendingPe220Pc201: {
+		DecisionReject: domain.CaseFastCashoutPendingPe220Pc201,
+	},
+	domain.CaseFastCashinUnknownPe220: {
+		DecisionReject: domain.CaseFastCashinUnknownPe220,
+	},
+}
+
+// decisionNotes tells the operator what to confirm before deciding a case
+var decisionNotes = map[domain.Case]string{
+	domain.CaseFastCashoutPendingPe220Pc201: "FAST has no final status. Reject only once FAST operations confirm the payment was not settled; accepting generates no SQL.",
+	domain.CaseFastCashinUnknownPe220:       "FAST has no final status. Reject only once FAST operations confirm the funds were not credited; accepting generates no SQL.",
+}
+
+// NeedsDecision reports whether the SQL of caseType depends on an operator decision
+func NeedsDecision(caseType domain.Case) bool {
+	_, ok := interactiveCases[caseType]
+	return ok
+}
+
+// DecisionProvider supplies decisions for interactive SOP cases. A provider
+// serves a single SQL generation run, so "apply to all" answers never carry
+// over into the next batch.
+type DecisionProvider interface {
+	Decide(result domain.TransactionResult) (Decision, error)
+}
+
+// parseDecision accepts "accept" or "reject" in any case
+func parseDecision(value string) (Decision, error) {
+	switch d := Decision(strings.ToLower(strings.TrimSpace(value))); d {
+	case DecisionAccept, DecisionReject:
+		return d, nil
+	default:
+		return "", errors.Validation(fmt.Sprintf("invalid decision %q (must be accept or reject)", value))
+	}
+}
+
+// decisionMenu lists the choices offered for an interactive case
+const decisionMenu = `
+Choose an option:
+1. Resume to Success (Manual Success) - This once
+2. Reject/Fail (Manual Rejection) - This once
+3. Resume to Success (Manual Success) - Apply to all similar
+4. Reject/Fail (Manual Rejection) - Apply to all similar
+`
+
+// PromptDecisions asks the operator on stdin and remembers "apply to all" answers per case
+type PromptDecisions struct {
+	in         io.Reader
+	out        io.Writer
+	applyToAll map[domain.Case]Decision
+}
+
+// NewPromptDecisions creates a provider that prompts on stdin
+func NewPromptDecisions() *PromptDecisions {
+	return newPromptDecisions(os.Stdin, os.Stdout)
+}
+
+// NewPromptDecisionsTo creates a provider that reads answers from stdin and
+// writes its prompts to out, e.g. stderr when stdout carries structured output
+func NewPromptDecisionsTo(out io.Writer) *PromptDecisions {
+	return newPromptDecisions(os.Stdin, out)
+}
+
+func newPromptDecisions(in io.Reader, out io.Writer) *PromptDecisions {
+	return &PromptDecisions{
+		in:         in,
+		out:        out,
+		applyToAll: make(map[domain.Case]Decision),
+	}
+}
+
+// Decide prompts for the decision of result unless one was applied to all similar cases
+func (p *PromptDecisions) Decide(result domain.TransactionResult) (Decision, error) {
+	if decision, ok := p.applyToAll[result.CaseType]; ok {
+		return decision, nil
+	}
+
+	// Display visual divider and transaction summary
+	if _, err := fmt.Fprintf(p.out, "\n%s\n", strings.Repeat("=", 80)); err != nil {
+		return "", fmt.Errorf("failed to write prompt: %w", err)
+	}
+	WriteResult(p.out, result, result.Index)
+	if note, ok := decisionNotes[result.CaseType]; ok {
+		if _, err := fmt.Fprintf(p.out, "\nNote: %s\n", note); err != nil {
+			return "", fmt.Errorf("failed to write prompt: %w", err)
+		}
+	}
+	if _, err := fmt.Fprint(p.out, decisionMenu); err != nil {
+		return "", fmt.Errorf("failed to write prompt: %w", err)
+	}
+
+	for {
+		if _, err := fmt.Fprint(p.out, "\nEnter your choice (1, 2, 3, or 4): "); err != nil {
+			return "", fmt.Errorf("failed to write prompt: %w", err)
+		}
+		line, readErr := readLine(p.in)
+		choice := strings.TrimSpace(line)
+
+		switch choice {
+		case "1":
+			return DecisionAccept, nil
+		case "2":
+			return DecisionReject, nil
+		case "3":
+			p.applyToAll[result.CaseType] = DecisionAccept
+			return DecisionAccept, nil
+		case "4":
+			p.applyToAll[result.CaseType] = DecisionReject
+			return DecisionReject, nil
+		}
+
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read user choice: %w", readErr)
+		}
+		if _, err := fmt.Fprintf(p.out, "Invalid choice %q (must be 1-4)\n", choice); err != nil {
+			return "", fmt.Errorf("failed to write prompt: %w", err)
+		}
+	}
+}
+
+// readLine reads up to the next newline one byte at a time, so that nothing past
+// the answer is taken from stdin before later prompts read it
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
+
+// Decisions answers from preset decisions, first by transaction ID and then by
+// case. Anything else goes to Prompt; with a nil Prompt a missing decision is an
+// error, so batches can run in cron or CI without a TTY.
+type Decisions struct {
+	ByID   map[string]Decision
+	ByCase map[domain.Case]Decision
+	Prompt DecisionProvider
+}
+
+// NewDecisions creates an empty set of preset decisions. Unless nonInteractive,
+// missing decisions are prompted for on stdin.
+func NewDecisions(nonInteractive bool) *Decisions {
+	d := &Decisions{
+		ByID:   make(map[string]Decision),
+		ByCase: make(map[domain.Case]Decision),
+	}
+	if !nonInteractive {
+		d.Prompt = NewPromptDecisions()
+	}
+	return d
+}
+
+// Decide returns the preset decision for result, prompting if there is none
+func (d *Decisions) Decide(result domain.TransactionResult) (Decision, error) {
+	for _, id := range decisionIDs(result) {
+		if decision, ok := d.ByID[id]; ok {
+			return decision, nil
+		}
+	}
+	if decision, ok := d.ByCase[result.CaseType]; ok {
+		return decision, nil
+	}
+	if d.Prompt != nil {
+		return d.Prompt.Decide(result)
+	}
+	return "", errors.Validation(fmt.Sprintf("no decision for transaction %s (case %s); add it to the decisions file",
+		result.InputID, result.CaseType))
+}
+
+// decisionIDs returns the IDs a decisions file may use for result: the input ID,
+// the payment-engine transaction ID and the RPP end-to-end ID
+func decisionIDs(result domain.TransactionResult) []string {
+	ids := []string{result.InputID}
+	if result.PaymentEngine != nil && result.PaymentEngine.Transfers.TransactionID != "" {
+		ids = append(ids, result.PaymentEngine.Transfers.TransactionID)
+	}
+	if result.RPPAdapter != nil && result.RPPAdapter.EndToEndID != "" {
+		ids = append(ids, result.RPPAdapter.EndToEndID)
+	}
+	return ids
+}
+
+// decisionsFile is the layout of a YAML decisions file
+type decisionsFile struct {
+	Transactions map[string]string `yaml:"transactions"`
+	Cases        map[string]string `yaml:"cases"`
+}
+
+// LoadDecisionsFile adds the decisions in path to d. YAML files (.yaml, .yml)
+// map IDs under "transactions" and case types under "cases"; any other file is
+// read as CSV rows of "<transaction-id-or-case>,<accept|reject>".
+func (d *Decisions) LoadDecisionsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeConfiguration,
+			fmt.Sprintf("failed to read decisions file %s", path))
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = d.parseYAML(data)
+	default:
+		err = d.parseCSV(data)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeConfiguration,
+			fmt.Sprintf("invalid decisions file %s", path))
+	}
+	return nil
+}
+
+func (d *Decisions) parseYAML(data []byte) error {
+	var file decisionsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	for id, value := range file.Transactions {
+		if err := d.add(id, value, false); err != nil {
+			return err
+		}
+	}
+	for caseType, value := range file.Cases {
+		if err := d.add(caseType, value, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decisions) parseCSV(data []byte) error {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		if len(record) != 2 {
+			return fmt.Errorf("line %d: expected <transaction-id-or-case>,<decision>", i+1)
+		}
+		// Allow a header row
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[1]), "decision") {
+			continue
+		}
+		key := strings.TrimSpace(record[0])
+		_, isCase := interactiveCases[domain.Case(key)]
+		if err := d.add(key, record[1], isCase); err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// add records one decision, keyed by case type when isCase and by transaction ID otherwise
+func (d *Decisions) add(key, value string, isCase bool) error {
+	decision, err := parseDecision(value)
+	if err != nil {
+		return err
+	}
+
+	if !isCase {
+		d.ByID[key] = decision
+		return nil
+	}
+	if _, ok := interactiveCases[domain.Case(key)]; !ok {
+		return fmt.Errorf("case %s does not take a decision", key)
+	}
+	d.ByCase[domain.Case(key)] = decision
+	return nil
+}
@@ -0,0 +1,132 @@
+package adapters
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// interactiveResult returns a result matching the cashout_rpp210_pe220_pc201 rule
+func interactiveResult(t *testing.T, inputID string) domain.TransactionResult {
+	t.Helper()
+	for _, rule := range getDefaultSOPRules() {
+		if rule.CaseType == domain.CaseCashoutRpp210Pe220Pc201 {
+			result := resultMatchingRule(t, rule)
+			result.InputID = inputID
+			return result
+		}
+	}
+	t.Fatal("no rule for cashout_rpp210_pe220_pc201")
+	return domain.TransactionResult{}
+}
+
+func writeDecisionsFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestDecisions_LoadFile(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		d := NewDecisions(true)
+		require.NoError(t, d.LoadDecisionsFile(writeDecisionsFile(t, "decisions.yaml", `
+transactions:
+  input-1: Reject
+cases:
+  cashout_rpp210_pe220_pc201: accept
+`)))
+		assert.Equal(t, DecisionReject, d.ByID["input-1"])
+		assert.Equal(t, DecisionAccept, d.ByCase[domain.CaseCashoutRpp210Pe220Pc201])
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		d := NewDecisions(true)
+		require.NoError(t, d.LoadDecisionsFile(writeDecisionsFile(t, "decisions.csv",
+			"id,decision\n# comment\ninput-1, reject\ncashout_rpp210_pe220_pc201,accept\n")))
+		assert.Equal(t, DecisionReject, d.ByID["input-1"])
+		assert.Equal(t, DecisionAccept, d.ByCase[domain.CaseCashoutRpp210Pe220Pc201])
+	})
+
+	t.Run("invalid decision", func(t *testing.T) {
+		err := NewDecisions(true).LoadDecisionsFile(writeDecisionsFile(t, "decisions.csv", "input-1,maybe\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be accept or reject")
+	})
+
+	t.Run("case without a decision", func(t *testing.T) {
+		err := NewDecisions(true).LoadDecisionsFile(writeDecisionsFile(t, "decisions.yml",
+			"cases:\n  pe_transfer_payment_210_0: accept\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not take a decision")
+	})
+}
+
+func TestDecisions_Decide(t *testing.T) {
+	d := NewDecisions(true)
+	d.ByID["input-1"] = DecisionReject
+	d.ByCase[domain.CaseCashoutRpp210Pe220Pc201] = DecisionAccept
+
+	decision, err := d.Decide(interactiveResult(t, "input-1"))
+	require.NoError(t, err)
+	assert.Equal(t, DecisionReject, decision, "transaction IDs take precedence over cases")
+
+	decision, err = d.Decide(interactiveResult(t, "input-2"))
+	require.NoError(t, err)
+	assert.Equal(t, DecisionAccept, decision)
+
+	_, err = NewDecisions(true).Decide(interactiveResult(t, "input-3"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no decision for transaction input-3")
+}
+
+func TestGenerateSQLStatementsWithDecisions(t *testing.T) {
+	t.Run("uses the decided template", func(t *testing.T) {
+		d := NewDecisions(true)
+		d.ByID["input-1"] = DecisionReject
+
+		results := []domain.TransactionResult{interactiveResult(t, "input-1")}
+		statements, err := GenerateSQLStatementsWithDecisions(results, d)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, statements.PEDeployStatements)
+		assert.Contains(t, statements.PEDeployStatements[0], "-- rpp210_pe220_pc201_reject")
+	})
+
+	t.Run("missing decision fails before generating SQL", func(t *testing.T) {
+		results := []domain.TransactionResult{
+			populatedResult(domain.CasePeTransferPayment210_0),
+			interactiveResult(t, "input-1"),
+		}
+		statements, err := GenerateSQLStatementsWithDecisions(results, NewDecisions(true))
+		require.Error(t, err)
+		assert.Equal(t, domain.SQLStatements{}, statements)
+		assert.Contains(t, results[1].Error, "no decision")
+	})
+}
+
+func TestPromptDecisions_ApplyToAll(t *testing.T) {
+	var out bytes.Buffer
+	p := newPromptDecisions(strings.NewReader("7\n4\n"), &out)
+
+	decision, err := p.Decide(interactiveResult(t, "input-1"))
+	require.NoError(t, err)
+	assert.Equal(t, DecisionReject, decision)
+	assert.Contains(t, out.String(), `Invalid choice "7"`)
+
+	// The second transaction is answered without prompting
+	out.Reset()
+	decision, err = p.Decide(interactiveResult(t, "input-2"))
+	require.NoError(t, err)
+	assert.Equal(t, DecisionReject, decision)
+	assert.Empty(t, out.String())
+
+	_, err = newPromptDecisions(strings.NewReader(""), &out).Decide(interactiveResult(t, "input-3"))
+	assert.Error(t, err)
+}
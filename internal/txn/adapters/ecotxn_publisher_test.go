@@ -165,10 +165,26 @@ func (m *MockDoormanClient) QueryPartnerpayEngine(query string) ([]map[string]in
 	return []map[string]interface{}{}, nil
 }
 
+func (m *MockDoormanClient) QueryService(serviceName, query string) ([]map[string]interface{}, error) {
+	return m.ExecuteQuery("", "", "", query)
+}
+
 func (m *MockDoormanClient) CreateTicket(serviceName, originalQuery, rollbackQuery, note string) (string, error) {
 	return "mock-ticket-id", nil
 }
 
+func (m *MockDoormanClient) GetTicket(ticketID string) (*doorman.TicketResult, error) {
+	return &doorman.TicketResult{Status: doorman.TicketStatusPending}, nil
+}
+
+func (m *MockDoormanClient) ListTickets(filter doorman.TicketFilter) ([]doorman.TicketResult, error) {
+	return nil, nil
+}
+
+func (m *MockDoormanClient) TicketURL(ticketID string) string {
+	return "https://doorman.example/rds/dml/" + ticketID
+}
+
 func TestEcoTxn_ChargeUpdateSQLTimestampPreservation(t *testing.T) {
 	// Test that both deploy and rollback SQL preserve the updated_at field
 
@@ -0,0 +1,144 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fastCashoutResult(status string) *domain.TransactionResult {
+	return &domain.TransactionResult{
+		InputID: "fast-cashout-001",
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Workflow: domain.WorkflowInfo{WorkflowID: "workflow_transfer_payment", RunID: "pe-run-001", State: "220", Attempt: 0},
+		},
+		PaymentCore: &domain.PaymentCoreInfo{
+			ExternalTransfer: domain.PCExternalInfo{
+				Workflow: domain.WorkflowInfo{
+					WorkflowID: "external_payment_flow",
+					RunID:      "pc-run-001",
+					State:      "201",
+					Attempt:    0,
+					Data:       `{"State": 201, "StreamResp": {"Status": "PROCESSING"}}`,
+				},
+			},
+		},
+		FastAdapter: &domain.FastAdapterInfo{InstructionID: "20251017FASTSGS0001", Type: "cashout", Status: status},
+	}
+}
+
+func fastCashinResult(status string) *domain.TransactionResult {
+	return &domain.TransactionResult{
+		InputID: "fast-cashin-001",
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Workflow: domain.WorkflowInfo{WorkflowID: "workflow_transfer_collection", RunID: "pe-run-002", State: "220", Attempt: 0},
+		},
+		FastAdapter: &domain.FastAdapterInfo{InstructionID: "20251017FASTSGS0002", Type: "cashin", Status: status},
+	}
+}
+
+func TestFastCaseIdentification(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *domain.TransactionResult
+		want   domain.Case
+	}{
+		{"cashout pending", fastCashoutResult("StPending"), domain.CaseFastCashoutPendingPe220Pc201},
+		{"cashout erroneous", fastCashoutResult("StErraneous"), domain.CaseFastCashoutErroneousPe220Pc201},
+		{"cashout accepted", fastCashoutResult("StAccepted"), domain.CaseNone},
+		{"cashin auth unknown", fastCashinResult("StAuthUnknown"), domain.CaseFastCashinUnknownPe220},
+		{"cashin confirm unknown", fastCashinResult("StConfirmUnknown"), domain.CaseFastCashinUnknownPe220},
+		{"cashin erroneous", fastCashinResult("StErraneous"), domain.CaseFastCashinErroneousPe220},
+		{"cashin failed", fastCashinResult("FAILED"), domain.CasePe2200FastCashinFailed},
+	}
+
+	sopRepo := NewSOPRepository()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sopRepo.IdentifyCase(tt.result, "sg"))
+		})
+	}
+
+	t.Run("not matched in my", func(t *testing.T) {
+		assert.Equal(t, domain.CaseNone, sopRepo.IdentifyCase(fastCashoutResult("StPending"), "my"))
+		assert.Equal(t, domain.CaseNone, sopRepo.IdentifyCase(fastCashinResult("StErraneous"), "my"))
+	})
+}
+
+func TestFastTemplates(t *testing.T) {
+	t.Run("cashout rejects PC external transfer", func(t *testing.T) {
+		result := fastCashoutResult("StErraneous")
+		result.CaseType = domain.CaseFastCashoutErroneousPe220Pc201
+
+		statements := GenerateSQLStatements([]domain.TransactionResult{*result})
+		require.Len(t, statements.PCDeployStatements, 1)
+		require.Len(t, statements.PCRollbackStatements, 1)
+		assert.Empty(t, statements.PEDeployStatements)
+
+		deploy := statements.PCDeployStatements[0]
+		assert.Contains(t, deploy, "SET state = 202, attempt = 1")
+		assert.Contains(t, deploy, "'Status', 'FAILED'")
+		assert.Contains(t, deploy, "'pc-run-001'")
+		assert.Contains(t, deploy, "workflow_id = 'external_payment_flow' AND state = 201 AND attempt = 0")
+
+		rollback := statements.PCRollbackStatements[0]
+		assert.Contains(t, rollback, "SET state = 201, attempt = 0")
+		assert.True(t, strings.Contains(rollback, "PROCESSING"), "rollback should restore the original StreamResp: %s", rollback)
+	})
+
+	t.Run("cashin rejects PE collection", func(t *testing.T) {
+		result := fastCashinResult("StErraneous")
+		result.CaseType = domain.CaseFastCashinErroneousPe220
+
+		statements := GenerateSQLStatements([]domain.TransactionResult{*result})
+		require.Len(t, statements.PEDeployStatements, 1)
+		require.Len(t, statements.PERollbackStatements, 1)
+		assert.Empty(t, statements.PCDeployStatements)
+
+		deploy := statements.PEDeployStatements[0]
+		assert.Contains(t, deploy, "state = 221")
+		assert.Contains(t, deploy, "'MANUAL REJECT'")
+		assert.Contains(t, deploy, "'pe-run-002'")
+		assert.Contains(t, deploy, "AND state = 220")
+	})
+
+	t.Run("unknown outcomes need a decision", func(t *testing.T) {
+		pending := fastCashoutResult("StPending")
+		pending.InputID = "fast-pending"
+		pending.CaseType = domain.CaseFastCashoutPendingPe220Pc201
+		unknown := fastCashinResult("StAuthUnknown")
+		unknown.InputID = "fast-unknown"
+		unknown.CaseType = domain.CaseFastCashinUnknownPe220
+		results := []domain.TransactionResult{*pending, *unknown}
+
+		_, err := GenerateSQLStatementsWithDecisions(results, NewDecisions(true))
+		require.Error(t, err, "no reject SQL without a decision")
+
+		rejected := NewDecisions(true)
+		rejected.ByID["fast-pending"] = DecisionReject
+		rejected.ByID["fast-unknown"] = DecisionReject
+		statements, err := GenerateSQLStatementsWithDecisions(results, rejected)
+		require.NoError(t, err)
+		require.Len(t, statements.PCDeployStatements, 1)
+		assert.Contains(t, statements.PCDeployStatements[0], "-- fast_cashout_pending_pe220_pc201")
+		require.Len(t, statements.PEDeployStatements, 1)
+		assert.Contains(t, statements.PEDeployStatements[0], "-- fast_cashin_unknown_pe220")
+
+		accepted := NewDecisions(true)
+		accepted.ByCase[domain.CaseFastCashoutPendingPe220Pc201] = DecisionAccept
+		accepted.ByCase[domain.CaseFastCashinUnknownPe220] = DecisionAccept
+		statements, err = GenerateSQLStatementsWithDecisions(results, accepted)
+		require.NoError(t, err)
+		assert.Empty(t, statements.PCDeployStatements, "a settled payment has no SOP fix")
+		assert.Empty(t, statements.PEDeployStatements)
+	})
+
+	t.Run("missing workflow produces no ticket", func(t *testing.T) {
+		assert.Nil(t, fastCashoutPendingPe220Pc201(domain.TransactionResult{}))
+		assert.Nil(t, fastCashinErroneousPe220(domain.TransactionResult{}))
+	})
+}
@@ -0,0 +1,196 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+
+	"buddy/internal/txn/domain"
+)
+
+// FixExpectation is one acceptable outcome of running a case's deploy SQL. The
+// fix has landed when every condition of any of the case's expectations holds.
+type FixExpectation struct {
+	Description string
+	Conditions  []RuleCondition
+	// RPPWorkflowID limits RPPAdapter.Workflow conditions to workflows with this
+	// ID, so an unrelated workflow (e.g. wf_process_registry at 900) cannot
+	// satisfy them. Empty means any RPP workflow.
+	RPPWorkflowID string
+}
+
+// FixStatus is the outcome of re-checking a transaction after its DML ran
+type FixStatus string
+
+const (
+	FixLanded      FixStatus = "LANDED"      // the transaction reached an expected target state
+	FixStillStuck  FixStatus = "STILL_STUCK" // the transaction still matches the case that generated the SQL
+	FixMoved       FixStatus = "MOVED"       // the transaction now matches a different SOP case
+	FixUnconfirmed FixStatus = "UNCONFIRMED" // no longer stuck, but not in an expected target state either
+	FixError       FixStatus = "ERROR"       // the transaction could not be re-queried
+)
+
+// FixVerification reports whether the DML for one transaction took effect
+type FixVerification struct {
+	InputID      string
+	OriginalCase domain.Case
+	CurrentCase  domain.Case
+	Status       FixStatus
+	Expectation  string   // description of the expectation that was met
+	Observed     []string // "field=value" for every field the expectations look at
+	Detail       string
+}
+
+// Workflow states that show a deploy was applied and picked up by the workflow
+var (
+	peTransferRejectedStates   = []string{"221", "400", "501", "505", "510", "511", "512"}
+	peCaptureCompletedStates   = []string{"231", "900", "905", "910", "911", "912"}
+	peCollectionRejectedStates = []string{"221", "501", "505", "510"}
+	pcExternalRejectedStates   = []string{"202", "500", "501"}
+	pcExternalCompletedStates  = []string{"202", "900", "901"}
+	pcInternalRetriedStates    = []string{"0", "100", "101", "900", "901", "902"}
+	rppRejectedStates          = []string{"221", "311", "700"}
+	rppResumedStates           = []string{"222", "211", "301", "321", "900"}
+	rppCashinProgressedStates  = []string{"110", "121", "200", "201", "210", "220", "700", "701", "900", "901"}
+)
+
+// targetStates returns a condition that holds when field is one of states
+func targetStates(field string, states ...string) RuleCondition {
+	return RuleCondition{FieldPath: field, Operator: "in", Value: states}
+}
+
+// expectStates builds a single-expectation list for the common case
+func expectStates(description string, conditions ...RuleCondition) []FixExpectation {
+	return []FixExpectation{{Description: description, Conditions: conditions}}
+}
+
+// expectRPPStates builds a single expectation on the state of one RPP workflow
+func expectRPPStates(description, workflowID string, states ...string) []FixExpectation {
+	return []FixExpectation{{
+		Description:   description,
+		Conditions:    []RuleCondition{targetStates("RPPAdapter.Workflow.State", states...)},
+		RPPWorkflowID: workflowID,
+	}}
+}
+
+// HasFixExpectations reports whether target states are declared for caseType
+func HasFixExpectations(caseType domain.Case) bool {
+	_, ok := templateExpectations[caseType]
+	return ok
+}
+
+// VerifyFix compares a freshly queried result against the target states declared
+// for the case that generated its SQL
+func (r *SOPRepository) VerifyFix(originalCase domain.Case, result *domain.TransactionResult, env string) FixVerification {
+	verification := FixVerification{
+		InputID:      result.InputID,
+		OriginalCase: originalCase,
+		CurrentCase:  domain.CaseNone,
+	}
+
+	if result.Error != "" {
+		verification.Status = FixError
+		verification.Detail = result.Error
+		return verification
+	}
+	if result.QueryFailed() {
+		verification.Status = FixError
+		verification.Detail = DescribeQueryFailures(result.QueryFailures)
+		return verification
+	}
+
+	expectations, ok := templateExpectations[originalCase]
+	if !ok {
+		verification.Status = FixUnconfirmed
+		verification.Detail = fmt.Sprintf("no target states are declared for case %s", originalCase)
+		return verification
+	}
+
+	verification.Observed = r.observeFields(expectations, result)
+	verification.CurrentCase = r.IdentifyCase(result, env)
+
+	for _, expectation := range expectations {
+		if r.meetsAll(expectation.Conditions, scopeRPPWorkflows(result, expectation.RPPWorkflowID)) {
+			verification.Status = FixLanded
+			verification.Expectation = expectation.Description
+			return verification
+		}
+	}
+
+	switch verification.CurrentCase {
+	case originalCase:
+		verification.Status = FixStillStuck
+	case domain.CaseNone:
+		verification.Status = FixUnconfirmed
+		verification.Detail = "no longer matches any SOP case, but has not reached a target state"
+	default:
+		verification.Status = FixMoved
+		verification.Detail = fmt.Sprintf("now matches %s", verification.CurrentCase)
+	}
+	return verification
+}
+
+func (r *SOPRepository) meetsAll(conditions []RuleCondition, result *domain.TransactionResult) bool {
+	for _, condition := range conditions {
+		if !r.evaluateCondition(condition, result) {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeRPPWorkflows returns a shallow copy of result that only carries the RPP
+// workflows with workflowID
+func scopeRPPWorkflows(result *domain.TransactionResult, workflowID string) *domain.TransactionResult {
+	if workflowID == "" || result.RPPAdapter == nil {
+		return result
+	}
+
+	adapter := *result.RPPAdapter
+	adapter.Workflow = nil
+	for _, wf := range result.RPPAdapter.Workflow {
+		if wf.WorkflowID == workflowID {
+			adapter.Workflow = append(adapter.Workflow, wf)
+		}
+	}
+
+	scoped := *result
+	scoped.RPPAdapter = &adapter
+	return &scoped
+}
+
+// observeFields lists the current value of every field the expectations check
+func (r *SOPRepository) observeFields(expectations []FixExpectation, result *domain.TransactionResult) []string {
+	var observed []string
+	seen := make(map[string]bool)
+	for _, expectation := range expectations {
+		for _, condition := range expectation.Conditions {
+			if seen[condition.FieldPath] {
+				continue
+			}
+			seen[condition.FieldPath] = true
+
+			value, ok := r.getFieldValue(condition.FieldPath, result)
+			if !ok {
+				value = "-"
+			}
+			observed = append(observed, fmt.Sprintf("%s=%v", condition.FieldPath, value))
+		}
+	}
+	return observed
+}
+
+// String formats a verification as one report line
+func (v FixVerification) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-12s %s [%s]", v.Status, v.InputID, v.OriginalCase)
+	if v.Expectation != "" {
+		fmt.Fprintf(&b, " %s", v.Expectation)
+	}
+	if v.Detail != "" {
+		fmt.Fprintf(&b, " - %s", v.Detail)
+	}
+	if len(v.Observed) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(v.Observed, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	"reflect"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func peTransferResult(state string, attempt int) *domain.TransactionResult {
+	return &domain.TransactionResult{
+		InputID: "txn-1",
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Transfers: domain.PETransfersInfo{TransactionID: "txn-1", Status: "PROCESSING"},
+			Workflow:  domain.WorkflowInfo{WorkflowID: "workflow_transfer_payment", RunID: "run-1", State: state, Attempt: attempt},
+		},
+	}
+}
+
+func TestVerifyFix(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      *domain.TransactionResult
+		wantStatus  FixStatus
+		wantCurrent domain.Case
+	}{
+		{
+			name:       "fix landed",
+			result:     peTransferResult("221", 1),
+			wantStatus: FixLanded,
+		},
+		{
+			name:        "still stuck",
+			result:      peTransferResult("210", 0),
+			wantStatus:  FixStillStuck,
+			wantCurrent: domain.CasePeTransferPayment210_0,
+		},
+		{
+			name:        "moved to another case",
+			result:      peTransferResult("102", 0),
+			wantStatus:  FixMoved,
+			wantCurrent: domain.CasePeStuckAtLimitCheck102,
+		},
+		{
+			name:        "no case and no target state",
+			result:      peTransferResult("999", 0),
+			wantStatus:  FixUnconfirmed,
+			wantCurrent: domain.CaseNone,
+		},
+		{
+			name:       "query failed",
+			result:     &domain.TransactionResult{InputID: "txn-1", Error: "not found"},
+			wantStatus: FixError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := SOPRepo.VerifyFix(domain.CasePeTransferPayment210_0, tt.result, "my")
+			if verification.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s (%s)", tt.wantStatus, verification.Status, verification)
+			}
+			if tt.wantCurrent != "" && verification.CurrentCase != tt.wantCurrent {
+				t.Errorf("expected current case %s, got %s", tt.wantCurrent, verification.CurrentCase)
+			}
+		})
+	}
+}
+
+func TestVerifyFix_ScopesRPPWorkflow(t *testing.T) {
+	result := &domain.TransactionResult{
+		InputID: "txn-1",
+		RPPAdapter: &domain.RPPAdapterInfo{
+			Workflow: []domain.WorkflowInfo{
+				{WorkflowID: "wf_ct_cashout", RunID: "rpp-1", State: "101", Attempt: 19},
+				{WorkflowID: "wf_ct_qr_payment", RunID: "rpp-2", State: "700"},
+			},
+		},
+	}
+
+	verification := SOPRepo.VerifyFix(domain.CaseRppCashoutReject101_19, result, "my")
+	if verification.Status == FixLanded {
+		t.Fatalf("a different RPP workflow in a target state must not count as landed: %s", verification)
+	}
+
+	result.RPPAdapter.Workflow[0].State = "700"
+	verification = SOPRepo.VerifyFix(domain.CaseRppCashoutReject101_19, result, "my")
+	if verification.Status != FixLanded {
+		t.Errorf("expected LANDED once wf_ct_cashout is rejected, got %s", verification)
+	}
+}
+
+func TestTemplateExpectations(t *testing.T) {
+	resultType := reflect.TypeOf(domain.TransactionResult{})
+
+	for caseType := range sqlTemplates {
+		expectations, ok := templateExpectations[caseType]
+		if !ok || len(expectations) == 0 {
+			t.Errorf("%s: no target states declared next to its SQL template", caseType)
+			continue
+		}
+		for _, expectation := range expectations {
+			if expectation.Description == "" || len(expectation.Conditions) == 0 {
+				t.Errorf("%s: expectation %q has no description or conditions", caseType, expectation.Description)
+			}
+			for _, condition := range expectation.Conditions {
+				if !isValidFieldPath(resultType, condition.FieldPath) {
+					t.Errorf("%s: invalid field path %s", caseType, condition.FieldPath)
+				}
+			}
+		}
+	}
+
+	for caseType := range templateExpectations {
+		if _, ok := sqlTemplates[caseType]; !ok {
+			t.Errorf("%s: target states declared without an SQL template", caseType)
+		}
+	}
+}
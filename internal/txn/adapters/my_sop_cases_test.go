@@ -0,0 +1,296 @@
+package adapters
+
+import (
+	"buddy/internal/txn/domain"
+	"testing"
+)
+
+// cashoutStuckResult returns PE 220 and PC 201 with the given RPP workflow
+func cashoutStuckResult(peAttempt, pcAttempt int, rppWorkflow domain.WorkflowInfo) *domain.TransactionResult {
+	return &domain.TransactionResult{
+		InputID:  "20260110GXSPMYKL010ORB00020001",
+		CaseType: domain.CaseNone,
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Transfers: domain.PETransfersInfo{
+				Type:        "PAYMENT",
+				TxnSubtype:  "RPP_NETWORK",
+				Status:      "PROCESSING",
+				ReferenceID: "8b1f0c2a9d3e4f5a8b7c6d5e4f3a2b1c",
+			},
+			Workflow: domain.WorkflowInfo{
+				WorkflowID: "workflow_transfer_payment",
+				State:      "220",
+				Attempt:    peAttempt,
+				RunID:      "8b1f0c2a9d3e4f5a8b7c6d5e4f3a2b1c",
+			},
+		},
+		PaymentCore: &domain.PaymentCoreInfo{
+			ExternalTransfer: domain.PCExternalInfo{
+				RefID:    "c4d5e6f708194a2b9c3d4e5f60718293",
+				TxType:   "TRANSFER",
+				TxStatus: "PROCESSING",
+				Workflow: domain.WorkflowInfo{
+					WorkflowID: "external_payment_flow",
+					State:      "201",
+					Attempt:    pcAttempt,
+					RunID:      "c4d5e6f708194a2b9c3d4e5f60718293",
+				},
+			},
+		},
+		RPPAdapter: &domain.RPPAdapterInfo{
+			Workflow: []domain.WorkflowInfo{rppWorkflow},
+		},
+	}
+}
+
+// TestRppNoResponseRejectNotFoundState0_CaseIdentification tests the case identification
+// for RPP wf_ct_qr_payment stuck at stInit while PE and PC are retrying
+func TestRppNoResponseRejectNotFoundState0_CaseIdentification(t *testing.T) {
+	rpp := domain.WorkflowInfo{WorkflowID: "wf_ct_qr_payment", State: "0", Attempt: 7, RunID: "rpp-qr-run-001"}
+	sopRepo := NewSOPRepository()
+
+	result := sopRepo.IdentifyCase(cashoutStuckResult(0, 3, rpp), "my")
+	if result != domain.CaseRppNoResponseRejectNotFoundState0 {
+		t.Errorf("Expected case %s, got %s", domain.CaseRppNoResponseRejectNotFoundState0, result)
+	}
+
+	// PE 220/0 and PC 201/0 keep going to the PC rejection of pe220_pc201_rpp0_stuck_init
+	result = sopRepo.IdentifyCase(cashoutStuckResult(0, 0, rpp), "my")
+	if result != domain.CasePe220Pc201Rpp0StuckInit {
+		t.Errorf("Expected case %s, got %s", domain.CasePe220Pc201Rpp0StuckInit, result)
+	}
+}
+
+// TestRppNoResponseRejectNotFoundState0_SQLGeneration tests SQL generation for the case
+func TestRppNoResponseRejectNotFoundState0_SQLGeneration(t *testing.T) {
+	transactionResult := cashoutStuckResult(0, 3, domain.WorkflowInfo{WorkflowID: "wf_ct_qr_payment", State: "0", Attempt: 7, RunID: "rpp-qr-run-001"})
+	transactionResult.CaseType = domain.CaseRppNoResponseRejectNotFoundState0
+
+	statements := GenerateSQLStatements([]domain.TransactionResult{*transactionResult})
+	if len(statements.RPPDeployStatements) == 0 || len(statements.RPPRollbackStatements) == 0 {
+		t.Fatal("Expected RPP deploy and rollback statements")
+	}
+	if len(statements.PCDeployStatements) != 0 {
+		t.Errorf("Expected no PC statements, got %v", statements.PCDeployStatements)
+	}
+
+	deploySQL := statements.RPPDeployStatements[0]
+	for _, element := range []string{
+		"SET state = 221",
+		"attempt = 1",
+		"run_id IN ('rpp-qr-run-001')",
+		"AND state = 0",
+		"AND workflow_id = 'wf_ct_qr_payment'",
+	} {
+		if !containsString(deploySQL, element) {
+			t.Errorf("Expected SQL to contain '%s', but got: %s", element, deploySQL)
+		}
+	}
+
+	// The rollback restores the attempt the workflow had
+	rollbackSQL := statements.RPPRollbackStatements[0]
+	for _, element := range []string{"SET state = 0", "attempt = 7", "run_id IN ('rpp-qr-run-001')"} {
+		if !containsString(rollbackSQL, element) {
+			t.Errorf("Expected rollback SQL to contain '%s', but got: %s", element, rollbackSQL)
+		}
+	}
+}
+
+// TestRppAdapterPublishFailure311_CaseIdentification tests the case identification for
+// RPP wf_ct_cashout that reached a publish state but never published
+func TestRppAdapterPublishFailure311_CaseIdentification(t *testing.T) {
+	sopRepo := NewSOPRepository()
+
+	for _, state := range []string{"301", "311"} {
+		rpp := domain.WorkflowInfo{WorkflowID: "wf_ct_cashout", State: state, Attempt: 0, RunID: "rpp-cashout-run-001"}
+		result := sopRepo.IdentifyCase(cashoutStuckResult(0, 0, rpp), "my")
+		if result != domain.CaseRppAdapterPublishFailure311 {
+			t.Errorf("RPP %s: expected case %s, got %s", state, domain.CaseRppAdapterPublishFailure311, result)
+		}
+	}
+
+	// A QR payment at 311 is not covered by this case
+	rpp := domain.WorkflowInfo{WorkflowID: "wf_ct_qr_payment", State: "311", Attempt: 0, RunID: "rpp-qr-run-002"}
+	if result := sopRepo.IdentifyCase(cashoutStuckResult(0, 0, rpp), "my"); result == domain.CaseRppAdapterPublishFailure311 {
+		t.Errorf("Should not match wf_ct_qr_payment")
+	}
+}
+
+// TestRppAdapterPublishFailure311_SQLGeneration tests that the publish is resumed from 311
+// as documented in the SOP, and that the rollback restores the original state
+func TestRppAdapterPublishFailure311_SQLGeneration(t *testing.T) {
+	for _, state := range []string{"301", "311"} {
+		transactionResult := cashoutStuckResult(0, 0, domain.WorkflowInfo{WorkflowID: "wf_ct_cashout", State: state, Attempt: 0, RunID: "rpp-cashout-run-001"})
+		transactionResult.CaseType = domain.CaseRppAdapterPublishFailure311
+
+		statements := GenerateSQLStatements([]domain.TransactionResult{*transactionResult})
+		if len(statements.RPPDeployStatements) == 0 || len(statements.RPPRollbackStatements) == 0 {
+			t.Fatalf("RPP %s: expected RPP deploy and rollback statements", state)
+		}
+
+		deploySQL := statements.RPPDeployStatements[0]
+		for _, element := range []string{
+			"SET state = 311",
+			"attempt = 1",
+			"'$.State', 311",
+			"run_id IN ('rpp-cashout-run-001')",
+			"AND state IN (301, 311)",
+			"AND workflow_id = 'wf_ct_cashout'",
+		} {
+			if !containsString(deploySQL, element) {
+				t.Errorf("RPP %s: expected SQL to contain '%s', but got: %s", state, element, deploySQL)
+			}
+		}
+
+		rollbackSQL := statements.RPPRollbackStatements[0]
+		for _, element := range []string{"SET state = " + state, "attempt = 0", "'$.State', " + state} {
+			if !containsString(rollbackSQL, element) {
+				t.Errorf("RPP %s: expected rollback SQL to contain '%s', but got: %s", state, element, rollbackSQL)
+			}
+		}
+	}
+}
+
+// TestPeStuck223HystrixTimeout_CaseIdentification tests the case identification for PE
+// stuck at 220 or 223 after a Hystrix timeout
+func TestPeStuck223HystrixTimeout_CaseIdentification(t *testing.T) {
+	sopRepo := NewSOPRepository()
+
+	for _, state := range []string{"220", "223"} {
+		transactionResult := &domain.TransactionResult{
+			InputID: "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+			PaymentEngine: &domain.PaymentEngineInfo{
+				Workflow: domain.WorkflowInfo{
+					WorkflowID: "workflow_transfer_payment",
+					State:      state,
+					Attempt:    0,
+					RunID:      "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+					Data:       `{"State": ` + state + `, "StreamMessage": {"Status": "FAILED", "ErrorMessage": "hystrix: timeout"}}`,
+				},
+			},
+		}
+
+		result := sopRepo.IdentifyCase(transactionResult, "my")
+		if result != domain.CasePeStuck223HystrixTimeout {
+			t.Errorf("PE %s: expected case %s, got %s", state, domain.CasePeStuck223HystrixTimeout, result)
+		}
+	}
+
+	// Without a Hystrix error the PE state alone does not match
+	transactionResult := &domain.TransactionResult{
+		InputID: "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Workflow: domain.WorkflowInfo{
+				WorkflowID: "workflow_transfer_payment",
+				State:      "223",
+				RunID:      "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+				Data:       `{"State": 223}`,
+			},
+		},
+	}
+	if result := sopRepo.IdentifyCase(transactionResult, "my"); result == domain.CasePeStuck223HystrixTimeout {
+		t.Errorf("Should not match without a Hystrix timeout in the workflow data")
+	}
+
+	// Once the transfer reached RPP the RPP outcome decides, not the PE timeout
+	transactionResult.PaymentEngine.Workflow.State = "220"
+	transactionResult.PaymentEngine.Workflow.Data = `{"State": 220, "StreamMessage": {"ErrorMessage": "hystrix: timeout"}}`
+	transactionResult.PaymentCore = &domain.PaymentCoreInfo{
+		ExternalTransfer: domain.PCExternalInfo{
+			Workflow: domain.WorkflowInfo{WorkflowID: "external_payment_flow", State: "201", RunID: "pc-run-1"},
+		},
+	}
+	transactionResult.RPPAdapter = &domain.RPPAdapterInfo{
+		Status:   "PROCESSING",
+		Workflow: []domain.WorkflowInfo{{WorkflowID: "wf_ct_qr_payment", State: "210", RunID: "rpp-run-1"}},
+	}
+	if result := sopRepo.IdentifyCase(transactionResult, "my"); result != domain.CaseCashoutRpp210Pe220Pc201 {
+		t.Errorf("Expected case %s once RPP is involved, got %s", domain.CaseCashoutRpp210Pe220Pc201, result)
+	}
+}
+
+// TestPeStuck223HystrixTimeout_SQLGeneration tests SQL generation for the case
+func TestPeStuck223HystrixTimeout_SQLGeneration(t *testing.T) {
+	transactionResult := domain.TransactionResult{
+		InputID:  "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+		CaseType: domain.CasePeStuck223HystrixTimeout,
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Workflow: domain.WorkflowInfo{
+				WorkflowID: "workflow_transfer_payment",
+				State:      "223",
+				Attempt:    2,
+				RunID:      "5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b",
+				Data:       `{"State": 223, "StreamMessage": {"Status": "FAILED", "ErrorMessage": "hystrix: timeout"}}`,
+			},
+		},
+	}
+
+	statements := GenerateSQLStatements([]domain.TransactionResult{transactionResult})
+	if len(statements.PEDeployStatements) == 0 || len(statements.PERollbackStatements) == 0 {
+		t.Fatal("Expected PE deploy and rollback statements")
+	}
+
+	deploySQL := statements.PEDeployStatements[0]
+	for _, element := range []string{
+		"SET state = 221",
+		"attempt = 1",
+		"'$.StreamMessage', JSON_OBJECT()",
+		"run_id IN ('5e6f7a8b9c0d4e1f8a2b3c4d5e6f7a8b')",
+		"AND state IN (220, 223)",
+	} {
+		if !containsString(deploySQL, element) {
+			t.Errorf("Expected SQL to contain '%s', but got: %s", element, deploySQL)
+		}
+	}
+
+	rollbackSQL := statements.PERollbackStatements[0]
+	for _, element := range []string{"SET state = 223", "attempt = 2", "'$.State', 223", "hystrix: timeout"} {
+		if !containsString(rollbackSQL, element) {
+			t.Errorf("Expected rollback SQL to contain '%s', but got: %s", element, rollbackSQL)
+		}
+	}
+}
+
+// TestUserNameChangeQrInvalidation_SQLGeneration tests that the QR invalidation is pinned to
+// the rows that were ACTIVE when the ticket was generated
+func TestUserNameChangeQrInvalidation_SQLGeneration(t *testing.T) {
+	if ticket := GetDMLTicketForUserNameChangeQrInvalidation("user-001", nil); ticket != nil {
+		t.Fatal("Expected no ticket without an ACTIVE QR code")
+	}
+
+	ticket := GetDMLTicketForUserNameChangeQrInvalidation("user-001", []string{"2025-06-01T10:00:00Z"})
+	if ticket == nil {
+		t.Fatal("Expected a ticket")
+	}
+	if ticket.CaseType != domain.CaseUserNameChangeQrInvalidation {
+		t.Errorf("Expected case %s, got %s", domain.CaseUserNameChangeQrInvalidation, ticket.CaseType)
+	}
+
+	statements, err := GenerateSQLFromTicket(*ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySQLStatements(statements); err != nil {
+		t.Fatalf("Generated SQL does not verify: %v", err)
+	}
+
+	deploySQL := statements.RPPDeployStatements[0]
+	for _, element := range []string{
+		"UPDATE qr_code",
+		"SET status = 'INACTIVE'",
+		"WHERE user_id = 'user-001'",
+		"AND status = 'ACTIVE'",
+		"AND created_at IN ('2025-06-01T10:00:00Z')",
+	} {
+		if !containsString(deploySQL, element) {
+			t.Errorf("Expected SQL to contain '%s', but got: %s", element, deploySQL)
+		}
+	}
+
+	rollbackSQL := statements.RPPRollbackStatements[0]
+	for _, element := range []string{"SET status = 'ACTIVE'", "AND status = 'INACTIVE'", "AND created_at IN ('2025-06-01T10:00:00Z')"} {
+		if !containsString(rollbackSQL, element) {
+			t.Errorf("Expected rollback SQL to contain '%s', but got: %s", element, rollbackSQL)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 // WriteBatchResults writes transaction results to an output file in the new format
@@ -35,6 +36,24 @@ func WriteResult(w io.Writer, result domain.TransactionResult, index int) {
 	writeResult(w, result, index)
 }
 
+// describeQueryFailure formats one failed system, e.g.
+// "payment-core: query failed after 3 attempts: 503 Service Unavailable"
+func describeQueryFailure(failure domain.QueryFailure) string {
+	if failure.Attempts > 1 {
+		return fmt.Sprintf("%s: query failed after %d attempts: %s", failure.System, failure.Attempts, failure.Error)
+	}
+	return fmt.Sprintf("%s: query failed: %s", failure.System, failure.Error)
+}
+
+// DescribeQueryFailures joins the failed systems of a result into one line
+func DescribeQueryFailures(failures []domain.QueryFailure) string {
+	descriptions := make([]string, len(failures))
+	for i, failure := range failures {
+		descriptions[i] = describeQueryFailure(failure)
+	}
+	return strings.Join(descriptions, "; ")
+}
+
 // Helper function to display Classification section
 func displayClassificationSection(w io.Writer, result domain.TransactionResult) error {
 	// Always write section header
@@ -42,16 +61,32 @@ func displayClassificationSection(w io.Writer, result domain.TransactionResult)
 		return err
 	}
 
-	// Show NOT_FOUND for empty case types
+	// Show NOT_FOUND for empty case types, or QUERY_FAILED when data is missing
+	// because a system could not be queried
 	caseType := result.CaseType
 	if caseType == "" || caseType == domain.CaseNone {
 		caseType = "NOT_FOUND"
+		if result.QueryFailed() {
+			caseType = domain.QueryFailedStatus
+		}
 	}
 
 	if _, err := fmt.Fprintf(w, "%s\n", caseType); err != nil {
 		fmt.Printf("Warning: failed to write case type: %v\n", err)
 	}
 
+	for _, failure := range result.QueryFailures {
+		if _, err := fmt.Fprintf(w, "%s\n", describeQueryFailure(failure)); err != nil {
+			fmt.Printf("Warning: failed to write query failure: %v\n", err)
+		}
+	}
+
+	if result.RecoveredRetries > 0 {
+		if _, err := fmt.Fprintf(w, "queries succeeded after %d retries\n", result.RecoveredRetries); err != nil {
+			fmt.Printf("Warning: failed to write query retries: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
@@ -68,7 +103,11 @@ func writeResult(w io.Writer, result domain.TransactionResult, index int) {
 		if _, err := fmt.Fprintln(w, "[rpp-adapter]"); err != nil {
 			fmt.Printf("Warning: failed to write rpp-adapter header: %v\n", err)
 		}
-		if _, err := fmt.Fprintln(w, "NOT FOUND"); err != nil {
+		status := "NOT FOUND"
+		if result.QueryFailed() {
+			status = "QUERY FAILED: " + DescribeQueryFailures(result.QueryFailures)
+		}
+		if _, err := fmt.Fprintln(w, status); err != nil {
 			fmt.Printf("Warning: failed to write NOT FOUND: %v\n", err)
 		}
 		if _, err := fmt.Fprintln(w); err != nil {
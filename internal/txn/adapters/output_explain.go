@@ -0,0 +1,179 @@
+package adapters
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"buddy/internal/txn/domain"
+)
+
+// WriteRuleExplanation writes the identified case followed by the top near-miss
+// rules and the outcome of each of their conditions.
+func WriteRuleExplanation(w io.Writer, result domain.TransactionResult, traces []RuleTrace, top int) {
+	caseType := result.CaseType
+	if caseType == "" {
+		caseType = domain.CaseNone
+	}
+
+	if _, err := fmt.Fprintf(w, "### SOP explain: %s\n", result.InputID); err != nil {
+		fmt.Printf("Warning: failed to write explain header: %v\n", err)
+	}
+	if _, err := fmt.Fprintf(w, "identified case: %s\n", caseType); err != nil {
+		fmt.Printf("Warning: failed to write identified case: %v\n", err)
+	}
+
+	skipped := 0
+	for _, trace := range traces {
+		if trace.Skipped {
+			skipped++
+		}
+	}
+	if _, err := fmt.Fprintf(w, "rules evaluated: %d (%d skipped for other countries)\n", len(traces)-skipped, skipped); err != nil {
+		fmt.Printf("Warning: failed to write rule count: %v\n", err)
+	}
+
+	for i, trace := range RankNearMisses(traces, top) {
+		writeRuleTrace(w, trace, i+1)
+	}
+}
+
+func writeRuleTrace(w io.Writer, trace RuleTrace, rank int) {
+	status := "near miss"
+	if trace.Matched() {
+		status = "MATCH"
+	}
+
+	if _, err := fmt.Fprintf(w, "\n[%d] %s - %d/%d conditions (%s)\n",
+		rank, trace.Rule.CaseType, trace.Satisfied, len(trace.Conditions), status); err != nil {
+		fmt.Printf("Warning: failed to write rule trace: %v\n", err)
+	}
+	if trace.Rule.Description != "" {
+		if _, err := fmt.Fprintf(w, "    %s\n", trace.Rule.Description); err != nil {
+			fmt.Printf("Warning: failed to write rule description: %v\n", err)
+		}
+	}
+
+	for _, ct := range trace.Conditions {
+		mark := "✗"
+		if ct.Passed {
+			mark = "✓"
+		}
+
+		actual := formatTraceValue(ct.Value)
+		if !ct.Found {
+			actual = "<unreachable>"
+		}
+
+		if _, err := fmt.Fprintf(w, "  %s %s %s %s (actual: %s)\n",
+			mark, ct.Condition.FieldPath, ct.Condition.Operator, formatTraceValue(ct.Condition.Value), actual); err != nil {
+			fmt.Printf("Warning: failed to write condition trace: %v\n", err)
+		}
+	}
+}
+
+// formatTraceValue renders condition and field values compactly: strings are
+// quoted and slices are printed element by element.
+func formatTraceValue(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts[i] = formatTraceValue(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// WriteCaseCoverage writes every case with its rules, country gating and whether
+// a template is registered. It returns the number of cases missing a rule or template.
+func WriteCaseCoverage(w io.Writer, env string, coverage []CaseCoverage) int {
+	incomplete := 0
+	for _, c := range coverage {
+		if len(c.Missing()) > 0 {
+			incomplete++
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "### SOP cases (%s): %d cases, %d missing a rule or template\n",
+		env, len(coverage), incomplete); err != nil {
+		fmt.Printf("Warning: failed to write case list header: %v\n", err)
+	}
+
+	for i, c := range coverage {
+		writeCaseCoverage(w, c, i+1)
+	}
+	return incomplete
+}
+
+func writeCaseCoverage(w io.Writer, c CaseCoverage, index int) {
+	if _, err := fmt.Fprintf(w, "\n[%d] %s\n    template: %s, interactive: %s\n",
+		index, c.Case, yesNo(c.HasTemplate), yesNo(c.Interactive)); err != nil {
+		fmt.Printf("Warning: failed to write case: %v\n", err)
+	}
+
+	if c.IdentifiedInCode != "" {
+		if _, err := fmt.Fprintf(w, "    identified in code (country: %s)\n", c.IdentifiedInCode); err != nil {
+			fmt.Printf("Warning: failed to write case identification: %v\n", err)
+		}
+	}
+
+	if len(c.DecidedFrom) > 0 {
+		if _, err := fmt.Fprintf(w, "    decided from: %s\n", joinCases(c.DecidedFrom)); err != nil {
+			fmt.Printf("Warning: failed to write decision sources: %v\n", err)
+		}
+	}
+	if c.RaisedBy != "" {
+		if _, err := fmt.Fprintf(w, "    raised by: %s\n", c.RaisedBy); err != nil {
+			fmt.Printf("Warning: failed to write how the case is raised: %v\n", err)
+		}
+	}
+
+	for _, rule := range c.Rules {
+		country := rule.Country
+		if country == "" {
+			country = "all"
+		}
+		if _, err := fmt.Fprintf(w, "    rule (country: %s): %s\n", country, rule.Description); err != nil {
+			fmt.Printf("Warning: failed to write rule: %v\n", err)
+		}
+		for _, cond := range rule.Conditions {
+			if _, err := fmt.Fprintf(w, "      %s %s %s\n",
+				cond.FieldPath, cond.Operator, formatTraceValue(cond.Value)); err != nil {
+				fmt.Printf("Warning: failed to write condition: %v\n", err)
+			}
+		}
+	}
+
+	if missing := c.Missing(); len(missing) > 0 {
+		if _, err := fmt.Fprintf(w, "    MISSING: %s\n", strings.Join(missing, ", ")); err != nil {
+			fmt.Printf("Warning: failed to write missing parts: %v\n", err)
+		}
+	}
+}
+
+func joinCases(cases []domain.Case) string {
+	names := make([]string, len(cases))
+	for i, caseType := range cases {
+		names[i] = string(caseType)
+	}
+	return strings.Join(names, ", ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,435 @@
+package adapters
+
+import (
+	"buddy/internal/txn/domain"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ResultSchemaVersion is the version of the JSON/NDJSON result schema. It is
+// bumped whenever a field is removed or changes meaning; new fields may be
+// added without a bump.
+const ResultSchemaVersion = 1
+
+// OutputFormat selects how transaction results are rendered
+type OutputFormat string
+
+const (
+	OutputText   OutputFormat = "text"   // human-readable sections (default)
+	OutputJSON   OutputFormat = "json"   // one indented JSON document
+	OutputNDJSON OutputFormat = "ndjson" // one JSON object per line
+)
+
+// ParseOutputFormat validates an --output flag value
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "", OutputText:
+		return OutputText, nil
+	case OutputJSON, OutputNDJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (expected text, json or ndjson)", value)
+	}
+}
+
+// IsStructured reports whether the format is machine-readable
+func (f OutputFormat) IsStructured() bool {
+	return f == OutputJSON || f == OutputNDJSON
+}
+
+// ResultDocument is the top-level JSON output
+type ResultDocument struct {
+	SchemaVersion int            `json:"schema_version"`
+	Results       []ResultRecord `json:"results"`
+	SQL           *SQLRecord     `json:"sql,omitempty"`
+}
+
+// ResultRecord is the serialized form of a domain.TransactionResult
+type ResultRecord struct {
+	SchemaVersion    int                     `json:"schema_version,omitempty"` // set on NDJSON lines only
+	Type             string                  `json:"type,omitempty"`           // "result" on NDJSON lines only
+	Index            int                     `json:"index"`
+	InputID          string                  `json:"input_id"`
+	Case             string                  `json:"case"`
+	Error            string                  `json:"error,omitempty"`
+	QueryFailures    []QueryFailureRecord    `json:"query_failures,omitempty"`
+	QueryRetries     int                     `json:"query_retries,omitempty"` // extra attempts, failed or not
+	PaymentEngine    *PaymentEngineRecord    `json:"payment_engine,omitempty"`
+	PaymentCore      *PaymentCoreRecord      `json:"payment_core,omitempty"`
+	FastAdapter      *FastAdapterRecord      `json:"fast_adapter,omitempty"`
+	RPPAdapter       *RPPAdapterRecord       `json:"rpp_adapter,omitempty"`
+	PartnerpayEngine *PartnerpayEngineRecord `json:"partnerpay_engine,omitempty"`
+}
+
+// QueryFailureRecord is a system that could not be queried; Attempts counts retries
+type QueryFailureRecord struct {
+	System   string `json:"system"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// WorkflowRecord is a workflow_execution row; StateName comes from FormatWorkflowState
+type WorkflowRecord struct {
+	WorkflowID  string `json:"workflow_id"`
+	RunID       string `json:"run_id"`
+	State       string `json:"state"`
+	StateName   string `json:"state_name"`
+	Attempt     int    `json:"attempt"`
+	PrevTransID string `json:"prev_trans_id,omitempty"`
+	Data        string `json:"data,omitempty"`
+}
+
+// PaymentEngineRecord is the payment-engine section
+type PaymentEngineRecord struct {
+	Transfers PETransfersRecord `json:"transfers"`
+	Workflow  *WorkflowRecord   `json:"workflow,omitempty"`
+}
+
+// PETransfersRecord is a payment-engine transfers row
+type PETransfersRecord struct {
+	Type                 string  `json:"type,omitempty"`
+	TxnSubtype           string  `json:"txn_subtype,omitempty"`
+	TxnDomain            string  `json:"txn_domain,omitempty"`
+	TransactionID        string  `json:"transaction_id,omitempty"`
+	ReferenceID          string  `json:"reference_id,omitempty"`
+	Status               string  `json:"status,omitempty"`
+	ExternalID           string  `json:"external_id,omitempty"`
+	SourceAccountID      string  `json:"source_account_id,omitempty"`
+	DestinationAccountID string  `json:"destination_account_id,omitempty"`
+	Amount               float64 `json:"amount,omitempty"`
+	CreatedAt            string  `json:"created_at,omitempty"`
+	UpdatedAt            string  `json:"updated_at,omitempty"`
+	Properties           string  `json:"properties,omitempty"`
+}
+
+// PaymentCoreRecord is the payment-core section; empty transactions are omitted
+type PaymentCoreRecord struct {
+	InternalAuth     *PCInternalRecord `json:"internal_auth,omitempty"`
+	InternalCapture  *PCInternalRecord `json:"internal_capture,omitempty"`
+	ExternalTransfer *PCExternalRecord `json:"external_transfer,omitempty"`
+}
+
+// PCInternalRecord is a payment-core internal_transaction row
+type PCInternalRecord struct {
+	TxID      string          `json:"tx_id,omitempty"`
+	GroupID   string          `json:"group_id,omitempty"`
+	TxType    string          `json:"tx_type,omitempty"`
+	TxStatus  string          `json:"tx_status,omitempty"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	ErrorMsg  string          `json:"error_msg,omitempty"`
+	CreatedAt string          `json:"created_at,omitempty"`
+	Workflow  *WorkflowRecord `json:"workflow,omitempty"`
+}
+
+// PCExternalRecord is a payment-core external_transaction row
+type PCExternalRecord struct {
+	RefID     string          `json:"ref_id,omitempty"`
+	GroupID   string          `json:"group_id,omitempty"`
+	TxType    string          `json:"tx_type,omitempty"`
+	TxStatus  string          `json:"tx_status,omitempty"`
+	CreatedAt string          `json:"created_at,omitempty"`
+	Workflow  *WorkflowRecord `json:"workflow,omitempty"`
+}
+
+// FastAdapterRecord is the fast-adapter section
+type FastAdapterRecord struct {
+	InstructionID    string `json:"instruction_id,omitempty"`
+	Type             string `json:"type,omitempty"`
+	Status           string `json:"status,omitempty"`
+	StatusCode       int    `json:"status_code"`
+	CancelReasonCode string `json:"cancel_reason_code,omitempty"`
+	RejectReasonCode string `json:"reject_reason_code,omitempty"`
+	CreatedAt        string `json:"created_at,omitempty"`
+}
+
+// RPPAdapterRecord is the rpp-adapter section
+type RPPAdapterRecord struct {
+	ReqBizMsgID  string           `json:"req_biz_msg_id,omitempty"`
+	PartnerMsgID string           `json:"partner_msg_id,omitempty"`
+	PartnerTxID  string           `json:"partner_tx_id,omitempty"`
+	EndToEndID   string           `json:"end_to_end_id,omitempty"`
+	Status       string           `json:"status,omitempty"`
+	CreatedAt    string           `json:"created_at,omitempty"`
+	Info         string           `json:"info,omitempty"`
+	Workflows    []WorkflowRecord `json:"workflows"`
+}
+
+// PartnerpayEngineRecord is the partnerpay-engine section
+type PartnerpayEngineRecord struct {
+	Charge   PPEChargeRecord `json:"charge"`
+	Workflow *WorkflowRecord `json:"workflow,omitempty"`
+}
+
+// PPEChargeRecord is a partnerpay-engine charge row
+type PPEChargeRecord struct {
+	TransactionID           string `json:"transaction_id,omitempty"`
+	Status                  string `json:"status,omitempty"`
+	StatusReason            string `json:"status_reason,omitempty"`
+	StatusReasonDescription string `json:"status_reason_description,omitempty"`
+	CreatedAt               string `json:"created_at,omitempty"`
+	UpdatedAt               string `json:"updated_at,omitempty"`
+	ErrorCode               string `json:"error_code,omitempty"`
+	ErrorMsg                string `json:"error_msg,omitempty"`
+}
+
+// SQLRecord holds the generated deploy and rollback SQL keyed by database
+// (payment_core, payment_engine, partnerpay_engine, rpp_adapter)
+type SQLRecord struct {
+	SchemaVersion int                 `json:"schema_version,omitempty"` // set on NDJSON lines only
+	Type          string              `json:"type,omitempty"`           // "sql" on NDJSON lines only
+	Deploy        map[string][]string `json:"deploy"`
+	Rollback      map[string][]string `json:"rollback"`
+}
+
+// NewResultRecord converts a transaction result into its serialized form
+func NewResultRecord(result domain.TransactionResult, index int) ResultRecord {
+	caseType := string(result.CaseType)
+	if result.CaseType == "" {
+		caseType = string(domain.CaseNone)
+		if result.QueryFailed() {
+			caseType = domain.QueryFailedStatus
+		}
+	}
+
+	record := ResultRecord{
+		Index:        index,
+		InputID:      result.InputID,
+		Case:         caseType,
+		Error:        result.Error,
+		QueryRetries: result.QueryRetries(),
+	}
+	for _, failure := range result.QueryFailures {
+		record.QueryFailures = append(record.QueryFailures, QueryFailureRecord(failure))
+	}
+
+	if pe := result.PaymentEngine; pe != nil {
+		record.PaymentEngine = &PaymentEngineRecord{
+			Transfers: PETransfersRecord(pe.Transfers),
+			Workflow:  newWorkflowRecord(pe.Workflow),
+		}
+	}
+
+	if pc := result.PaymentCore; pc != nil {
+		record.PaymentCore = &PaymentCoreRecord{
+			InternalAuth:     newPCInternalRecord(pc.InternalAuth),
+			InternalCapture:  newPCInternalRecord(pc.InternalCapture),
+			ExternalTransfer: newPCExternalRecord(pc.ExternalTransfer),
+		}
+	}
+
+	if fa := result.FastAdapter; fa != nil {
+		fastAdapter := FastAdapterRecord(*fa)
+		record.FastAdapter = &fastAdapter
+	}
+
+	if ra := result.RPPAdapter; ra != nil {
+		rpp := &RPPAdapterRecord{
+			ReqBizMsgID:  ra.ReqBizMsgID,
+			PartnerMsgID: ra.PartnerMsgID,
+			PartnerTxID:  ra.PartnerTxID,
+			EndToEndID:   ra.EndToEndID,
+			Status:       ra.Status,
+			CreatedAt:    ra.CreatedAt,
+			Info:         ra.Info,
+			Workflows:    make([]WorkflowRecord, 0, len(ra.Workflow)),
+		}
+		for _, wf := range ra.Workflow {
+			if wfRecord := newWorkflowRecord(wf); wfRecord != nil {
+				rpp.Workflows = append(rpp.Workflows, *wfRecord)
+			}
+		}
+		record.RPPAdapter = rpp
+	}
+
+	if ppe := result.PartnerpayEngine; ppe != nil {
+		record.PartnerpayEngine = &PartnerpayEngineRecord{
+			Charge:   PPEChargeRecord(ppe.Charge),
+			Workflow: newWorkflowRecord(ppe.Workflow),
+		}
+	}
+
+	return record
+}
+
+// newWorkflowRecord returns nil for workflows that were not found
+func newWorkflowRecord(wf domain.WorkflowInfo) *WorkflowRecord {
+	if wf.RunID == "" && wf.WorkflowID == "" && wf.State == "" {
+		return nil
+	}
+	return &WorkflowRecord{
+		WorkflowID:  wf.WorkflowID,
+		RunID:       wf.RunID,
+		State:       wf.State,
+		StateName:   wf.GetFormattedState(),
+		Attempt:     wf.Attempt,
+		PrevTransID: wf.PrevTransID,
+		Data:        wf.Data,
+	}
+}
+
+func newPCInternalRecord(info domain.PCInternalInfo) *PCInternalRecord {
+	if info.TxID == "" && info.TxStatus == "" && info.Workflow.RunID == "" {
+		return nil
+	}
+	return &PCInternalRecord{
+		TxID:      info.TxID,
+		GroupID:   info.GroupID,
+		TxType:    info.TxType,
+		TxStatus:  info.TxStatus,
+		ErrorCode: info.ErrorCode,
+		ErrorMsg:  info.ErrorMsg,
+		CreatedAt: info.CreatedAt,
+		Workflow:  newWorkflowRecord(info.Workflow),
+	}
+}
+
+func newPCExternalRecord(info domain.PCExternalInfo) *PCExternalRecord {
+	if info.RefID == "" && info.TxStatus == "" && info.Workflow.RunID == "" {
+		return nil
+	}
+	return &PCExternalRecord{
+		RefID:     info.RefID,
+		GroupID:   info.GroupID,
+		TxType:    info.TxType,
+		TxStatus:  info.TxStatus,
+		CreatedAt: info.CreatedAt,
+		Workflow:  newWorkflowRecord(info.Workflow),
+	}
+}
+
+// NewSQLRecord converts generated SQL statements into their serialized form.
+// Every database key is always present so consumers need no existence checks.
+func NewSQLRecord(statements domain.SQLStatements) *SQLRecord {
+	nonNil := func(stmts []string) []string {
+		if stmts == nil {
+			return []string{}
+		}
+		return stmts
+	}
+
+	return &SQLRecord{
+		Deploy: map[string][]string{
+			"payment_core":      nonNil(statements.PCDeployStatements),
+			"payment_engine":    nonNil(statements.PEDeployStatements),
+			"partnerpay_engine": nonNil(statements.PPEDeployStatements),
+			"rpp_adapter":       nonNil(statements.RPPDeployStatements),
+		},
+		Rollback: map[string][]string{
+			"payment_core":      nonNil(statements.PCRollbackStatements),
+			"payment_engine":    nonNil(statements.PERollbackStatements),
+			"partnerpay_engine": nonNil(statements.PPERollbackStatements),
+			"rpp_adapter":       nonNil(statements.RPPRollbackStatements),
+		},
+	}
+}
+
+// WriteStructuredResults writes results and, when statements is non-nil, the
+// generated SQL. JSON emits a single ResultDocument; NDJSON emits one "result"
+// line per transaction followed by one "sql" line.
+func WriteStructuredResults(w io.Writer, format OutputFormat, results []domain.TransactionResult, statements *domain.SQLStatements) error {
+	records := make([]ResultRecord, 0, len(results))
+	for idx, result := range results {
+		records = append(records, NewResultRecord(result, idx+1))
+	}
+
+	var sqlRecord *SQLRecord
+	if statements != nil {
+		sqlRecord = NewSQLRecord(*statements)
+	}
+
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ResultDocument{
+			SchemaVersion: ResultSchemaVersion,
+			Results:       records,
+			SQL:           sqlRecord,
+		})
+	case OutputNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			record.SchemaVersion = ResultSchemaVersion
+			record.Type = "result"
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		if sqlRecord != nil {
+			sqlRecord.SchemaVersion = ResultSchemaVersion
+			sqlRecord.Type = "sql"
+			return encoder.Encode(sqlRecord)
+		}
+		return nil
+	default:
+		return fmt.Errorf("output format %q is not structured", format)
+	}
+}
+
+// WriteStructuredBatchResults writes batch results and their generated SQL to a file
+func WriteStructuredBatchResults(results []domain.TransactionResult, statements domain.SQLStatements, outputPath string, format OutputFormat) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+
+	if err := WriteStructuredResults(file, format, results, &statements); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+
+	return file.Close()
+}
+
+// StructuredOutputPath swaps the extension of a text results path for the given format
+func StructuredOutputPath(textPath string, format OutputFormat) string {
+	return strings.TrimSuffix(textPath, ".txt") + "." + string(format)
+}
+
+// ReadStructuredResults reads the result records back from a file written with
+// --output json or ndjson. Both a single ResultDocument and NDJSON lines are
+// accepted; "sql" lines are skipped.
+func ReadStructuredResults(path string) ([]ResultRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var records []ResultRecord
+	decoder := json.NewDecoder(file)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse results file %s: %v", path, err)
+		}
+
+		var probe struct {
+			Type    string         `json:"type"`
+			Results []ResultRecord `json:"results"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return nil, fmt.Errorf("failed to parse results file %s: %v", path, err)
+		}
+
+		switch {
+		case probe.Results != nil:
+			records = append(records, probe.Results...)
+		case probe.Type == "result":
+			var record ResultRecord
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return nil, fmt.Errorf("failed to parse result line in %s: %v", path, err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
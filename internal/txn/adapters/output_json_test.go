@@ -0,0 +1,184 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func sampleStructuredResult() domain.TransactionResult {
+	return domain.TransactionResult{
+		InputID:  "txn-1",
+		CaseType: domain.CasePeStuck230RepublishPC,
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Transfers: domain.PETransfersInfo{Status: "PROCESSING", TransactionID: "txn-1"},
+			Workflow:  domain.WorkflowInfo{WorkflowID: "workflow_transfer_payment", RunID: "run-1", State: "230", Attempt: 2},
+		},
+		RPPAdapter: &domain.RPPAdapterInfo{
+			EndToEndID: "20250101GXSPMYXXXXXXXXXXXXXXXXXX",
+			Workflow: []domain.WorkflowInfo{
+				{WorkflowID: "wf_ct_cashout", RunID: "rpp-run-1", State: "900"},
+			},
+		},
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for input, want := range map[string]OutputFormat{"": OutputText, "text": OutputText, "JSON": OutputJSON, "ndjson": OutputNDJSON} {
+		got, err := ParseOutputFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseOutputFormat(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseOutputFormat("yaml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestWriteStructuredResults_JSON(t *testing.T) {
+	statements := domain.SQLStatements{
+		PEDeployStatements:   []string{"UPDATE workflow_execution SET state = 222 WHERE run_id = 'run-1';"},
+		PERollbackStatements: []string{"UPDATE workflow_execution SET state = 230 WHERE run_id = 'run-1';"},
+	}
+	results := []domain.TransactionResult{sampleStructuredResult(), {InputID: "txn-2", Error: "not found"}}
+
+	var buf bytes.Buffer
+	if err := WriteStructuredResults(&buf, OutputJSON, results, &statements); err != nil {
+		t.Fatalf("WriteStructuredResults: %v", err)
+	}
+
+	var doc ResultDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if doc.SchemaVersion != ResultSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", ResultSchemaVersion, doc.SchemaVersion)
+	}
+	if len(doc.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(doc.Results))
+	}
+
+	first := doc.Results[0]
+	if first.Case != string(domain.CasePeStuck230RepublishPC) || first.Index != 1 {
+		t.Errorf("unexpected case or index: %+v", first)
+	}
+	wf := first.PaymentEngine.Workflow
+	if wf == nil || wf.State != "230" || wf.StateName != domain.FormatWorkflowState("workflow_transfer_payment", "230") || wf.Attempt != 2 {
+		t.Errorf("unexpected payment-engine workflow: %+v", wf)
+	}
+	if first.PaymentCore != nil || first.FastAdapter != nil {
+		t.Errorf("expected absent adapters to be omitted: %+v", first)
+	}
+	if len(first.RPPAdapter.Workflows) != 1 || first.RPPAdapter.Workflows[0].RunID != "rpp-run-1" {
+		t.Errorf("unexpected rpp-adapter workflows: %+v", first.RPPAdapter.Workflows)
+	}
+
+	second := doc.Results[1]
+	if second.Case != string(domain.CaseNone) || second.Error != "not found" {
+		t.Errorf("expected unidentified result to report NOT_FOUND with error: %+v", second)
+	}
+
+	if doc.SQL == nil || len(doc.SQL.Deploy["payment_engine"]) != 1 || len(doc.SQL.Rollback["payment_engine"]) != 1 {
+		t.Fatalf("unexpected sql section: %+v", doc.SQL)
+	}
+	if rpp, ok := doc.SQL.Deploy["rpp_adapter"]; !ok || rpp == nil || len(rpp) != 0 {
+		t.Errorf("expected every database key to be present with an empty list, got %v", doc.SQL.Deploy)
+	}
+}
+
+func TestWriteStructuredResults_NDJSON(t *testing.T) {
+	statements := domain.SQLStatements{}
+	results := []domain.TransactionResult{sampleStructuredResult(), sampleStructuredResult()}
+
+	var buf bytes.Buffer
+	if err := WriteStructuredResults(&buf, OutputNDJSON, results, &statements); err != nil {
+		t.Fatalf("WriteStructuredResults: %v", err)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			SchemaVersion int    `json:"schema_version"`
+			Type          string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line is not valid JSON: %v", err)
+		}
+		if line.SchemaVersion != ResultSchemaVersion {
+			t.Errorf("expected schema version on every line, got %d", line.SchemaVersion)
+		}
+		types = append(types, line.Type)
+	}
+
+	want := []string{"result", "result", "sql"}
+	if len(types) != len(want) {
+		t.Fatalf("expected line types %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("line %d: expected type %q, got %q", i, want[i], types[i])
+		}
+	}
+}
+
+func TestReadStructuredResults(t *testing.T) {
+	statements := domain.SQLStatements{PEDeployStatements: []string{"UPDATE workflow_execution SET state = 222 WHERE run_id = 'run-1';"}}
+	results := []domain.TransactionResult{sampleStructuredResult(), {InputID: "txn-2", Error: "not found"}}
+
+	for _, format := range []OutputFormat{OutputJSON, OutputNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results."+string(format))
+			if err := WriteStructuredBatchResults(results, statements, path, format); err != nil {
+				t.Fatalf("WriteStructuredBatchResults: %v", err)
+			}
+
+			records, err := ReadStructuredResults(path)
+			if err != nil {
+				t.Fatalf("ReadStructuredResults: %v", err)
+			}
+			if len(records) != 2 {
+				t.Fatalf("expected 2 records, got %d", len(records))
+			}
+			if records[0].InputID != "txn-1" || records[0].Case != string(domain.CasePeStuck230RepublishPC) {
+				t.Errorf("unexpected first record: %+v", records[0])
+			}
+			if records[1].InputID != "txn-2" || records[1].Error != "not found" {
+				t.Errorf("unexpected second record: %+v", records[1])
+			}
+		})
+	}
+}
+
+func TestNewResultRecord_QueryFailed(t *testing.T) {
+	result := domain.TransactionResult{
+		InputID:          "txn-3",
+		QueryFailures:    []domain.QueryFailure{{System: "payment-core", Error: "doorman query failed: 503", Attempts: 3}},
+		RecoveredRetries: 1,
+	}
+
+	record := NewResultRecord(result, 1)
+	if record.Case != domain.QueryFailedStatus {
+		t.Errorf("expected case %s, got %s", domain.QueryFailedStatus, record.Case)
+	}
+	if len(record.QueryFailures) != 1 || record.QueryFailures[0].Attempts != 3 {
+		t.Errorf("unexpected query failures: %+v", record.QueryFailures)
+	}
+	if record.QueryRetries != 3 {
+		t.Errorf("expected failed and recovered retries to be counted, got %d", record.QueryRetries)
+	}
+
+	var text bytes.Buffer
+	WriteResult(&text, result, 1)
+	if !bytes.Contains(text.Bytes(), []byte("payment-core: query failed after 3 attempts")) {
+		t.Errorf("expected failure in text output, got:\n%s", text.String())
+	}
+	if !bytes.Contains(text.Bytes(), []byte("queries succeeded after 1 retries")) {
+		t.Errorf("expected recovered retries in text output, got:\n%s", text.String())
+	}
+}
@@ -0,0 +1,129 @@
+package adapters
+
+import (
+	"buddy/internal/txn/domain"
+)
+
+// codeIdentifiedCases lists cases that IdentifyCase detects in code rather than
+// through sop_rules.yaml, mapped to the country they are gated to
+var codeIdentifiedCases = map[domain.Case]string{
+	domain.CaseCashInStuck100Retry:          "my",
+	domain.CaseCashInStuck100UpdateMismatch: "my",
+}
+
+// manualCase describes a case that no rule identifies because the operator
+// raises it by hand
+type manualCase struct {
+	country  string
+	raisedBy string // how the operator raises the case
+	ownSQL   bool   // the command that raises the case builds its SQL without a template
+}
+
+// manualCases lists cases that are raised by the operator rather than identified
+// from the transaction, mapped to how they are raised
+var manualCases = map[domain.Case]manualCase{
+	domain.CaseRppQrPaymentReject210_0: {
+		country:  "my",
+		raisedBy: "manual reject once PayNet confirms it has no record of the QR payment",
+	},
+	domain.CaseRppNoResponseRejectNotFound: {
+		country:  "my",
+		raisedBy: "manual reject once PayNet confirms it has no record of the transfer",
+	},
+	domain.CaseUserNameChangeQrInvalidation: {
+		country:  "my",
+		raisedBy: "rpp qr-invalidate command",
+		ownSQL:   true,
+	},
+}
+
+// decisionSources maps each case that an operator decision leads to onto the
+// interactive cases it is decided from. A case deciding to itself is left out,
+// as it still has to be identified by a rule.
+func decisionSources() map[domain.Case][]domain.Case {
+	sources := make(map[domain.Case][]domain.Case)
+	for _, caseType := range domain.GetCaseSummaryOrder() {
+		for _, target := range interactiveCases[caseType] {
+			if target != caseType {
+				sources[target] = append(sources[target], caseType)
+			}
+		}
+	}
+	return sources
+}
+
+// CaseCoverage reports how one SOP case is wired up for an environment
+type CaseCoverage struct {
+	Case             domain.Case
+	Rules            []CaseRule    // rules that identify the case, in evaluation order
+	IdentifiedInCode string        // country the case is identified in code for, empty if rule-driven
+	DecidedFrom      []domain.Case // interactive cases whose decision leads to this case
+	RaisedBy         string        // how the operator raises a case that is not identified
+	HasTemplate      bool
+	Interactive      bool
+}
+
+// Missing lists what the case lacks to be identified and remediated. Cases that
+// are reached through a decision or raised by the operator need no rule of their
+// own, but an interactive case always does: nothing else can bring its prompt up.
+func (c CaseCoverage) Missing() []string {
+	var missing []string
+	reachable := len(c.DecidedFrom) > 0 || c.RaisedBy != ""
+	if len(c.Rules) == 0 && c.IdentifiedInCode == "" && (c.Interactive || !reachable) {
+		missing = append(missing, "rule")
+	}
+	if !c.HasTemplate {
+		missing = append(missing, "template")
+	}
+	return missing
+}
+
+// CaseCoverage returns the coverage of every case in the summary order that
+// applies to env. Cases gated entirely to another country are left out; cases
+// with no rule at all are always included so that gaps show up everywhere.
+func (r *SOPRepository) CaseCoverage(env string) []CaseCoverage {
+	sources := decisionSources()
+
+	var coverage []CaseCoverage
+	for _, caseType := range domain.GetCaseSummaryOrder() {
+		c := CaseCoverage{
+			Case:        caseType,
+			HasTemplate: sqlTemplates[caseType] != nil,
+			DecidedFrom: sources[caseType],
+		}
+		_, c.Interactive = interactiveCases[caseType]
+
+		gatedElsewhere := false
+		for _, rule := range r.rules {
+			if rule.CaseType != caseType {
+				continue
+			}
+			if rule.Country != "" && rule.Country != env {
+				gatedElsewhere = true
+				continue
+			}
+			c.Rules = append(c.Rules, rule)
+		}
+
+		if country, ok := codeIdentifiedCases[caseType]; ok {
+			if country != env {
+				continue
+			}
+			c.IdentifiedInCode = country
+		}
+
+		if manual, ok := manualCases[caseType]; ok {
+			if manual.country != env {
+				continue
+			}
+			c.RaisedBy = manual.raisedBy
+			c.HasTemplate = c.HasTemplate || manual.ownSQL
+		}
+
+		if len(c.Rules) == 0 && gatedElsewhere {
+			continue
+		}
+		coverage = append(coverage, c)
+	}
+	return coverage
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"bytes"
+	"testing"
+
+	"buddy/internal/txn/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coverageByCase(coverage []CaseCoverage) map[domain.Case]CaseCoverage {
+	byCase := make(map[domain.Case]CaseCoverage, len(coverage))
+	for _, c := range coverage {
+		byCase[c.Case] = c
+	}
+	return byCase
+}
+
+func TestCaseCoverage_DefaultRules(t *testing.T) {
+	my := coverageByCase(NewSOPRepository().CaseCoverage("my"))
+
+	interactive := my[domain.CaseCashoutRpp210Pe220Pc201]
+	assert.True(t, interactive.Interactive)
+	assert.Empty(t, interactive.Missing())
+
+	stuck100 := my[domain.CaseCashInStuck100Retry]
+	assert.Equal(t, "my", stuck100.IdentifiedInCode)
+	assert.Empty(t, stuck100.Missing())
+
+	_, ok := my[domain.CaseFastCashinErroneousPe220]
+	assert.False(t, ok, "sg-only cases should not be listed for my")
+
+	sg := coverageByCase(NewSOPRepository().CaseCoverage("sg"))
+	_, ok = sg[domain.CaseCashInStuck100Retry]
+	assert.False(t, ok, "cases identified in code for my should not be listed for sg")
+	for _, rule := range sg[domain.CaseFastCashinErroneousPe220].Rules {
+		assert.Equal(t, "sg", rule.Country)
+	}
+}
+
+func TestCaseCoverage_DefaultRulesAreComplete(t *testing.T) {
+	for _, env := range []string{"my", "sg"} {
+		for _, c := range NewSOPRepository().CaseCoverage(env) {
+			assert.Empty(t, c.Missing(), "%s: %s", env, c.Case)
+		}
+	}
+
+	my := coverageByCase(NewSOPRepository().CaseCoverage("my"))
+	assert.NotEmpty(t, my[domain.CaseRppQrPaymentReject210_0].RaisedBy)
+	assert.Equal(t, []domain.Case{domain.CaseCashoutRpp210Pe220Pc201}, my[domain.CaseRpp210Pe220Pc201Accept].DecidedFrom)
+
+	sg := coverageByCase(NewSOPRepository().CaseCoverage("sg"))
+	_, ok := sg[domain.CaseUserNameChangeQrInvalidation]
+	assert.False(t, ok, "cases raised by hand in my should not be listed for sg")
+}
+
+func TestCaseCoverage_InteractiveCasesNeedRules(t *testing.T) {
+	repo := NewSOPRepository()
+	var rules []CaseRule
+	for _, rule := range repo.rules {
+		if rule.CaseType != domain.CaseFastCashoutPendingPe220Pc201 {
+			rules = append(rules, rule)
+		}
+	}
+	repo.SetRules(rules)
+
+	pending := coverageByCase(repo.CaseCoverage("sg"))[domain.CaseFastCashoutPendingPe220Pc201]
+	assert.True(t, pending.Interactive)
+	assert.Equal(t, []string{"rule"}, pending.Missing())
+
+	for caseType := range interactiveCases {
+		found := false
+		for _, rule := range NewSOPRepository().rules {
+			found = found || rule.CaseType == caseType
+		}
+		assert.True(t, found, "interactive case %s has no rule to identify it", caseType)
+	}
+}
+
+func TestCaseCoverage_ReportsMissingRules(t *testing.T) {
+	repo := NewSOPRepository()
+	repo.SetRules([]CaseRule{{
+		CaseType:    domain.CasePeTransferPayment210_0,
+		Description: "PE stuck at 210",
+		Conditions:  []RuleCondition{{FieldPath: "PaymentEngine.Workflow.State", Operator: "eq", Value: "210"}},
+	}})
+
+	coverage := repo.CaseCoverage("my")
+	byCase := coverageByCase(coverage)
+	assert.Empty(t, byCase[domain.CasePeTransferPayment210_0].Missing())
+	assert.Equal(t, []string{"rule"}, byCase[domain.CaseRppNoResponseResume].Missing())
+
+	var buf bytes.Buffer
+	incomplete := WriteCaseCoverage(&buf, "my", coverage)
+	require.Positive(t, incomplete)
+	out := buf.String()
+	assert.Contains(t, out, "rule (country: all): PE stuck at 210")
+	assert.Contains(t, out, `PaymentEngine.Workflow.State eq "210"`)
+	assert.Contains(t, out, "MISSING: rule")
+}
+
+func TestCaseSummaryOrder_ListsEveryTemplate(t *testing.T) {
+	listed := make(map[domain.Case]bool)
+	for _, caseType := range domain.GetCaseSummaryOrder() {
+		listed[caseType] = true
+	}
+	for caseType := range sqlTemplates {
+		assert.True(t, listed[caseType], "%s has a template but is missing from GetCaseSummaryOrder", caseType)
+	}
+}
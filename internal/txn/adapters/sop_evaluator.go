@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -23,6 +24,84 @@ func (r *SOPRepository) evaluateRule(rule CaseRule, result *domain.TransactionRe
 	return true
 }
 
+// ConditionTrace records how a single condition evaluated against a transaction.
+type ConditionTrace struct {
+	Condition RuleCondition
+	Value     interface{} // Value resolved by getFieldValue
+	Found     bool        // Whether the field path was reachable
+	Passed    bool
+}
+
+// RuleTrace records how every condition of a rule evaluated against a transaction.
+type RuleTrace struct {
+	Rule       CaseRule
+	Skipped    bool // Rule does not apply to the environment
+	Conditions []ConditionTrace
+	Satisfied  int
+}
+
+// Matched reports whether all conditions of the rule passed.
+func (t RuleTrace) Matched() bool {
+	return !t.Skipped && t.Satisfied == len(t.Conditions)
+}
+
+// ExplainRules evaluates every rule against the result without short-circuiting
+// and returns one trace per rule, in rule order. It does not modify result.
+func (r *SOPRepository) ExplainRules(result *domain.TransactionResult, env string) []RuleTrace {
+	traces := make([]RuleTrace, 0, len(r.rules))
+
+	for _, rule := range r.rules {
+		trace := RuleTrace{Rule: rule}
+		if rule.Country != "" && rule.Country != env {
+			trace.Skipped = true
+			traces = append(traces, trace)
+			continue
+		}
+
+		for _, condition := range rule.Conditions {
+			value, found := r.getFieldValue(condition.FieldPath, result)
+			passed := r.evaluateCondition(condition, result)
+			if passed {
+				trace.Satisfied++
+			}
+			trace.Conditions = append(trace.Conditions, ConditionTrace{
+				Condition: condition,
+				Value:     value,
+				Found:     found,
+				Passed:    passed,
+			})
+		}
+
+		traces = append(traces, trace)
+	}
+
+	return traces
+}
+
+// RankNearMisses returns up to limit applicable rule traces ordered by the number
+// of satisfied conditions, then by the fraction satisfied. Ties keep rule order.
+// A limit <= 0 returns all applicable traces.
+func RankNearMisses(traces []RuleTrace, limit int) []RuleTrace {
+	ranked := make([]RuleTrace, 0, len(traces))
+	for _, trace := range traces {
+		if !trace.Skipped {
+			ranked = append(ranked, trace)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Satisfied != ranked[j].Satisfied {
+			return ranked[i].Satisfied > ranked[j].Satisfied
+		}
+		return ranked[i].Satisfied*len(ranked[j].Conditions) > ranked[j].Satisfied*len(ranked[i].Conditions)
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
 // evaluateCondition evaluates a single condition against the transaction result.
 func (r *SOPRepository) evaluateCondition(condition RuleCondition, result *domain.TransactionResult) bool {
 	fieldValue, ok := r.getFieldValue(condition.FieldPath, result)
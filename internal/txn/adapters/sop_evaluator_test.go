@@ -147,3 +147,67 @@ func TestEvaluateRule_PeStuck300RppNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestExplainRules_RanksNearMisses(t *testing.T) {
+	// PE transfer payment at 210 but attempt 1: pe_transfer_payment_210_0 misses on attempt only
+	result := &domain.TransactionResult{
+		InputID: "explain-test",
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Workflow: domain.WorkflowInfo{
+				WorkflowID: "workflow_transfer_payment",
+				State:      "210",
+				Attempt:    1,
+			},
+		},
+	}
+
+	repo := NewSOPRepository()
+	if caseType := repo.IdentifyCase(result, "my"); caseType != domain.CaseNone {
+		t.Fatalf("expected no case to match, got %s", caseType)
+	}
+
+	traces := repo.ExplainRules(result, "my")
+	if len(traces) != len(repo.rules) {
+		t.Fatalf("expected one trace per rule, got %d traces for %d rules", len(traces), len(repo.rules))
+	}
+
+	for _, trace := range traces {
+		if trace.Rule.Country == "sg" && !trace.Skipped {
+			t.Errorf("expected sg rule %s to be skipped for my", trace.Rule.CaseType)
+		}
+		if trace.Matched() {
+			t.Errorf("expected no rule to match, %s matched", trace.Rule.CaseType)
+		}
+	}
+
+	ranked := RankNearMisses(traces, 3)
+	if len(ranked) != 3 {
+		t.Fatalf("expected 3 ranked traces, got %d", len(ranked))
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Satisfied > ranked[i-1].Satisfied {
+			t.Errorf("expected traces ordered by satisfied conditions, got %d before %d", ranked[i-1].Satisfied, ranked[i].Satisfied)
+		}
+	}
+
+	var peTrace *RuleTrace
+	for i := range ranked {
+		if ranked[i].Rule.CaseType == domain.CasePeTransferPayment210_0 {
+			peTrace = &ranked[i]
+		}
+	}
+	if peTrace == nil {
+		t.Fatalf("expected %s among the top near misses", domain.CasePeTransferPayment210_0)
+	}
+	if peTrace.Satisfied != len(peTrace.Conditions)-1 {
+		t.Errorf("expected all but one condition satisfied, got %d/%d", peTrace.Satisfied, len(peTrace.Conditions))
+	}
+
+	for _, ct := range peTrace.Conditions {
+		if ct.Condition.FieldPath == "PaymentEngine.Workflow.Attempt" {
+			if ct.Passed || ct.Value != 1 || !ct.Found {
+				t.Errorf("expected attempt condition to fail with resolved value 1, got %+v", ct)
+			}
+		}
+	}
+}
@@ -21,6 +21,11 @@ func NewSOPRepository() *SOPRepository {
 	}
 }
 
+// SetRules replaces the rules used for case identification
+func (r *SOPRepository) SetRules(rules []CaseRule) {
+	r.rules = rules
+}
+
 // IdentifyCase identifies SOP case for a transaction result
 func (r *SOPRepository) IdentifyCase(result *domain.TransactionResult, env string) domain.Case {
 	// Check if we've already identified case
@@ -28,6 +33,12 @@ func (r *SOPRepository) IdentifyCase(result *domain.TransactionResult, env strin
 		return result.CaseType
 	}
 
+	// Never match a case on partial data; the fix SQL could target the wrong state
+	if result.QueryFailed() {
+		result.CaseType = domain.CaseNone
+		return result.CaseType
+	}
+
 	// Special handling for cash-in stuck at state 100 with timestamp analysis
 	if caseType := r.identifyCashInStuck100Case(result, env); caseType != domain.CaseNone {
 		result.CaseType = caseType
@@ -69,6 +69,17 @@ func TestEcotxnChargeFailedCaptureFailedTMError(t *testing.T) {
 			t.Errorf("Expected case %s, got %s", domain.CaseEcotxnChargeFailedCaptureFailedTMError, result)
 		}
 	})
+
+	// The same data with a system that could not be queried must not match
+	t.Run("WithQueryFailure", func(t *testing.T) {
+		partial := *transactionResult
+		partial.CaseType = domain.CaseNone
+		partial.RecordQueryFailure("payment-engine", fmt.Errorf("network error"))
+
+		if result := sopRepo.IdentifyCase(&partial, "my"); result != domain.CaseNone {
+			t.Errorf("Expected no case on partial data, got %s", result)
+		}
+	})
 }
 
 // TestIndividualConditions tests each condition individually to identify the failing one
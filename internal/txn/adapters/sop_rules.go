@@ -1,390 +1,311 @@
 package adapters
 
-import "buddy/internal/txn/domain"
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"buddy/internal/errors"
+	"buddy/internal/txn/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed sop_rules.yaml
+var defaultSOPRulesYAML []byte
+
+// SOPRulesEnvVar names an environment variable pointing at an SOP rules override file
+const SOPRulesEnvVar = "BUDDY_SOP_RULES"
 
 // CaseRule defines a rule for identifying SOP cases
 type CaseRule struct {
-	CaseType    domain.Case
-	Description string
-	Country     string // optional: "", "my", "sg" for country-specific rules
-	Conditions  []RuleCondition
+	CaseType    domain.Case     `yaml:"case"`
+	Description string          `yaml:"description"`
+	Country     string          `yaml:"country"` // optional: "", "my", "sg" for country-specific rules
+	Conditions  []RuleCondition `yaml:"conditions"`
 }
 
 // RuleCondition defines a single condition in a rule
 type RuleCondition struct {
-	FieldPath string      // e.g., "PaymentEngine.Workflow.State"
-	Operator  string      // eq, ne, lt, gt, in, not_in, regex, contains
-	Value     interface{} // Expected value(s)
-	Country   string      // optional: "", "my", "sg" for country-specific rules
+	FieldPath string      `yaml:"field"`   // e.g., "PaymentEngine.Workflow.State"
+	Operator  string      `yaml:"op"`      // eq, ne, lt, gt, in, not_in, regex, contains
+	Value     interface{} `yaml:"value"`   // Expected value(s)
+	Country   string      `yaml:"country"` // optional: "", "my", "sg" for country-specific rules
 }
 
-// Helper constants for common field paths
-const (
-	pathPEWorkflowID         = "PaymentEngine.Workflow.WorkflowID"
-	pathPEWorkflowState      = "PaymentEngine.Workflow.State"
-	pathPEWorkflowAttempt    = "PaymentEngine.Workflow.Attempt"
-	pathPETransfersExtID     = "PaymentEngine.Transfers.ExternalID"
-	pathPCExtTransfersWfID   = "PaymentCore.ExternalTransfer.Workflow.WorkflowID"
-	pathPCExtTransfersState  = "PaymentCore.ExternalTransfer.Workflow.State"
-	pathPCExtTransfersAttempt = "PaymentCore.ExternalTransfer.Workflow.Attempt"
-	pathPCIntAuthState       = "PaymentCore.InternalAuth.Workflow.State"
-	pathPCIntCaptureWfID     = "PaymentCore.InternalCapture.Workflow.WorkflowID"
-	pathPCIntCaptureState    = "PaymentCore.InternalCapture.Workflow.State"
-	pathPCIntCaptureAttempt  = "PaymentCore.InternalCapture.Workflow.Attempt"
-	pathPCIntCaptureTxType   = "PaymentCore.InternalCapture.TxType"
-	pathPCIntCaptureTxStatus = "PaymentCore.InternalCapture.TxStatus"
-	pathRPPAdapterWfID       = "RPPAdapter.Workflow.WorkflowID"
-	pathRPPAdapterState      = "RPPAdapter.Workflow.State"
-	pathRPPAdapterAttempt    = "RPPAdapter.Workflow.Attempt"
-	pathRPPAdapterStatus     = "RPPAdapter.Status"
-	pathPartnerpayWfID       = "PartnerpayEngine.Workflow.WorkflowID"
-	pathPartnerpayState      = "PartnerpayEngine.Workflow.State"
-	pathPartnerpayAttempt    = "PartnerpayEngine.Workflow.Attempt"
-	pathFastAdapterStatus    = "FastAdapter.Status"
-)
+// SOPRulesVersion is the version of the SOP rules file layout this binary reads
+const SOPRulesVersion = 1
 
-// Common state values
-const (
-	stateInit              = "0"
-	stateSuccess           = "900"
-	stateFailed            = "500"
-	stateProcessing        = "201"
-	stateTransferProcessing = "220"
-	stateCaptureFailed      = "701"
-	stateCaptureFailedCond  = "701"
-	stateCaptureProcessing = "230"
-	stateTransactionFailed = "500"
-	stateRppWaitingResponse = "210"
-	stateRppReject         = "101"
-	stateRppReceived       = "200"
-	stateValidationFailed  = "122"
-)
+// sopRulesFile is the top-level layout of an SOP rules YAML file
+type sopRulesFile struct {
+	Version int        `yaml:"version"`
+	Rules   []CaseRule `yaml:"rules"`
+}
 
-// Common workflow IDs
-const (
-	wfTransferPayment = "workflow_transfer_payment"
-	wfTransferCollection = "workflow_transfer_collection"
-	wfExternalPayment = "external_payment_flow"
-	wfInternalPayment = "internal_payment_flow"
-	wfInternalCapture = "internal_payment_flow"
-	wfCashout         = "wf_ct_cashout"
-	wfQrPayment       = "wf_ct_qr_payment"
-	wfCashin          = "wf_ct_cashin"
-	wfRtpCashin       = "wf_ct_rtp_cashin"
-	wfProcessRegistry = "wf_process_registry"
-)
+// validOperators lists the operators understood by evaluateCondition
+var validOperators = map[string]bool{
+	"eq": true, "ne": true, "lt": true, "gt": true,
+	"in": true, "not_in": true, "regex": true, "contains": true,
+}
 
-// Helper function to create an equality condition
-func cond(fieldPath string, value interface{}) RuleCondition {
-	return RuleCondition{FieldPath: fieldPath, Operator: "eq", Value: value}
+// getDefaultSOPRules returns the default SOP case rules embedded in the binary.
+// The embedded file is checked against ValidateSOPRules by the package tests; it
+// cannot be validated here because SOPRepo is built before the SQL templates
+// are registered.
+func getDefaultSOPRules() []CaseRule {
+	rules, err := parseSOPRules(defaultSOPRulesYAML)
+	if err != nil {
+		panic(fmt.Sprintf("embedded SOP rules are invalid: %v", err))
+	}
+	return rules
 }
 
-// Helper function to create a not-equal condition
-func condNe(fieldPath string, value interface{}) RuleCondition {
-	return RuleCondition{FieldPath: fieldPath, Operator: "ne", Value: value}
+// parseSOPRules decodes SOP rules from YAML, rejecting unknown keys and files
+// written for another version of the layout
+func parseSOPRules(data []byte) ([]CaseRule, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file sopRulesFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration, "failed to parse SOP rules YAML")
+	}
+	switch file.Version {
+	case SOPRulesVersion:
+	case 0:
+		return nil, errors.Configuration(fmt.Sprintf("SOP rules file has no version (expected version: %d)", SOPRulesVersion))
+	default:
+		return nil, errors.Configuration(fmt.Sprintf("unsupported SOP rules version %d (expected version: %d)", file.Version, SOPRulesVersion))
+	}
+
+	for i := range file.Rules {
+		for j := range file.Rules[i].Conditions {
+			file.Rules[i].Conditions[j].Value = normalizeConditionValue(file.Rules[i].Conditions[j].Value)
+		}
+	}
+
+	return file.Rules, nil
 }
 
-// Helper function to create an "in" condition
-func condIn(fieldPath string, values []string) RuleCondition {
-	return RuleCondition{FieldPath: fieldPath, Operator: "in", Value: values}
+// LoadSOPRulesFile reads, parses and validates SOP rules from a file on disk
+func LoadSOPRulesFile(path string) ([]CaseRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeConfiguration,
+			fmt.Sprintf("failed to read SOP rules file %s", path))
+	}
+
+	rules, err := parseSOPRules(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSOPRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
 }
 
-// Helper function to create PE workflow conditions (state, attempt)
-func peWorkflowConds(state string, attempt int) []RuleCondition {
-	return []RuleCondition{
-		cond(pathPEWorkflowState, state),
-		cond(pathPEWorkflowAttempt, attempt),
+// ValidateSOPRules checks that every rule has a known case with a registered SQL
+// template, and that every condition uses a known operator, a field path that
+// exists on domain.TransactionResult and values of the field's type.
+func ValidateSOPRules(rules []CaseRule) error {
+	if len(rules) == 0 {
+		return errors.Configuration("SOP rules file contains no rules")
+	}
+
+	resultType := reflect.TypeOf(domain.TransactionResult{})
+	var problems []string
+
+	for i, rule := range rules {
+		ruleRef := fmt.Sprintf("rule %d (%s)", i+1, rule.CaseType)
+
+		if rule.CaseType == "" {
+			problems = append(problems, fmt.Sprintf("rule %d: case is required", i+1))
+		} else if _, ok := sqlTemplates[rule.CaseType]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: no SQL template registered for case", ruleRef))
+		}
+
+		if len(rule.Conditions) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one condition is required", ruleRef))
+		}
+
+		for j, condition := range rule.Conditions {
+			condRef := fmt.Sprintf("%s condition %d", ruleRef, j+1)
+
+			if !validOperators[condition.Operator] {
+				problems = append(problems, fmt.Sprintf("%s: unknown operator %q", condRef, condition.Operator))
+			}
+			fieldType, ok := fieldPathType(resultType, condition.FieldPath)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown field path %q", condRef, condition.FieldPath))
+			}
+			if condition.Operator == "in" || condition.Operator == "not_in" {
+				if condition.Value == nil || reflect.TypeOf(condition.Value).Kind() != reflect.Slice {
+					problems = append(problems, fmt.Sprintf("%s: operator %q requires a list value", condRef, condition.Operator))
+					continue
+				}
+			}
+			if ok {
+				if problem := conditionValueProblem(fieldType, condition); problem != "" {
+					problems = append(problems, fmt.Sprintf("%s: %s", condRef, problem))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Configuration("invalid SOP rules:\n  " + strings.Join(problems, "\n  "))
 	}
+	return nil
 }
 
-// Helper function to create PC external transfer conditions
-func pcExtTransferConds(state string, attempt int) []RuleCondition {
-	return []RuleCondition{
-		cond(pathPCExtTransfersWfID, wfExternalPayment),
-		cond(pathPCExtTransfersState, state),
-		cond(pathPCExtTransfersAttempt, attempt),
+// isValidFieldPath reports whether a dot path resolves on the given struct type
+func isValidFieldPath(t reflect.Type, fieldPath string) bool {
+	_, ok := fieldPathType(t, fieldPath)
+	return ok
+}
+
+// fieldPathType returns the type a dot path resolves to on the given struct type,
+// following pointers and slice elements the same way getFieldValue does. It
+// reports false if the path does not resolve.
+func fieldPathType(t reflect.Type, fieldPath string) (reflect.Type, bool) {
+	if fieldPath == "" {
+		return nil, false
+	}
+
+	current := t
+	for _, part := range strings.Split(fieldPath, ".") {
+		current = elemType(current)
+		if current.Kind() != reflect.Struct {
+			return nil, false
+		}
+
+		field, ok := current.FieldByName(part)
+		if !ok {
+			return nil, false
+		}
+		current = field.Type
 	}
+
+	return elemType(current), true
 }
 
-// Helper function to create RPP adapter conditions
-func rppAdapterConds(workflowID, state string, attempt int) []RuleCondition {
-	return []RuleCondition{
-		cond(pathRPPAdapterWfID, workflowID),
-		cond(pathRPPAdapterState, state),
-		cond(pathRPPAdapterAttempt, attempt),
+// elemType strips pointers and slices off t
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
 	}
+	return t
 }
 
-// getDefaultSOPRules returns the default SOP case rules
-func getDefaultSOPRules() []CaseRule {
-	return []CaseRule{
-		// 1. Complex Rules (PE + PC + RPP)
-		// Most specific rule first: PC 201/0, PE 220/0, RPP wf_ct_cashout at 900/0
-		{
-			CaseType:    domain.CasePcStuck201WaitingRppRepublishFromRpp,
-			Description: "PC stuck at 201/0, PE at 220/0, RPP wf_ct_cashout at 900/0 - republish RPP success message",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCExtTransfersWfID, wfExternalPayment),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				cond(pathRPPAdapterWfID, wfCashout),
-				cond(pathRPPAdapterState, stateSuccess),
-				cond(pathRPPAdapterAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CasePcExternalPaymentFlow201_0RPP900,
-			Description: "PC External Payment Flow 201/0 with RPP 900 (completed)",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				condNe(pathPETransfersExtID, ""),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				cond(pathRPPAdapterStatus, stateSuccess),
-			},
-		},
-		{
-			CaseType:    domain.CasePcExternalPaymentFlow201_0RPP210,
-			Description: "PC External Payment Flow 201/0 with RPP not completed (stuck)",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				condNe(pathPETransfersExtID, ""),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				condNe(pathRPPAdapterStatus, stateSuccess),
-			},
-		},
-		{
-			CaseType:    domain.CasePeCaptureProcessingPcCaptureFailedRppSuccess,
-			Description: "PE capture processing, PC capture failed, but RPP succeeded",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateCaptureProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCIntCaptureWfID, wfInternalCapture),
-				cond(pathPCIntCaptureState, stateFailed),
-				cond(pathPCIntCaptureAttempt, 0),
-				cond(pathPCIntCaptureTxType, "CAPTURE"),
-				cond(pathPCIntCaptureTxStatus, "FAILED"),
-				condIn(pathRPPAdapterWfID, []string{wfQrPayment, wfCashout}),
-				cond(pathRPPAdapterState, stateSuccess),
-				cond(pathRPPAdapterAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CasePeStuck300RppNotFound,
-			Description: "PE stuck at state 300 with auth success, no capture, no RPP",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, "300"),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond("PaymentCore.InternalAuth.Workflow.State", stateSuccess),
-				cond(pathPCIntCaptureWfID, ""), // Checks for empty InternalCapture.Workflow.WorkflowID
-				cond(pathRPPAdapterWfID, ""),   // Checks for nil/empty RPPAdapter
-			},
-		},
-		{
-			CaseType:    domain.CaseCashoutPe220Pc201Reject,
-			Description: "Cashout PE 220/0, PC 201/0, RPP PROCESSING - manual reject",
-			Country:     "sg",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCExtTransfersWfID, wfExternalPayment),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				cond(pathRPPAdapterStatus, "PROCESSING"),
-			},
-		},
-		{
-			CaseType:    domain.CaseCashoutRpp210Pe220Pc201,
-			Description: "Cashout PE 220/0, PC 201/0, RPP process registry 0/0, RPP cashout 210/0 - manual intervention required",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCExtTransfersWfID, wfExternalPayment),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				condIn(pathRPPAdapterWfID, []string{wfProcessRegistry, wfCashout, wfQrPayment}),
-				condIn(pathRPPAdapterState, []string{stateInit, "210"}),
-				cond(pathRPPAdapterAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CaseRpp210Pe220Pc201Accept,
-			Description: "RPP 210, PE 220, PC 201 - Manual Accept",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathRPPAdapterState, "210"),
-				condIn(pathRPPAdapterWfID, []string{wfCashout, wfQrPayment}),
-			},
-		},
-		{
-			CaseType:    domain.CaseRpp210Pe220Pc201Reject,
-			Description: "RPP 210, PE 220, PC 201 - Manual Reject",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathRPPAdapterState, "210"),
-				condIn(pathRPPAdapterWfID, []string{wfCashout, wfQrPayment}),
-			},
-		},
-		{
-			CaseType:    domain.CasePe220Pc201Rpp0StuckInit,
-			Description: "PE 220/0, PC 201/0, RPP wf_ct_qr_payment stuck at State 0 - manual PE rejection required",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCExtTransfersWfID, wfExternalPayment),
-				cond(pathPCExtTransfersState, stateProcessing),
-				cond(pathPCExtTransfersAttempt, 0),
-				cond(pathRPPAdapterWfID, wfQrPayment),
-				cond(pathRPPAdapterState, stateInit),
-			},
-		},
-
-		// 2. Medium Complexity (PE + PC or Partnerpay + PC)
-		{
-			CaseType:    domain.CaseThoughtMachineFalseNegative,
-			Description: "Thought Machine returning errors/false negatives, but transaction was successful",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateCaptureFailedCond),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCIntCaptureWfID, wfInternalCapture),
-				cond(pathPCIntCaptureState, stateFailed),
-				cond(pathPCIntCaptureAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CaseEcotxnChargeFailedCaptureFailedTMError,
-			Description: "Ecotxn Charge Failed Capture Failed with TMError",
-			Conditions: []RuleCondition{
-				cond(pathPartnerpayWfID, "workflow_charge"),
-				cond(pathPartnerpayState, "502"),
-				cond(pathPartnerpayAttempt, 0),
-				cond("PartnerpayEngine.Charge.StatusReason", "SYSTEM_ERROR"),
-				cond("PartnerpayEngine.Charge.StatusReasonDescription", "error occurred in Thought Machine."),
-				cond(pathPCIntCaptureWfID, wfInternalCapture),
-				cond(pathPCIntCaptureState, stateFailed),
-				cond(pathPCIntCaptureAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CasePeStuck230RepublishPC,
-			Description: "PE stuck at state 230 (capture) requires PC republish",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowState, stateCaptureProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathPCIntCaptureState, stateSuccess),
-				cond(pathPCIntCaptureAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CasePe2200FastCashinFailed,
-			Description: "PE Transfer Collection at state 220 with attempt 0 and Fast Adapter failed",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowID, wfTransferCollection),
-				cond(pathPEWorkflowState, stateTransferProcessing),
-				cond(pathPEWorkflowAttempt, 0),
-				cond(pathFastAdapterStatus, "FAILED"),
-			},
-		},
-
-		// 3. Simple Rules (Single Domain)
-		{
-			CaseType:    domain.CasePeTransferPayment210_0,
-			Description: "PE Transfer Payment stuck at state 210 with attempt 0",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, "210"),
-				cond(pathPEWorkflowID, wfTransferPayment),
-				cond(pathPEWorkflowAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CasePcExternalPaymentFlow200_11,
-			Description: "PC External Payment Flow stuck at state 200 with attempt 11",
-			Conditions: []RuleCondition{
-				cond(pathPCExtTransfersState, "200"),
-				cond(pathPCExtTransfersAttempt, 11),
-			},
-		},
-		{
-			CaseType:    domain.CasePeStuckAtLimitCheck102,
-			Description: "PE stuck at state 102 (stTransactionLimitChecked)",
-			Conditions: []RuleCondition{
-				cond(pathPEWorkflowState, "102"),
-				cond(pathPEWorkflowID, wfTransferPayment),
-			},
-		},
-		{
-			CaseType:    domain.CaseRppNoResponseResume,
-			Description: "RPP No Response Resume (timeout scenario)",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathRPPAdapterState, stateRppWaitingResponse),
-				cond(pathRPPAdapterAttempt, 0),
-				condIn(pathRPPAdapterWfID, []string{wfCashout, wfQrPayment}),
-			},
-		},
-		{
-			CaseType:    domain.CaseRppCashoutReject101_19,
-			Description: "RPP Cashout Reject at state 101 with attempt 19",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathRPPAdapterWfID, wfCashout),
-				cond(pathRPPAdapterState, stateRppReject),
-				cond(pathRPPAdapterAttempt, 19),
-			},
-		},
-		{
-			CaseType:    domain.CaseRppRtpCashinStuck200_0,
-			Description: "RPP RTP Cashin stuck at state 200 with attempt 0",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathRPPAdapterWfID, wfRtpCashin),
-				cond(pathRPPAdapterState, stateRppReceived),
-				cond(pathRPPAdapterAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CaseRppCashinValidationFailed122_0,
-			Description: "RPP Cashin Validation Failed at state 122 with attempt 0",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathRPPAdapterWfID, wfCashin),
-				cond(pathRPPAdapterState, stateValidationFailed),
-				cond(pathRPPAdapterAttempt, 0),
-			},
-		},
-		{
-			CaseType:    domain.CaseRppProcessRegistryStuckInit,
-			Description: "RPP Process Registry stuck at state 0 (stInit)",
-			Country:     "my",
-			Conditions: []RuleCondition{
-				cond(pathRPPAdapterWfID, wfProcessRegistry),
-				cond(pathRPPAdapterState, stateInit),
-			},
-		},
+// conditionValueProblem describes why the value of condition cannot match a
+// field of fieldType, e.g. an unquoted 220 compared with a string State. It
+// returns "" if the value fits. Fields of other kinds than string, integer and
+// bool are not checked.
+func conditionValueProblem(fieldType reflect.Type, condition RuleCondition) string {
+	switch condition.Operator {
+	case "regex", "contains":
+		if _, ok := condition.Value.(string); !ok {
+			return fmt.Sprintf("operator %q requires a string value, got %v (%T)", condition.Operator, condition.Value, condition.Value)
+		}
+		return ""
+	case "in", "not_in":
+		items := reflect.ValueOf(condition.Value)
+		for i := 0; i < items.Len(); i++ {
+			if problem := valueKindProblem(fieldType, condition.FieldPath, items.Index(i).Interface()); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	default:
+		if condition.Value == nil {
+			return ""
+		}
+		return valueKindProblem(fieldType, condition.FieldPath, condition.Value)
+	}
+}
+
+// valueKindProblem describes why value cannot be compared with a field of
+// fieldType, or returns "" if it can
+func valueKindProblem(fieldType reflect.Type, fieldPath string, value interface{}) string {
+	var fits bool
+	switch fieldType.Kind() {
+	case reflect.String:
+		_, fits = value.(string)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, fits = value.(int)
+	case reflect.Bool:
+		_, fits = value.(bool)
+	default:
+		return ""
+	}
+
+	if fits {
+		return ""
 	}
-}
\ No newline at end of file
+	return fmt.Sprintf("%s has type %s, got %v (%T); states are quoted strings, attempts are integers",
+		fieldPath, fieldType.Kind(), value, value)
+}
+
+// normalizeConditionValue converts YAML lists of strings into []string so they
+// behave like the rules previously declared in Go.
+func normalizeConditionValue(value interface{}) interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return value
+		}
+		strs = append(strs, s)
+	}
+	return strs
+}
+
+// SOPRulesOverridePath returns the path of the SOP rules override file and
+// whether it was explicitly requested through BUDDY_SOP_RULES.
+func SOPRulesOverridePath() (string, bool) {
+	if path := os.Getenv(SOPRulesEnvVar); path != "" {
+		return path, true
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, ".config", "buddy", "sop_rules.yaml"), false
+}
+
+// LoadSOPRulesOverride replaces the rules of the global SOPRepo with the
+// override file, if one exists. A missing default override file is not an error.
+func LoadSOPRulesOverride() error {
+	path, explicit := SOPRulesOverridePath()
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil
+		}
+		return errors.Wrap(err, errors.ErrorTypeConfiguration,
+			fmt.Sprintf("SOP rules override %s is not readable", path))
+	}
+
+	rules, err := LoadSOPRulesFile(path)
+	if err != nil {
+		return err
+	}
+
+	SOPRepo.SetRules(rules)
+	return nil
+}
@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func TestDefaultSOPRules_AreValid(t *testing.T) {
+	rules := getDefaultSOPRules()
+	if len(rules) == 0 {
+		t.Fatal("expected embedded SOP rules to be non-empty")
+	}
+
+	if err := ValidateSOPRules(rules); err != nil {
+		t.Fatalf("embedded SOP rules failed validation: %v", err)
+	}
+}
+
+func TestDefaultSOPRules_PreserveValueTypes(t *testing.T) {
+	rules := getDefaultSOPRules()
+	if rules[0].CaseType != domain.CasePcStuck201WaitingRppRepublishFromRpp {
+		t.Fatalf("expected first rule to be %s, got %s", domain.CasePcStuck201WaitingRppRepublishFromRpp, rules[0].CaseType)
+	}
+
+	for _, rule := range rules {
+		for _, condition := range rule.Conditions {
+			switch {
+			case strings.HasSuffix(condition.FieldPath, ".Attempt"):
+				if _, ok := condition.Value.(int); !ok {
+					t.Errorf("%s: %s should be an int, got %T", rule.CaseType, condition.FieldPath, condition.Value)
+				}
+			case condition.Operator == "in":
+				if _, ok := condition.Value.([]string); !ok {
+					t.Errorf("%s: %s should be a []string, got %T", rule.CaseType, condition.FieldPath, condition.Value)
+				}
+			case strings.HasSuffix(condition.FieldPath, ".State"):
+				if _, ok := condition.Value.(string); !ok {
+					t.Errorf("%s: %s should be a string, got %T", rule.CaseType, condition.FieldPath, condition.Value)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateSOPRules_RejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    CaseRule
+		wantErr string
+	}{
+		{
+			name: "unknown field path",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "PaymentEngine.Workflow.Stat", Operator: "eq", Value: "210"}},
+			},
+			wantErr: `unknown field path "PaymentEngine.Workflow.Stat"`,
+		},
+		{
+			name: "unknown operator",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "PaymentEngine.Workflow.State", Operator: "equals", Value: "210"}},
+			},
+			wantErr: `unknown operator "equals"`,
+		},
+		{
+			name: "case without template",
+			rule: CaseRule{
+				CaseType:   domain.Case("no_such_case"),
+				Conditions: []RuleCondition{{FieldPath: "PaymentEngine.Workflow.State", Operator: "eq", Value: "210"}},
+			},
+			wantErr: "no SQL template registered",
+		},
+		{
+			name: "in without list",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "RPPAdapter.Workflow.WorkflowID", Operator: "in", Value: "wf_ct_cashout"}},
+			},
+			wantErr: "requires a list value",
+		},
+		{
+			name: "unquoted state",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "PaymentEngine.Workflow.State", Operator: "eq", Value: 210}},
+			},
+			wantErr: "PaymentEngine.Workflow.State has type string, got 210 (int)",
+		},
+		{
+			name: "quoted attempt",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "PaymentEngine.Workflow.Attempt", Operator: "eq", Value: "0"}},
+			},
+			wantErr: "PaymentEngine.Workflow.Attempt has type int, got 0 (string)",
+		},
+		{
+			name: "unquoted state in list",
+			rule: CaseRule{
+				CaseType:   domain.CasePeTransferPayment210_0,
+				Conditions: []RuleCondition{{FieldPath: "RPPAdapter.Workflow.State", Operator: "in", Value: []interface{}{"0", 210}}},
+			},
+			wantErr: "RPPAdapter.Workflow.State has type string, got 210 (int)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSOPRules([]CaseRule{tt.rule})
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadSOPRulesFile(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	content := `version: 1
+rules:
+  - case: pe_transfer_payment_210_0
+    description: custom rule
+    conditions:
+      - { field: PaymentEngine.Workflow.State, op: eq, value: "210" }
+      - { field: RPPAdapter.Workflow.WorkflowID, op: in, value: [wf_ct_cashout] }
+`
+	if err := os.WriteFile(valid, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadSOPRulesFile(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 1 || len(rules[0].Conditions) != 2 {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	unknownKey := filepath.Join(dir, "unknown.yaml")
+	if err := os.WriteFile(unknownKey, []byte("rules:\n  - case: pe_transfer_payment_210_0\n    typo: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSOPRulesFile(unknownKey); err == nil {
+		t.Error("expected error for unknown YAML key")
+	}
+
+	for name, version := range map[string]string{"missing version": "", "future version": "version: 2\n"} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".yaml")
+		if err := os.WriteFile(path, []byte(version+strings.TrimPrefix(content, "version: 1\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSOPRulesFile(path); err == nil || !strings.Contains(err.Error(), "version") {
+			t.Errorf("%s: expected a version error, got %v", name, err)
+		}
+	}
+
+	mistyped := filepath.Join(dir, "mistyped.yaml")
+	if err := os.WriteFile(mistyped, []byte(strings.Replace(content, `value: "210"`, "value: 210", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSOPRulesFile(mistyped); err == nil || !strings.Contains(err.Error(), "has type string") {
+		t.Errorf("expected an unquoted state to be rejected at load time, got %v", err)
+	}
+}
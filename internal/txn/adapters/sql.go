@@ -5,44 +5,73 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 )
 
-var autoChoices = make(map[domain.Case]int)
-
-// ResetAutoChoices clears any saved user choices for "Apply to All"
-func ResetAutoChoices() {
-	autoChoices = make(map[domain.Case]int)
-}
-
-// PrepopulateAutoChoice allows external code to pre-populate an auto-choice for a specific case type
-// This is used by the --auto flag to automatically resume transactions based on Jira ticket title
-func PrepopulateAutoChoice(caseType domain.Case, choice int) {
-	autoChoices[caseType] = choice
+// GenerateSQLStatements generates SQL statements for all supported cases using
+// templates, prompting on stdin for cases that need an operator decision.
+func GenerateSQLStatements(results []domain.TransactionResult) domain.SQLStatements {
+	statements, err := GenerateSQLStatementsWithDecisions(results, NewPromptDecisions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return statements
 }
 
-// GenerateSQLStatements generates SQL statements for all supported cases using templates.
-func GenerateSQLStatements(results []domain.TransactionResult) domain.SQLStatements {
+// GenerateSQLStatementsWithDecisions generates SQL statements for all supported
+// cases using templates. Decisions for interactive cases are taken from
+// decisions before any SQL is generated; if one cannot be made, no SQL is
+// generated, the result is marked with the error and the error is returned.
+func GenerateSQLStatementsWithDecisions(results []domain.TransactionResult, decisions DecisionProvider) (domain.SQLStatements, error) {
 	statements := domain.SQLStatements{}
 
-	// Reset auto-choices for "Apply to All" at the start of each batch
-	ResetAutoChoices()
+	// Populate index for use in interactive prompts
+	for i := range results {
+		results[i].Index = i + 1
+	}
+
+	// Resolve every decision up front so a missing one fails the run before any SQL exists
+	decided := make(map[int]Decision)
+	for i := range results {
+		if !hasSOPCase(results[i]) {
+			continue
+		}
+		if _, interactive := interactiveCases[results[i].CaseType]; !interactive {
+			continue
+		}
+		decision, err := decisions.Decide(results[i])
+		if err != nil {
+			results[i].Error = err.Error()
+			return statements, err
+		}
+		decided[i] = decision
+	}
 
 	// Group tickets by CaseType to allow cross-result consolidation
 	groupedTickets := make(map[domain.Case]*domain.DMLTicket)
+	groupedResults := make(map[domain.Case][]domain.TransactionResult)
 	caseErrors := make(map[domain.Case]string)
 
 	for i := range results {
-		// Populate index for use in interactive prompts
-		results[i].Index = i + 1
-
+		if !hasSOPCase(results[i]) {
+			continue
+		}
 		// SOP cases should already be identified by Identifydomain.Cases
 		caseType := results[i].CaseType
 
 		// Get the template function for this case
 		if templateFunc, exists := sqlTemplates[caseType]; exists {
-			ticket := templateFunc(results[i])
+			var ticket *domain.DMLTicket
+			if decision, ok := decided[i]; ok {
+				if _, hasCase := interactiveCases[caseType][decision]; !hasCase {
+					fmt.Fprintf(os.Stderr, "No SQL for %s: %s was decided %s, which has no SOP fix\n", results[i].InputID, caseType, decision)
+					continue
+				}
+				ticket = ticketForDecision(results[i], decision)
+			} else {
+				ticket = templateFunc(results[i])
+			}
 			if ticket != nil {
+				groupedResults[caseType] = append(groupedResults[caseType], results[i])
 				if existing, exists := groupedTickets[caseType]; exists {
 					// Merge templates from new ticket into existing one
 					existing.Deploy = append(existing.Deploy, ticket.Deploy...)
@@ -65,17 +94,33 @@ func GenerateSQLStatements(results []domain.TransactionResult) domain.SQLStateme
 			caseErrors[caseType] = err.Error()
 			continue
 		}
+		// Catch template typos before the SQL reaches a Doorman ticket
+		if err := VerifySQLStatements(generatedSQL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: SQL for case %s failed schema verification: %v\n", caseType, err)
+			caseErrors[caseType] = fmt.Sprintf("generated SQL failed schema verification: %v", err)
+			continue
+		}
+		// Prove the rollback puts back what the deploy changes
+		if err := CheckRollbackConsistency(groupedResults[caseType], generatedSQL); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: SQL for case %s failed the rollback check: %v\n", caseType, err)
+			caseErrors[caseType] = fmt.Sprintf("generated SQL failed the rollback check: %v", err)
+			continue
+		}
 		appendStatements(&statements, generatedSQL)
 	}
 
 	// Generate transfer table UPDATE statements for transactions with payment-core internal_auth
 	// Skip for pe_stuck_at_limit_check_102_4 case as it's handled in the template
 	// Skip for pe220_pc201_rpp0_stuck_init case as it's a multi-database rejection
-	// Skip for NOT_FOUND (CaseNone) cases as they should not generate any SQL
+	// Skip for NOT_FOUND (CaseNone) cases and incomplete results as they should not generate any SQL
 	for _, result := range results {
-		if shouldGenerateTransferUpdate(result) && result.CaseType != domain.CasePeStuckAtLimitCheck102 && result.CaseType != domain.CasePe220Pc201Rpp0StuckInit && result.CaseType != domain.CaseNone {
+		if hasSOPCase(result) && shouldGenerateTransferUpdate(result) && result.CaseType != domain.CasePeStuckAtLimitCheck102 && result.CaseType != domain.CasePe220Pc201Rpp0StuckInit {
 			transferUpdateSQL := generateTransferUpdateSQL(result)
 			if transferUpdateSQL != "" {
+				if err := VerifySQL("PE", transferUpdateSQL); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: skipping transfer update for %s: %v\n", result.InputID, err)
+					continue
+				}
 				statements.PEDeployStatements = append(statements.PEDeployStatements, transferUpdateSQL)
 			}
 		}
@@ -90,7 +135,14 @@ func GenerateSQLStatements(results []domain.TransactionResult) domain.SQLStateme
 		}
 	}
 
-	return statements
+	return statements, nil
+}
+
+// hasSOPCase reports whether SQL may be generated for result: it matched an SOP
+// case and every system was queried. A result with a failed query is missing
+// data, so even a matched case cannot be trusted.
+func hasSOPCase(result domain.TransactionResult) bool {
+	return !result.QueryFailed() && result.CaseType != "" && result.CaseType != domain.CaseNone
 }
 
 // shouldGenerateTransferUpdate checks if a transfer table UPDATE statement should be generated
@@ -227,60 +279,19 @@ func GetDMLTicketForCashoutRpp210Pe220Pc201(result domain.TransactionResult) *do
 		return nil
 	}
 
-	// Check if we already have an auto-choice for this case
-	if choice, exists := autoChoices[result.CaseType]; exists {
-		return handleChoice(choice, result)
-	}
-
-	// Display visual divider and transaction summary
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	WriteResult(os.Stdout, result, result.Index)
-
-	// Prompt user for option
-	fmt.Println("\nChoose an option:")
-	fmt.Println("1. Resume to Success (Manual Success) - This once")
-	fmt.Println("2. Reject/Fail (Manual Rejection) - This once")
-	fmt.Println("3. Resume to Success (Manual Success) - Apply to all similar")
-	fmt.Println("4. Reject/Fail (Manual Rejection) - Apply to all similar")
-	fmt.Print("\nEnter your choice (1, 2, 3, or 4): ")
-
-	var choice int
-	_, err := fmt.Scanln(&choice)
+	decision, err := NewPromptDecisions().Decide(result)
 	if err != nil {
-		result.Error = fmt.Sprintf("failed to read user choice: %v", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return nil
 	}
-
-	// Handle "Apply to All" options
-	switch choice {
-	case 3:
-		autoChoices[result.CaseType] = 1
-		choice = 1
-	case 4:
-		autoChoices[result.CaseType] = 2
-		choice = 2
-	}
-
-	return handleChoice(choice, result)
+	return ticketForDecision(result, decision)
 }
 
-// handleChoice processes the selected option for a given result
-func handleChoice(choice int, result domain.TransactionResult) *domain.DMLTicket {
-	// Generate DML ticket based on user's choice
-	switch choice {
-	case 1:
-		// Option 1: Resume to Success
-		if templateFunc, exists := sqlTemplates[domain.CaseRpp210Pe220Pc201Accept]; exists {
-			return templateFunc(result)
-		}
-	case 2:
-		// Option 2: Reject/Fail
-		if templateFunc, exists := sqlTemplates[domain.CaseRpp210Pe220Pc201Reject]; exists {
-			return templateFunc(result)
-		}
-	default:
-		result.Error = fmt.Sprintf("invalid choice: %d (must be 1-4)", choice)
-		return nil
+// ticketForDecision generates the ticket of the case that decision selects for
+// the interactive case of result
+func ticketForDecision(result domain.TransactionResult, decision Decision) *domain.DMLTicket {
+	if templateFunc, exists := sqlTemplates[interactiveCases[result.CaseType][decision]]; exists {
+		return templateFunc(result)
 	}
 	return nil
 }
@@ -315,6 +326,13 @@ func GetDMLTicketForRppNoResponseRejectNotFound(result domain.TransactionResult)
 	return nil
 }
 
+// GetDMLTicketForUserNameChangeQrInvalidation returns a DML ticket that marks the ACTIVE QR
+// codes of userID, identified by their created_at, as INACTIVE. It returns nil when
+// there is nothing to invalidate.
+func GetDMLTicketForUserNameChangeQrInvalidation(userID string, activeCreatedAts []string) *domain.DMLTicket {
+	return userNameChangeQrInvalidation(userID, activeCreatedAts)
+}
+
 // GetDMLTicketForPe220Pc201Rpp0StuckInit returns a DML ticket for PE 220, PC 201, RPP 0 rejection
 func GetDMLTicketForPe220Pc201Rpp0StuckInit(result domain.TransactionResult) *domain.DMLTicket {
 	sopRepo := SOPRepo
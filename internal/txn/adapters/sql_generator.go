@@ -66,6 +66,9 @@ func formatParameter(info domain.ParamInfo) string {
 		return fmt.Sprintf("'%v'", info.Value)
 	case "int":
 		return fmt.Sprintf("%v", info.Value)
+	case "sql":
+		// Raw SQL expression such as JSON_OBJECT(...); inserted without quoting
+		return fmt.Sprintf("%v", info.Value)
 	default:
 		// Default to string formatting for unknown types
 		return fmt.Sprintf("'%v'", info.Value)
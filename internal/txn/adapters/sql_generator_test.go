@@ -192,6 +192,35 @@ func TestThoughtMachineFalseNegativeTemplate(t *testing.T) {
 	assert.Contains(t, statements.PCRollbackStatements[0], "workflow_id = 'internal_payment_flow'")
 }
 
+func TestGenerateSQLStatements_SkipsIncompleteResults(t *testing.T) {
+	// Enough data for a transfer update and a thought_machine_false_negative fix
+	newResult := func(inputID string) domain.TransactionResult {
+		return domain.TransactionResult{
+			InputID: inputID,
+			PaymentEngine: &domain.PaymentEngineInfo{
+				Transfers: domain.PETransfersInfo{TransactionID: inputID, UpdatedAt: "2025-10-17T10:00:00Z"},
+				Workflow: domain.WorkflowInfo{
+					RunID: "pe-" + inputID, WorkflowID: "workflow_transfer_payment", State: "701", PrevTransID: "prev-trans-id",
+				},
+			},
+			PaymentCore: &domain.PaymentCoreInfo{
+				InternalAuth: domain.PCInternalInfo{TxID: "tx-" + inputID, TxStatus: "SUCCESS"},
+			},
+		}
+	}
+
+	unidentified := newResult("unidentified")
+
+	failed := newResult("failed")
+	failed.CaseType = domain.CaseThoughtMachineFalseNegative
+	failed.QueryFailures = []domain.QueryFailure{{System: "payment-core", Error: "503 Service Unavailable", Attempts: 3}}
+
+	statements, err := GenerateSQLStatementsWithDecisions([]domain.TransactionResult{unidentified, failed}, NewDecisions(true))
+	require.NoError(t, err)
+	assert.Empty(t, statements.PEDeployStatements)
+	assert.Empty(t, statements.PCDeployStatements)
+}
+
 func TestGetDMLTicketForRppResume(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,431 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlTokenKind classifies a lexical SQL token
+type sqlTokenKind int
+
+const (
+	sqlTokenIdent  sqlTokenKind = iota // bare or backtick-quoted identifier, keyword or function name
+	sqlTokenString                     // '...' or "..." literal; Text holds the unescaped value
+	sqlTokenNumber                     // numeric literal
+	sqlTokenPunct                      // operator or punctuation
+)
+
+// sqlToken is one lexical token of a SQL statement
+type sqlToken struct {
+	Kind   sqlTokenKind
+	Text   string
+	Quoted bool // identifier was written in backticks
+}
+
+// sqlAssignment is one "column = expr" of an UPDATE SET clause
+type sqlAssignment struct {
+	Column string
+	Expr   []sqlToken
+}
+
+// sqlPredicate is one AND-ed condition of a WHERE clause. Op is "=", "IN" or
+// "other" for conditions that do not pin a column to literal values.
+type sqlPredicate struct {
+	Column string
+	Op     string
+	Values []sqlToken
+}
+
+// sqlStatement is the parsed shape of a single DML statement
+type sqlStatement struct {
+	Kind        string // UPDATE, DELETE or INSERT
+	Table       string
+	Assignments []sqlAssignment
+	Columns     []string // INSERT column list
+	HasWhere    bool
+	WhereTokens []sqlToken
+	Where       []sqlPredicate
+	WhereHasOr  bool
+}
+
+// parseSQLStatements tokenizes sql, splits it on semicolons and parses each
+// statement. Comments are ignored and comment-only segments are skipped.
+func parseSQLStatements(sql string) ([]sqlStatement, error) {
+	tokens, err := tokenizeSQL(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []sqlStatement
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && !isSQLPunct(tokens[i], ";") {
+			continue
+		}
+		if i > start {
+			stmt, err := parseSQLStatement(tokens[start:i])
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, stmt)
+		}
+		start = i + 1
+	}
+
+	return statements, nil
+}
+
+// tokenizeSQL splits sql into tokens, dropping whitespace and comments
+func tokenizeSQL(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--") || c == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'' || c == '"':
+			value, next, err := scanSQLString(sql, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlTokenString, Text: value})
+			i = next
+		case c == '`':
+			end := strings.IndexByte(sql[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlTokenIdent, Text: sql[i+1 : i+1+end], Quoted: true})
+			i += end + 2
+		case isSQLIdentStart(c):
+			start := i
+			for i < len(sql) && (isSQLIdentStart(sql[i]) || (sql[i] >= '0' && sql[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlTokenIdent, Text: sql[start:i]})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(sql) && ((sql[i] >= '0' && sql[i] <= '9') || sql[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlTokenNumber, Text: sql[start:i]})
+		default:
+			op := string(c)
+			for _, candidate := range []string{"->>", "->", "<=", ">=", "<>", "!="} {
+				if strings.HasPrefix(sql[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlTokenPunct, Text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// scanSQLString reads a quoted literal starting at sql[start], handling
+// backslash escapes and doubled quotes
+func scanSQLString(sql string, start int) (string, int, error) {
+	quote := sql[start]
+	var value strings.Builder
+	for i := start + 1; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\\' && i+1 < len(sql):
+			i++
+			value.WriteByte(sql[i])
+		case c == quote && i+1 < len(sql) && sql[i+1] == quote:
+			i++
+			value.WriteByte(quote)
+		case c == quote:
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string literal")
+}
+
+func isSQLIdentStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$'
+}
+
+// isSQLPunct reports whether tok is the punctuation or operator p
+func isSQLPunct(tok sqlToken, p string) bool {
+	return tok.Kind == sqlTokenPunct && tok.Text == p
+}
+
+// isSQLKeyword reports whether tok is the unquoted keyword kw
+func isSQLKeyword(tok sqlToken, kw string) bool {
+	return tok.Kind == sqlTokenIdent && !tok.Quoted && strings.EqualFold(tok.Text, kw)
+}
+
+func parseSQLStatement(tokens []sqlToken) (sqlStatement, error) {
+	switch {
+	case isSQLKeyword(tokens[0], "UPDATE"):
+		return parseSQLUpdate(tokens)
+	case isSQLKeyword(tokens[0], "DELETE"):
+		return parseSQLDelete(tokens)
+	case isSQLKeyword(tokens[0], "INSERT"):
+		return parseSQLInsert(tokens)
+	default:
+		return sqlStatement{}, fmt.Errorf("unsupported statement starting with %q", tokens[0].Text)
+	}
+}
+
+// parseSQLTableName reads "table" or "schema.table" at tokens[pos]
+func parseSQLTableName(tokens []sqlToken, pos int) (string, int, error) {
+	if pos >= len(tokens) || tokens[pos].Kind != sqlTokenIdent {
+		return "", pos, fmt.Errorf("expected table name")
+	}
+	name := tokens[pos].Text
+	pos++
+	if pos+1 < len(tokens) && isSQLPunct(tokens[pos], ".") && tokens[pos+1].Kind == sqlTokenIdent {
+		name = tokens[pos+1].Text
+		pos += 2
+	}
+	return name, pos, nil
+}
+
+func parseSQLUpdate(tokens []sqlToken) (sqlStatement, error) {
+	stmt := sqlStatement{Kind: "UPDATE"}
+
+	table, pos, err := parseSQLTableName(tokens, 1)
+	if err != nil {
+		return stmt, fmt.Errorf("UPDATE: %w", err)
+	}
+	stmt.Table = table
+
+	if pos >= len(tokens) || !isSQLKeyword(tokens[pos], "SET") {
+		return stmt, fmt.Errorf("UPDATE %s: expected SET", table)
+	}
+	pos++
+
+	end := indexSQLKeyword(tokens, pos, "WHERE")
+	setTokens := tokens[pos:]
+	if end >= 0 {
+		setTokens = tokens[pos:end]
+	}
+
+	for _, part := range splitSQLTopLevel(setTokens, func(tok sqlToken) bool { return isSQLPunct(tok, ",") }) {
+		if len(part) < 3 || part[0].Kind != sqlTokenIdent || !isSQLPunct(part[1], "=") {
+			return stmt, fmt.Errorf("UPDATE %s: malformed assignment", table)
+		}
+		stmt.Assignments = append(stmt.Assignments, sqlAssignment{Column: part[0].Text, Expr: part[2:]})
+	}
+	if len(stmt.Assignments) == 0 {
+		return stmt, fmt.Errorf("UPDATE %s: no assignments", table)
+	}
+
+	if end >= 0 {
+		parseSQLWhere(&stmt, tokens[end+1:])
+	}
+	return stmt, nil
+}
+
+func parseSQLDelete(tokens []sqlToken) (sqlStatement, error) {
+	stmt := sqlStatement{Kind: "DELETE"}
+	if len(tokens) < 2 || !isSQLKeyword(tokens[1], "FROM") {
+		return stmt, fmt.Errorf("DELETE: expected FROM")
+	}
+
+	table, pos, err := parseSQLTableName(tokens, 2)
+	if err != nil {
+		return stmt, fmt.Errorf("DELETE: %w", err)
+	}
+	stmt.Table = table
+
+	if pos < len(tokens) {
+		if !isSQLKeyword(tokens[pos], "WHERE") {
+			return stmt, fmt.Errorf("DELETE FROM %s: unexpected %q", table, tokens[pos].Text)
+		}
+		parseSQLWhere(&stmt, tokens[pos+1:])
+	}
+	return stmt, nil
+}
+
+func parseSQLInsert(tokens []sqlToken) (sqlStatement, error) {
+	stmt := sqlStatement{Kind: "INSERT"}
+	if len(tokens) < 2 || !isSQLKeyword(tokens[1], "INTO") {
+		return stmt, fmt.Errorf("INSERT: expected INTO")
+	}
+
+	table, pos, err := parseSQLTableName(tokens, 2)
+	if err != nil {
+		return stmt, fmt.Errorf("INSERT: %w", err)
+	}
+	stmt.Table = table
+
+	if pos >= len(tokens) || !isSQLPunct(tokens[pos], "(") {
+		return stmt, fmt.Errorf("INSERT INTO %s: an explicit column list is required", table)
+	}
+	for pos++; pos < len(tokens) && !isSQLPunct(tokens[pos], ")"); pos++ {
+		if tokens[pos].Kind == sqlTokenIdent {
+			stmt.Columns = append(stmt.Columns, tokens[pos].Text)
+		}
+	}
+	return stmt, nil
+}
+
+// parseSQLWhere splits a WHERE clause into AND-ed predicates
+func parseSQLWhere(stmt *sqlStatement, tokens []sqlToken) {
+	stmt.HasWhere = len(tokens) > 0
+	stmt.WhereTokens = tokens
+
+	for _, tok := range topLevelSQLTokens(tokens) {
+		if isSQLKeyword(tok, "OR") {
+			stmt.WhereHasOr = true
+		}
+	}
+
+	betweenOpen := false
+	parts := splitSQLTopLevel(tokens, func(tok sqlToken) bool {
+		if isSQLKeyword(tok, "BETWEEN") {
+			betweenOpen = true
+		}
+		if isSQLKeyword(tok, "AND") {
+			if betweenOpen {
+				betweenOpen = false
+				return false
+			}
+			return true
+		}
+		return false
+	})
+
+	for _, part := range parts {
+		stmt.Where = append(stmt.Where, parseSQLPredicate(part))
+	}
+}
+
+// parseSQLPredicate recognises "column = literal" and "column IN (literals)"
+func parseSQLPredicate(tokens []sqlToken) sqlPredicate {
+	// Unwrap "( predicate )"
+	for len(tokens) >= 2 && isSQLPunct(tokens[0], "(") && matchingSQLParen(tokens, 0) == len(tokens)-1 {
+		tokens = tokens[1 : len(tokens)-1]
+	}
+
+	if len(tokens) >= 3 && tokens[0].Kind == sqlTokenIdent && isSQLPunct(tokens[1], ".") && tokens[2].Kind == sqlTokenIdent {
+		tokens = tokens[2:]
+	}
+	if len(tokens) < 3 || tokens[0].Kind != sqlTokenIdent {
+		return sqlPredicate{Op: "other"}
+	}
+
+	column := tokens[0].Text
+	if isSQLPunct(tokens[1], "=") && len(tokens) == 3 && isSQLLiteral(tokens[2]) {
+		return sqlPredicate{Column: column, Op: "=", Values: tokens[2:]}
+	}
+	if isSQLKeyword(tokens[1], "IN") && isSQLPunct(tokens[2], "(") && matchingSQLParen(tokens, 2) == len(tokens)-1 {
+		var values []sqlToken
+		for _, tok := range tokens[3 : len(tokens)-1] {
+			if isSQLPunct(tok, ",") {
+				continue
+			}
+			if !isSQLLiteral(tok) {
+				return sqlPredicate{Column: column, Op: "other"}
+			}
+			values = append(values, tok)
+		}
+		if len(values) > 0 {
+			return sqlPredicate{Column: column, Op: "IN", Values: values}
+		}
+	}
+	return sqlPredicate{Column: column, Op: "other"}
+}
+
+func isSQLLiteral(tok sqlToken) bool {
+	return tok.Kind == sqlTokenString || tok.Kind == sqlTokenNumber
+}
+
+// indexSQLKeyword returns the index of the first top-level keyword kw at or after start
+func indexSQLKeyword(tokens []sqlToken, start int, kw string) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		if isSQLPunct(tokens[i], "(") {
+			depth++
+		} else if isSQLPunct(tokens[i], ")") {
+			depth--
+		}
+		if depth == 0 && isSQLKeyword(tokens[i], kw) {
+			return i
+		}
+	}
+	return -1
+}
+
+// topLevelSQLTokens returns the tokens that are not inside parentheses
+func topLevelSQLTokens(tokens []sqlToken) []sqlToken {
+	var result []sqlToken
+	depth := 0
+	for _, tok := range tokens {
+		if isSQLPunct(tok, "(") {
+			depth++
+			continue
+		}
+		if isSQLPunct(tok, ")") {
+			depth--
+			continue
+		}
+		if depth == 0 {
+			result = append(result, tok)
+		}
+	}
+	return result
+}
+
+// splitSQLTopLevel splits tokens on top-level separators matched by isSep
+func splitSQLTopLevel(tokens []sqlToken, isSep func(sqlToken) bool) [][]sqlToken {
+	var parts [][]sqlToken
+	depth, start := 0, 0
+	for i, tok := range tokens {
+		if tok.Kind == sqlTokenPunct {
+			switch tok.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+		}
+		if depth == 0 && isSep(tok) {
+			if i > start {
+				parts = append(parts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
+
+// matchingSQLParen returns the index of the ")" closing tokens[open], or -1
+func matchingSQLParen(tokens []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		if tokens[i].Kind != sqlTokenPunct {
+			continue
+		}
+		switch tokens[i].Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
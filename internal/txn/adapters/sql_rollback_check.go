@@ -0,0 +1,551 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"buddy/internal/txn/domain"
+)
+
+// The rollback checker replays generated deploy and rollback SQL against a tiny
+// in-memory copy of the workflow_execution and transfer rows described by the
+// TransactionResults, and proves that rollback puts back what deploy changed.
+//
+// Values the simulator cannot compute (NOW(), data->>'$.x', arithmetic, rows or
+// JSON documents that were not fetched) are tracked as unknown and never cause
+// a failure on their own; the check only fails on a provable difference.
+
+// simUnknown marks a value the simulator cannot compute
+type simUnknown struct{}
+
+// simRow is one modeled row. partial marks JSON columns whose original document
+// was not fetched, so only the keys seeded from other columns are known.
+type simRow struct {
+	table   string
+	key     string
+	values  map[string]any
+	partial map[string]bool
+}
+
+// simDatabase holds the modeled rows of one target database
+type simDatabase struct {
+	targetDB string
+	rows     []*simRow
+}
+
+// rollbackCheckedColumns are the columns rollback must restore, per table
+var rollbackCheckedColumns = map[string][]string{
+	"workflow_execution": {"state", "attempt", "data"},
+	"transfer":           {"status", "properties"},
+}
+
+// CheckRollbackConsistency simulates deploy followed by rollback for every target
+// database and returns an error describing the first row whose state, attempt or
+// data (status or properties for transfer rows) is not restored.
+func CheckRollbackConsistency(results []domain.TransactionResult, statements domain.SQLStatements) error {
+	groups := []struct {
+		targetDB string
+		deploy   []string
+		rollback []string
+	}{
+		{"PC", statements.PCDeployStatements, statements.PCRollbackStatements},
+		{"PE", statements.PEDeployStatements, statements.PERollbackStatements},
+		{"PPE", statements.PPEDeployStatements, statements.PPERollbackStatements},
+		{"RPP", statements.RPPDeployStatements, statements.RPPRollbackStatements},
+	}
+
+	for _, group := range groups {
+		if len(group.deploy) == 0 && len(group.rollback) == 0 {
+			continue
+		}
+
+		db := newSimDatabase(group.targetDB, results)
+		if len(db.rows) == 0 {
+			continue
+		}
+		original := db.snapshot()
+
+		if err := db.apply(group.deploy); err != nil {
+			return fmt.Errorf("%s deploy: %w", group.targetDB, err)
+		}
+		if err := db.apply(group.rollback); err != nil {
+			return fmt.Errorf("%s rollback: %w", group.targetDB, err)
+		}
+		if err := db.compare(original); err != nil {
+			return fmt.Errorf("%s rollback does not invert deploy: %w", group.targetDB, err)
+		}
+	}
+	return nil
+}
+
+// newSimDatabase models the rows of targetDB that the results describe
+func newSimDatabase(targetDB string, results []domain.TransactionResult) *simDatabase {
+	db := &simDatabase{targetDB: targetDB}
+	seen := make(map[string]bool)
+
+	addWorkflow := func(wf domain.WorkflowInfo) {
+		if wf.RunID == "" || seen["workflow_execution/"+wf.RunID] {
+			return
+		}
+		seen["workflow_execution/"+wf.RunID] = true
+		db.rows = append(db.rows, newSimWorkflowRow(wf))
+	}
+
+	for _, result := range results {
+		switch targetDB {
+		case "PE":
+			if result.PaymentEngine == nil {
+				continue
+			}
+			addWorkflow(result.PaymentEngine.Workflow)
+			if transfer := result.PaymentEngine.Transfers; transfer.TransactionID != "" && !seen["transfer/"+transfer.TransactionID] {
+				seen["transfer/"+transfer.TransactionID] = true
+				db.rows = append(db.rows, newSimTransferRow(transfer))
+			}
+		case "PC":
+			if result.PaymentCore == nil {
+				continue
+			}
+			addWorkflow(result.PaymentCore.InternalAuth.Workflow)
+			addWorkflow(result.PaymentCore.InternalCapture.Workflow)
+			addWorkflow(result.PaymentCore.ExternalTransfer.Workflow)
+		case "RPP":
+			if result.RPPAdapter == nil {
+				continue
+			}
+			for _, wf := range result.RPPAdapter.Workflow {
+				addWorkflow(wf)
+			}
+		case "PPE":
+			if result.PartnerpayEngine == nil {
+				continue
+			}
+			addWorkflow(result.PartnerpayEngine.Workflow)
+		}
+	}
+	return db
+}
+
+func newSimWorkflowRow(wf domain.WorkflowInfo) *simRow {
+	row := &simRow{
+		table: "workflow_execution",
+		key:   "run_id=" + wf.RunID,
+		values: map[string]any{
+			"run_id":        wf.RunID,
+			"workflow_id":   wf.WorkflowID,
+			"state":         wf.State,
+			"attempt":       float64(wf.Attempt),
+			"prev_trans_id": wf.PrevTransID,
+		},
+		partial: make(map[string]bool),
+	}
+
+	// The engine keeps data.State in step with the state column, so a row whose
+	// data was not fetched still has a known $.State
+	row.values["data"] = parseSimDocument(wf.Data)
+	if wf.Data == "" {
+		row.partial["data"] = true
+		if state, err := strconv.ParseFloat(wf.State, 64); err == nil {
+			row.values["data"] = map[string]any{"State": state}
+		}
+	}
+	return row
+}
+
+func newSimTransferRow(transfer domain.PETransfersInfo) *simRow {
+	row := &simRow{
+		table: "transfer",
+		key:   "transaction_id=" + transfer.TransactionID,
+		values: map[string]any{
+			"transaction_id": transfer.TransactionID,
+			"status":         transfer.Status,
+			"external_id":    transfer.ExternalID,
+			"reference_id":   transfer.ReferenceID,
+			"updated_at":     transfer.UpdatedAt,
+			"properties":     parseSimDocument(transfer.Properties),
+		},
+		partial: make(map[string]bool),
+	}
+	if transfer.Status == "" {
+		row.values["status"] = simUnknown{}
+	}
+	if transfer.Properties == "" {
+		row.partial["properties"] = true
+	}
+	return row
+}
+
+// parseSimDocument decodes a JSON object column; anything else is unknown
+func parseSimDocument(raw string) any {
+	if raw == "" {
+		return map[string]any{}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return simUnknown{}
+	}
+	return doc
+}
+
+// snapshot deep-copies the checked columns of every row
+func (db *simDatabase) snapshot() []map[string]any {
+	snapshot := make([]map[string]any, len(db.rows))
+	for i, row := range db.rows {
+		snapshot[i] = make(map[string]any)
+		for _, column := range rollbackCheckedColumns[row.table] {
+			snapshot[i][column] = cloneSimValue(row.values[column])
+		}
+	}
+	return snapshot
+}
+
+// apply runs every UPDATE in sqls against the modeled rows. Statements on
+// tables that are not modeled are skipped.
+func (db *simDatabase) apply(sqls []string) error {
+	for _, sql := range sqls {
+		statements, err := parseSQLStatements(sql)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range statements {
+			if _, modeled := rollbackCheckedColumns[stmt.Table]; !modeled {
+				continue
+			}
+			if stmt.Kind != "UPDATE" {
+				return fmt.Errorf("%s on %s cannot be simulated; only UPDATE is supported", stmt.Kind, stmt.Table)
+			}
+			for _, row := range db.rows {
+				if row.table == stmt.Table && row.matches(stmt.Where) {
+					row.update(stmt.Assignments)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compare reports the first checked column that differs from the snapshot
+func (db *simDatabase) compare(original []map[string]any) error {
+	for i, row := range db.rows {
+		for _, column := range rollbackCheckedColumns[row.table] {
+			want, got := original[i][column], row.values[column]
+
+			equal := simEqual(want, got)
+			if row.partial[column] {
+				equal = simEqualKnownKeys(want, got)
+			}
+			if !equal {
+				return fmt.Errorf("%s %s: %s is %s after rollback, was %s",
+					row.table, row.key, column, formatSimValue(got), formatSimValue(want))
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the row can satisfy every pinned predicate. Columns
+// the row does not model, and non-literal predicates, are assumed to match.
+func (row *simRow) matches(predicates []sqlPredicate) bool {
+	for _, predicate := range predicates {
+		if predicate.Op != "=" && predicate.Op != "IN" {
+			continue
+		}
+		value, modeled := row.values[predicate.Column]
+		if !modeled {
+			continue
+		}
+		matched := false
+		for _, literal := range predicate.Values {
+			if simEqual(value, evalSimLiteral(literal)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// update applies SET assignments left to right, as MySQL does, so later
+// expressions see earlier assignments
+func (row *simRow) update(assignments []sqlAssignment) {
+	for _, assignment := range assignments {
+		row.values[assignment.Column] = evalSimExpr(assignment.Expr, row)
+	}
+}
+
+// evalSimExpr evaluates literals, column references and JSON_* calls
+func evalSimExpr(tokens []sqlToken, row *simRow) any {
+	switch {
+	case len(tokens) == 1:
+		tok := tokens[0]
+		if tok.Kind == sqlTokenIdent {
+			switch {
+			case isSQLKeyword(tok, "NULL"):
+				return nil
+			case isSQLKeyword(tok, "TRUE"):
+				return true
+			case isSQLKeyword(tok, "FALSE"):
+				return false
+			}
+			if value, ok := row.values[tok.Text]; ok {
+				return cloneSimValue(value)
+			}
+			return simUnknown{}
+		}
+		return evalSimLiteral(tok)
+	case len(tokens) == 2 && isSQLPunct(tokens[0], "-") && tokens[1].Kind == sqlTokenNumber:
+		if value, ok := evalSimLiteral(tokens[1]).(float64); ok {
+			return -value
+		}
+	case len(tokens) >= 3 && tokens[0].Kind == sqlTokenIdent && isSQLPunct(tokens[1], "(") && matchingSQLParen(tokens, 1) == len(tokens)-1:
+		var args []any
+		for _, arg := range splitSQLTopLevel(tokens[2:len(tokens)-1], func(tok sqlToken) bool { return isSQLPunct(tok, ",") }) {
+			args = append(args, evalSimExpr(arg, row))
+		}
+		return evalSimFunction(strings.ToUpper(tokens[0].Text), args)
+	}
+	return simUnknown{}
+}
+
+func evalSimLiteral(tok sqlToken) any {
+	switch tok.Kind {
+	case sqlTokenString:
+		return tok.Text
+	case sqlTokenNumber:
+		if value, err := strconv.ParseFloat(tok.Text, 64); err == nil {
+			return value
+		}
+	}
+	return simUnknown{}
+}
+
+func evalSimFunction(name string, args []any) any {
+	switch name {
+	case "JSON_OBJECT":
+		if len(args)%2 != 0 {
+			return simUnknown{}
+		}
+		object := make(map[string]any)
+		for i := 0; i < len(args); i += 2 {
+			key, ok := args[i].(string)
+			if !ok {
+				return simUnknown{}
+			}
+			object[key] = args[i+1]
+		}
+		return object
+	case "JSON_ARRAY":
+		return append([]any{}, args...)
+	case "JSON_SET", "JSON_REPLACE", "JSON_INSERT":
+		if len(args) < 3 || len(args)%2 != 1 {
+			return simUnknown{}
+		}
+		doc := args[0]
+		for i := 1; i < len(args); i += 2 {
+			doc = setSimPath(doc, args[i], args[i+1], name)
+		}
+		return doc
+	case "JSON_REMOVE":
+		if len(args) < 2 {
+			return simUnknown{}
+		}
+		doc := args[0]
+		for _, path := range args[1:] {
+			doc = removeSimPath(doc, path)
+		}
+		return doc
+	}
+	return simUnknown{}
+}
+
+// parseSimPath splits a "$.A.B" JSON path; array paths are not modeled
+func parseSimPath(path any) ([]string, bool) {
+	text, ok := path.(string)
+	if !ok || !strings.HasPrefix(text, "$.") || strings.ContainsAny(text, "[*") {
+		return nil, false
+	}
+	parts := strings.Split(text[2:], ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(part, `"`)
+		if parts[i] == "" {
+			return nil, false
+		}
+	}
+	return parts, true
+}
+
+// setSimPath follows MySQL semantics: a missing parent makes the call a no-op,
+// JSON_REPLACE only overwrites and JSON_INSERT only adds
+func setSimPath(doc, path, value any, mode string) any {
+	parts, ok := parseSimPath(path)
+	object, isObject := doc.(map[string]any)
+	if !ok || !isObject {
+		return simUnknown{}
+	}
+
+	parent := object
+	for _, part := range parts[:len(parts)-1] {
+		switch next := parent[part].(type) {
+		case map[string]any:
+			parent = next
+		case simUnknown:
+			return simUnknown{}
+		default:
+			return object
+		}
+	}
+
+	leaf := parts[len(parts)-1]
+	_, exists := parent[leaf]
+	if (mode == "JSON_REPLACE" && !exists) || (mode == "JSON_INSERT" && exists) {
+		return object
+	}
+	parent[leaf] = value
+	return object
+}
+
+func removeSimPath(doc, path any) any {
+	parts, ok := parseSimPath(path)
+	object, isObject := doc.(map[string]any)
+	if !ok || !isObject {
+		return simUnknown{}
+	}
+
+	parent := object
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := parent[part].(map[string]any)
+		if !ok {
+			return object
+		}
+		parent = next
+	}
+	delete(parent, parts[len(parts)-1])
+	return object
+}
+
+func cloneSimValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for key, item := range v {
+			clone[key] = cloneSimValue(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneSimValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+// simEqual compares two values the way the database would see them: unknown
+// matches anything, a JSON null equals an absent key and numbers compare equal
+// to their string form
+func simEqual(a, b any) bool {
+	if _, unknown := a.(simUnknown); unknown {
+		return true
+	}
+	if _, unknown := b.(simUnknown); unknown {
+		return true
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key := range av {
+			if !simEqual(av[key], bv[key]) {
+				return false
+			}
+		}
+		for key := range bv {
+			if _, seen := av[key]; !seen && !simEqual(nil, bv[key]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !simEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return b == nil
+	}
+
+	as, aok := simScalarText(a)
+	bs, bok := simScalarText(b)
+	return aok && bok && as == bs
+}
+
+// simEqualKnownKeys compares only the keys present in the original document
+func simEqualKnownKeys(original, got any) bool {
+	want, ok := original.(map[string]any)
+	if !ok {
+		return simEqual(original, got)
+	}
+	have, ok := got.(map[string]any)
+	if !ok {
+		return simEqual(original, got)
+	}
+	for key := range want {
+		if !simEqual(want[key], have[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func simScalarText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func formatSimValue(value any) string {
+	if _, unknown := value.(simUnknown); unknown {
+		return "<unknown>"
+	}
+	if object, ok := value.(map[string]any); ok {
+		// Sort keys so messages are stable
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%q:%s", key, formatSimValue(object[key])))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
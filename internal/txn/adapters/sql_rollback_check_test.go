@@ -0,0 +1,219 @@
+package adapters
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func TestCheckRollbackConsistency(t *testing.T) {
+	result := domain.TransactionResult{
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Transfers: domain.PETransfersInfo{TransactionID: "txn-1", Status: "PROCESSING", Properties: `{"Channel": "DUITNOW"}`},
+			Workflow:  domain.WorkflowInfo{WorkflowID: "workflow_transfer_payment", RunID: "run-1", State: "210", Data: `{"State": 210, "Amount": 100}`},
+		},
+	}
+	deploy := "UPDATE workflow_execution SET state = 221, attempt = 1, data = JSON_SET(data, '$.StreamMessage', JSON_OBJECT('Status', 'FAILED'), '$.State', 221) WHERE run_id = 'run-1' AND state = 210;"
+
+	tests := []struct {
+		name       string
+		statements domain.SQLStatements
+		wantErr    string
+	}{
+		{
+			name: "exact inverse",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{deploy},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET state = 210, attempt = 0, data = JSON_SET(data, '$.StreamMessage', NULL, '$.State', 210) WHERE run_id = 'run-1';"},
+			},
+		},
+		{
+			name: "JSON_REMOVE inverse",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{deploy},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET state = 210, attempt = 0, data = JSON_REMOVE(JSON_SET(data, '$.State', 210), '$.StreamMessage') WHERE run_id = 'run-1';"},
+			},
+		},
+		{
+			name: "rollback forgets attempt",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{deploy},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET state = 210, data = JSON_SET(data, '$.StreamMessage', NULL, '$.State', 210) WHERE run_id = 'run-1';"},
+			},
+			wantErr: "attempt is 1 after rollback, was 0",
+		},
+		{
+			name: "rollback leaves deploy data behind",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{deploy},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET state = 210, attempt = 0, data = JSON_SET(data, '$.State', 210) WHERE run_id = 'run-1';"},
+			},
+			wantErr: "data is",
+		},
+		{
+			name: "rollback guarded by wrong state never runs",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{deploy},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET state = 210, attempt = 0, data = JSON_SET(data, '$.StreamMessage', NULL, '$.State', 210) WHERE run_id = 'run-1' AND state = 222;"},
+			},
+			wantErr: "state is 221 after rollback, was \"210\"",
+		},
+		{
+			name: "transfer properties restored",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{"UPDATE transfer SET properties = JSON_SET(properties, '$.AuthorisationID', 'auth-1') WHERE transaction_id = 'txn-1';"},
+				PERollbackStatements: []string{"UPDATE transfer SET properties = JSON_REMOVE(properties, '$.AuthorisationID') WHERE transaction_id = 'txn-1';"},
+			},
+		},
+		{
+			name: "transfer status not restored",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{"UPDATE transfer SET status = 'FAILED' WHERE transaction_id = 'txn-1';"},
+				PERollbackStatements: []string{"UPDATE transfer SET updated_at = NOW() WHERE transaction_id = 'txn-1';"},
+			},
+			wantErr: "status is \"FAILED\" after rollback, was \"PROCESSING\"",
+		},
+		{
+			name: "computed values are not held against the rollback",
+			statements: domain.SQLStatements{
+				PEDeployStatements:   []string{"UPDATE workflow_execution SET prev_trans_id = data->>'$.StreamMessage.ID', data = JSON_SET(data, '$.Ref', data->>'$.X') WHERE run_id = 'run-1';"},
+				PERollbackStatements: []string{"UPDATE workflow_execution SET data = JSON_SET(data, '$.Ref', NULL) WHERE run_id = 'run-1';"},
+			},
+		},
+		{
+			name: "rows that are not modeled are ignored",
+			statements: domain.SQLStatements{
+				PEDeployStatements: []string{"UPDATE workflow_execution SET state = 999 WHERE run_id = 'someone-else';"},
+				PCDeployStatements: []string{"UPDATE internal_transaction SET status = 'FAILED' WHERE tx_id = 'tx-1';"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRollbackConsistency([]domain.TransactionResult{result}, tt.statements)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected rollback to be consistent, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckRollbackConsistency_UnfetchedDataKeepsState(t *testing.T) {
+	result := domain.TransactionResult{
+		RPPAdapter: &domain.RPPAdapterInfo{
+			Workflow: []domain.WorkflowInfo{{WorkflowID: "wf_ct_cashout", RunID: "rpp-1", State: "210"}},
+		},
+	}
+	statements := domain.SQLStatements{
+		RPPDeployStatements:   []string{"UPDATE workflow_execution SET state = 222, data = JSON_SET(data, '$.State', 222, '$.Extra', 1) WHERE run_id = 'rpp-1';"},
+		RPPRollbackStatements: []string{"UPDATE workflow_execution SET state = 210, data = JSON_SET(data, '$.State', 222) WHERE run_id = 'rpp-1';"},
+	}
+
+	err := CheckRollbackConsistency([]domain.TransactionResult{result}, statements)
+	if err == nil || !strings.Contains(err.Error(), `"State":222`) {
+		t.Errorf("expected data.State mismatch to be reported, got %v", err)
+	}
+}
+
+// resultMatchingRule returns a result that satisfies every eq/in condition of
+// rule, with each workflow's data carrying its state as the engine would
+func resultMatchingRule(t *testing.T, rule CaseRule) domain.TransactionResult {
+	t.Helper()
+	result := populatedResult(rule.CaseType)
+
+	for _, condition := range rule.Conditions {
+		value := condition.Value
+		switch condition.Operator {
+		case "eq":
+		case "in":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				continue
+			}
+			value = values[0]
+		default:
+			continue
+		}
+		setResultField(t, reflect.ValueOf(&result).Elem(), strings.Split(condition.FieldPath, "."), value)
+	}
+
+	syncData := func(wf *domain.WorkflowInfo) {
+		wf.Data = fmt.Sprintf(`{"State": %s}`, wf.State)
+	}
+	syncData(&result.PaymentEngine.Workflow)
+	syncData(&result.PaymentCore.InternalAuth.Workflow)
+	syncData(&result.PaymentCore.InternalCapture.Workflow)
+	syncData(&result.PaymentCore.ExternalTransfer.Workflow)
+	syncData(&result.PartnerpayEngine.Workflow)
+	for i := range result.RPPAdapter.Workflow {
+		syncData(&result.RPPAdapter.Workflow[i])
+	}
+	return result
+}
+
+func setResultField(t *testing.T, current reflect.Value, path []string, value interface{}) {
+	t.Helper()
+	for i, part := range path {
+		if current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				current.Set(reflect.New(current.Type().Elem()))
+			}
+			current = current.Elem()
+		}
+		current = current.FieldByName(part)
+		if current.Kind() == reflect.Slice {
+			// Conditions on a slice apply to any element; use the first
+			setResultField(t, current.Index(0), path[i+1:], value)
+			return
+		}
+	}
+
+	switch current.Kind() {
+	case reflect.String:
+		current.SetString(fmt.Sprint(value))
+	case reflect.Int:
+		if n, ok := value.(int); ok {
+			current.SetInt(int64(n))
+		}
+	}
+}
+
+func TestSQLTemplates_RollbackInvertsDeploy(t *testing.T) {
+	checked := 0
+	for _, rule := range getDefaultSOPRules() {
+		templateFunc, ok := sqlTemplates[rule.CaseType]
+		if !ok {
+			continue
+		}
+		result := resultMatchingRule(t, rule)
+		ticket := acceptingTemplate(rule.CaseType, templateFunc)(result)
+		if ticket == nil {
+			continue
+		}
+		checked++
+
+		statements, err := generateSQLFromTicket(*ticket)
+		if err != nil {
+			t.Errorf("%s: failed to generate SQL: %v", rule.CaseType, err)
+			continue
+		}
+		if err := CheckRollbackConsistency([]domain.TransactionResult{result}, statements); err != nil {
+			t.Errorf("%s: %v", rule.CaseType, err)
+		}
+	}
+
+	if checked == 0 {
+		t.Fatal("expected at least one template to produce SQL")
+	}
+	t.Logf("checked rollback of %d templates", checked)
+}
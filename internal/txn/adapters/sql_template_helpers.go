@@ -13,7 +13,17 @@ import (
 //
 // Returns empty string if no matching workflow is found.
 func getRPPWorkflowRunIDByCriteria(workflows []domain.WorkflowInfo, workflowID, state string, attempt int) string {
-	for _, wf := range workflows {
+	if wf := getRPPWorkflowByCriteria(workflows, workflowID, state, attempt); wf != nil {
+		return wf.RunID
+	}
+	return ""
+}
+
+// getRPPWorkflowByCriteria returns the first workflow matching the same criteria as
+// getRPPWorkflowRunIDByCriteria, for templates that also need its attempt or data.
+// Returns nil if no matching workflow is found.
+func getRPPWorkflowByCriteria(workflows []domain.WorkflowInfo, workflowID, state string, attempt int) *domain.WorkflowInfo {
+	for i, wf := range workflows {
 		// Check workflow_id if specified
 		if workflowID != "" && wf.WorkflowID != workflowID {
 			continue
@@ -27,8 +37,8 @@ func getRPPWorkflowRunIDByCriteria(workflows []domain.WorkflowInfo, workflowID,
 			continue
 		}
 		// All criteria matched
-		return wf.RunID
+		return &workflows[i]
 	}
 	// No matching workflow found
-	return ""
+	return nil
 }
@@ -8,13 +8,18 @@ type TemplateFunc func(domain.TransactionResult) *domain.DMLTicket
 // sqlTemplates maps SOP cases to their DML tickets
 var sqlTemplates = map[domain.Case]TemplateFunc{}
 
+// templateExpectations maps SOP cases to the states a transaction reaches once
+// the case's deploy SQL has run; "txn verify" checks them after execution
+var templateExpectations = map[domain.Case][]FixExpectation{}
+
 // init initializes all template registrations
 func init() {
-	registerPCTemplates(sqlTemplates)
-	registerPEBasicTemplates(sqlTemplates)
-	registerPEAdvancedTemplates(sqlTemplates)
-	registerRPPBasicTemplates(sqlTemplates)
-	registerRPPAdvancedTemplates(sqlTemplates)
-	registerPPETemplates(sqlTemplates)
-	registerCrossDomainTemplates(sqlTemplates)
+	registerPCTemplates(sqlTemplates, templateExpectations)
+	registerPEBasicTemplates(sqlTemplates, templateExpectations)
+	registerPEAdvancedTemplates(sqlTemplates, templateExpectations)
+	registerRPPBasicTemplates(sqlTemplates, templateExpectations)
+	registerRPPAdvancedTemplates(sqlTemplates, templateExpectations)
+	registerPPETemplates(sqlTemplates, templateExpectations)
+	registerFastTemplates(sqlTemplates, templateExpectations)
+	registerCrossDomainTemplates(sqlTemplates, templateExpectations)
 }
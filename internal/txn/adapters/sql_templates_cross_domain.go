@@ -3,10 +3,16 @@ package adapters
 import "buddy/internal/txn/domain"
 
 // registerCrossDomainTemplates registers cross-domain templates
-func registerCrossDomainTemplates(templates map[domain.Case]TemplateFunc) {
+func registerCrossDomainTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CaseThoughtMachineFalseNegative] = thoughtMachineFalseNegative
+	expectations[domain.CaseThoughtMachineFalseNegative] = expectStates("PE capture completed", targetStates("PaymentEngine.Workflow.State", peCaptureCompletedStates...))
 	templates[domain.CasePeCaptureProcessingPcCaptureFailedRppSuccess] = peCaptureProcessingPcCaptureFailedRppSuccess
+	expectations[domain.CasePeCaptureProcessingPcCaptureFailedRppSuccess] = expectStates("PC capture retried", targetStates("PaymentCore.InternalCapture.Workflow.State", pcInternalRetriedStates...))
 	templates[domain.CaseCashoutRpp210Pe220Pc201] = GetDMLTicketForCashoutRpp210Pe220Pc201
+	expectations[domain.CaseCashoutRpp210Pe220Pc201] = []FixExpectation{
+		{Description: "RPP transfer resumed", Conditions: []RuleCondition{targetStates("RPPAdapter.Workflow.State", rppResumedStates...)}},
+		{Description: "PE transfer rejected", Conditions: []RuleCondition{targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...)}},
+	}
 }
 
 // thoughtMachineFalseNegative handles ThoughtMachine false negative case (PE + PC)
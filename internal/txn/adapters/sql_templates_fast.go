@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"buddy/internal/txn/domain"
+	"buddy/internal/utils"
+)
+
+// registerFastTemplates registers templates for Singapore FAST adapter failures
+func registerFastTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
+	templates[domain.CaseFastCashoutPendingPe220Pc201] = fastCashoutPendingPe220Pc201
+	expectations[domain.CaseFastCashoutPendingPe220Pc201] = expectStates("PC external transfer rejected", targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalRejectedStates...))
+	templates[domain.CaseFastCashoutErroneousPe220Pc201] = fastCashoutErroneousPe220Pc201
+	expectations[domain.CaseFastCashoutErroneousPe220Pc201] = expectStates("PC external transfer rejected", targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalRejectedStates...))
+	templates[domain.CaseFastCashinUnknownPe220] = fastCashinUnknownPe220
+	expectations[domain.CaseFastCashinUnknownPe220] = expectStates("PE collection rejected", targetStates("PaymentEngine.Workflow.State", peCollectionRejectedStates...))
+	templates[domain.CaseFastCashinErroneousPe220] = fastCashinErroneousPe220
+	expectations[domain.CaseFastCashinErroneousPe220] = expectStates("PE collection rejected", targetStates("PaymentEngine.Workflow.State", peCollectionRejectedStates...))
+}
+
+// fastCashoutPendingPe220Pc201 handles a FAST cashout left in StPending while
+// PE waits at 220 and PC at 201. The deploy SQL should only be raised once FAST
+// operations confirm the payment was not settled.
+func fastCashoutPendingPe220Pc201(result domain.TransactionResult) *domain.DMLTicket {
+	return rejectFastCashoutPC(result, domain.CaseFastCashoutPendingPe220Pc201)
+}
+
+// fastCashoutErroneousPe220Pc201 handles a FAST cashout that ended in
+// StErraneous while PE waits at 220 and PC at 201
+func fastCashoutErroneousPe220Pc201(result domain.TransactionResult) *domain.DMLTicket {
+	return rejectFastCashoutPC(result, domain.CaseFastCashoutErroneousPe220Pc201)
+}
+
+// fastCashinUnknownPe220 handles a FAST cashin in StAuthUnknown or
+// StConfirmUnknown while the PE collection waits at 220
+func fastCashinUnknownPe220(result domain.TransactionResult) *domain.DMLTicket {
+	return rejectFastCashinCollection(result, domain.CaseFastCashinUnknownPe220)
+}
+
+// fastCashinErroneousPe220 handles a FAST cashin that ended in StErraneous
+// while the PE collection waits at 220
+func fastCashinErroneousPe220(result domain.TransactionResult) *domain.DMLTicket {
+	return rejectFastCashinCollection(result, domain.CaseFastCashinErroneousPe220)
+}
+
+// rejectFastCashoutPC fails the PC external transfer that is waiting for FAST,
+// so PC publishes the failure and PE reverses the transfer
+func rejectFastCashoutPC(result domain.TransactionResult, caseType domain.Case) *domain.DMLTicket {
+	if result.PaymentCore == nil || result.PaymentCore.ExternalTransfer.Workflow.RunID == "" {
+		return nil
+	}
+	runID := result.PaymentCore.ExternalTransfer.Workflow.RunID
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "PC",
+				SQLTemplate: "-- " + string(caseType) + "\n" +
+					"UPDATE workflow_execution\n" +
+					"SET state = 202, attempt = 1,\n" +
+					"    `data` = JSON_SET(`data`,\n" +
+					"      '$.StreamResp', JSON_OBJECT(\n" +
+					"        'TxID', '',\n" +
+					"        'Status', 'FAILED',\n" +
+					"        'ErrorCode', 'ADAPTER_ERROR',\n" +
+					"        'ExternalID', '',\n" +
+					"        'ErrorMessage', 'Reject from adapter'\n" +
+					"      ),\n" +
+					"      '$.State', 202)\n" +
+					"WHERE run_id IN (%s) AND workflow_id = 'external_payment_flow' AND state = 201 AND attempt = 0;",
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: runID, Type: "string"},
+				},
+			},
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "PC",
+				SQLTemplate: "UPDATE workflow_execution\n" +
+					"SET state = 201, attempt = 0,\n" +
+					"    `data` = JSON_SET(`data`, '$.StreamResp', %s, '$.State', 201)\n" +
+					"WHERE run_id IN (%s);",
+				Params: []domain.ParamInfo{
+					{Name: "stream_resp", Value: utils.GetRollbackJSONValue(result.PaymentCore.ExternalTransfer.Workflow.Data, "StreamResp", "NULL"), Type: "sql"},
+					{Name: "run_id", Value: runID, Type: "string"},
+				},
+			},
+		},
+		CaseType: caseType,
+	}
+}
+
+// rejectFastCashinCollection fails the PE collection that is waiting for FAST to authorise
+func rejectFastCashinCollection(result domain.TransactionResult, caseType domain.Case) *domain.DMLTicket {
+	if result.PaymentEngine == nil || result.PaymentEngine.Workflow.RunID == "" {
+		return nil
+	}
+	runID := result.PaymentEngine.Workflow.RunID
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "PE",
+				SQLTemplate: "-- " + string(caseType) + `
+UPDATE workflow_execution
+SET attempt = 1,
+    state = 221,
+    data = JSON_SET(
+      data,
+      '$.State', 221,
+      '$.StreamMessage.Status', 'FAILED',
+      '$.StreamMessage.ErrorMessage', 'MANUAL REJECT')
+WHERE run_id = %s
+AND workflow_id = 'workflow_transfer_collection'
+AND state = 220
+AND attempt = 0;`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: runID, Type: "string"},
+				},
+			},
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "PE",
+				SQLTemplate: `UPDATE workflow_execution
+SET attempt = 0,
+    state = 220,
+    data = JSON_SET(
+      data,
+      '$.State', 220,
+      '$.StreamMessage', %s)
+WHERE run_id = %s
+AND workflow_id = 'workflow_transfer_collection';`,
+				Params: []domain.ParamInfo{
+					{Name: "stream_message", Value: utils.GetRollbackJSONValue(result.PaymentEngine.Workflow.Data, "StreamMessage", "JSON_OBJECT()"), Type: "sql"},
+					{Name: "run_id", Value: runID, Type: "string"},
+				},
+			},
+		},
+		CaseType: caseType,
+	}
+}
@@ -1,10 +1,14 @@
 package adapters
 
-import "buddy/internal/txn/domain"
+import (
+	"buddy/internal/txn/domain"
+	"buddy/internal/utils"
+)
 
 // registerPCTemplates registers all Payment Core (PC) templates
-func registerPCTemplates(templates map[domain.Case]TemplateFunc) {
+func registerPCTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CasePcExternalPaymentFlow200_11] = pcExternalPaymentFlow200_11
+	expectations[domain.CasePcExternalPaymentFlow200_11] = expectStates("PC external transfer rejected", targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalRejectedStates...))
 }
 
 // pcExternalPaymentFlow200_11 handles PC external payment flow stuck at state 200, attempt 11
@@ -42,9 +46,10 @@ AND attempt = 11;`,
 					SQLTemplate: `UPDATE workflow_execution
 SET state = 200,
     attempt = 11,
-    data = JSON_SET(data, '$.State', 200)
+    data = JSON_SET(data, '$.StreamResp', %s, '$.State', 200)
 WHERE run_id = %s;`,
 					Params: []domain.ParamInfo{
+						{Name: "stream_resp", Value: utils.GetRollbackJSONValue(result.PaymentCore.ExternalTransfer.Workflow.Data, "StreamResp", "NULL"), Type: "sql"},
 						{Name: "run_id", Value: result.PaymentCore.ExternalTransfer.Workflow.RunID, Type: "string"},
 					},
 				},
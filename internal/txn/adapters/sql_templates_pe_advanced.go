@@ -6,12 +6,19 @@ import (
 )
 
 // registerPEAdvancedTemplates registers advanced Payment Engine (PE) templates
-func registerPEAdvancedTemplates(templates map[domain.Case]TemplateFunc) {
+func registerPEAdvancedTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CasePeStuck230RepublishPC] = peStuck230RepublishPC
+	expectations[domain.CasePeStuck230RepublishPC] = expectStates("PE capture completed", targetStates("PaymentEngine.Workflow.State", peCaptureCompletedStates...))
 	templates[domain.CasePeStuck300RppNotFound] = peStuck300RppNotFound
+	expectations[domain.CasePeStuck300RppNotFound] = expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))
 	templates[domain.CaseCashoutPe220Pc201Reject] = cashoutPe220Pc201Reject
+	expectations[domain.CaseCashoutPe220Pc201Reject] = expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))
 	templates[domain.CaseRpp210Pe220Pc201Reject] = rpp210Pe220Pc201Reject
+	expectations[domain.CaseRpp210Pe220Pc201Reject] = expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))
 	templates[domain.CasePe220Pc201Rpp0StuckInit] = pe220Pc201Rpp0StuckInit
+	expectations[domain.CasePe220Pc201Rpp0StuckInit] = expectStates("PC rejected and RPP failed",
+		targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalRejectedStates...),
+		targetStates("RPPAdapter.Workflow.State", "700"))
 }
 
 // peStuck230RepublishPC handles PE stuck at 230 - republish to PC
@@ -39,7 +46,7 @@ AND state = 900;`,
 					TargetDB: "PC",
 					SQLTemplate: `UPDATE workflow_execution
 SET state = 900,
-    attempt = 1,
+    attempt = 0,
     data = JSON_SET(data, '$.State', 900)
 WHERE run_id = %s
 AND workflow_id = 'internal_payment_flow'
@@ -99,7 +106,7 @@ SET state = 300,
 WHERE run_id = %s
 AND workflow_id = 'workflow_transfer_payment';`,
 					Params: []domain.ParamInfo{
-						{Name: "stream_message", Value: rollbackStreamMessage, Type: "string"},
+						{Name: "stream_message", Value: rollbackStreamMessage, Type: "sql"},
 						{Name: "run_id", Value: runID, Type: "string"},
 					},
 				},
@@ -137,12 +144,14 @@ func cashoutPe220Pc201Reject(result domain.TransactionResult) *domain.DMLTicket
 				{
 					TargetDB: "PE",
 					SQLTemplate: "UPDATE workflow_execution\n" +
-						"SET state = 220, attempt = 1, `data` = JSON_SET(\n" +
+						"SET state = 220, attempt = %s, `data` = JSON_SET(\n" +
 						"      `data`, '$.StreamMessage',\n" +
-						"      JSON_OBJECT(),\n" +
+						"      %s,\n" +
 						"   '$.State', 220)\n" +
 						"WHERE run_id IN (%s);",
 					Params: []domain.ParamInfo{
+						{Name: "attempt", Value: result.PaymentEngine.Workflow.Attempt, Type: "int"},
+						{Name: "stream_message", Value: utils.GetRollbackJSONValue(result.PaymentEngine.Workflow.Data, "StreamMessage", "JSON_OBJECT()"), Type: "sql"},
 						{Name: "run_id", Value: runID, Type: "string"},
 					},
 				},
@@ -181,12 +190,14 @@ func rpp210Pe220Pc201Reject(result domain.TransactionResult) *domain.DMLTicket {
 				{
 					TargetDB: "PE",
 					SQLTemplate: "UPDATE workflow_execution\n" +
-						"SET state = 220, attempt = 1, `data` = JSON_SET(\n" +
+						"SET state = 220, attempt = %s, `data` = JSON_SET(\n" +
 						"      `data`, '$.StreamMessage',\n" +
-						"      JSON_OBJECT(),\n" +
+						"      %s,\n" +
 						"   '$.State', 220)\n" +
 						"WHERE run_id IN (%s);",
 					Params: []domain.ParamInfo{
+						{Name: "attempt", Value: result.PaymentEngine.Workflow.Attempt, Type: "int"},
+						{Name: "stream_message", Value: utils.GetRollbackJSONValue(result.PaymentEngine.Workflow.Data, "StreamMessage", "JSON_OBJECT()"), Type: "sql"},
 						{Name: "run_id", Value: runID, Type: "string"},
 					},
 				},
@@ -245,9 +256,10 @@ func pe220Pc201Rpp0StuckInit(result domain.TransactionResult) *domain.DMLTicket
 			TargetDB: "PC",
 			SQLTemplate: "UPDATE workflow_execution\n" +
 				"SET state = 201, attempt = 0,\n" +
-				"    `data` = JSON_SET(`data`, '$.State', 201)\n" +
+				"    `data` = JSON_SET(`data`, '$.StreamResp', %s, '$.State', 201)\n" +
 				"WHERE run_id IN (%s);",
 			Params: []domain.ParamInfo{
+				{Name: "stream_resp", Value: utils.GetRollbackJSONValue(result.PaymentCore.ExternalTransfer.Workflow.Data, "StreamResp", "NULL"), Type: "sql"},
 				{Name: "run_id", Value: pcRunID, Type: "string"},
 			},
 		})
@@ -1,12 +1,22 @@
 package adapters
 
-import "buddy/internal/txn/domain"
+import (
+	"buddy/internal/txn/domain"
+	"buddy/internal/utils"
+)
 
 // registerPEBasicTemplates registers basic Payment Engine (PE) templates
-func registerPEBasicTemplates(templates map[domain.Case]TemplateFunc) {
+func registerPEBasicTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CasePeTransferPayment210_0] = peTransferPayment210_0
+	expectations[domain.CasePeTransferPayment210_0] = expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))
 	templates[domain.CasePeStuckAtLimitCheck102] = peStuckAtLimitCheck102
+	expectations[domain.CasePeStuckAtLimitCheck102] = expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))
 	templates[domain.CasePe2200FastCashinFailed] = pe2200FastCashinFailed
+	expectations[domain.CasePe2200FastCashinFailed] = expectStates("PE collection rejected", targetStates("PaymentEngine.Workflow.State", peCollectionRejectedStates...))
+	templates[domain.CasePeStuck223HystrixTimeout] = peStuck223HystrixTimeout
+	expectations[domain.CasePeStuck223HystrixTimeout] = append(
+		expectStates("PE capture completed", targetStates("PaymentEngine.Workflow.State", peCaptureCompletedStates...)),
+		expectStates("PE transfer rejected", targetStates("PaymentEngine.Workflow.State", peTransferRejectedStates...))...)
 }
 
 // peTransferPayment210_0 handles PE transfer payment stuck at state 210, attempt 0
@@ -120,17 +130,17 @@ func peStuckAtLimitCheck102(result domain.TransactionResult) *domain.DMLTicket {
 				SQLTemplate: "-- cashout_pe102_reject_rollback\n" +
 					"UPDATE workflow_execution\n" +
 					"SET state = 102,\n" +
-					"    attempt = 4,\n" +
+					"    attempt = %s,\n" +
 					"    `data` = JSON_SET(\n" +
 					"        `data`,\n" +
-					"        '$.StreamMessage', JSON_OBJECT(),\n" +
+					"        '$.StreamMessage', %s,\n" +
 					"        '$.State', 102,\n" +
 					"        '$.Properties.AuthorisationID', NULL\n" +
 					"    )\n" +
-					"WHERE run_id IN (\n" +
-					"    %s\n" +
-					");",
+					"WHERE run_id IN (%s);",
 				Params: []domain.ParamInfo{
+					{Name: "attempt", Value: result.PaymentEngine.Workflow.Attempt, Type: "int"},
+					{Name: "stream_message", Value: utils.GetRollbackJSONValue(result.PaymentEngine.Workflow.Data, "StreamMessage", "JSON_OBJECT()"), Type: "sql"},
 					{Name: "run_id", Value: runID, Type: "string"},
 				},
 			},
@@ -153,48 +163,62 @@ func peStuckAtLimitCheck102(result domain.TransactionResult) *domain.DMLTicket {
 
 // pe2200FastCashinFailed handles PE 220, attempt 0 - fast cashin failed
 func pe2200FastCashinFailed(result domain.TransactionResult) *domain.DMLTicket {
-	if runID := result.PaymentEngine.Workflow.RunID; runID != "" {
-		return &domain.DMLTicket{
-			Deploy: []domain.TemplateInfo{
-				{
-					TargetDB: "PE",
-					SQLTemplate: `-- pe_220_0_fast_cashin_failed
+	return rejectFastCashinCollection(result, domain.CasePe2200FastCashinFailed)
+}
+
+// peStuck223HystrixTimeout handles PE stuck at 220 or 223 after a Hystrix timeout left the
+// transition half saved. The workflow is reset to 221 (stTransferStreamPersisted) so it
+// replays the transition. The rollback restores the state, attempt and StreamMessage it had.
+func peStuck223HystrixTimeout(result domain.TransactionResult) *domain.DMLTicket {
+	if result.PaymentEngine == nil || result.PaymentEngine.Workflow.RunID == "" {
+		return nil
+	}
+	wf := result.PaymentEngine.Workflow
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "PE",
+				SQLTemplate: `-- pe_stuck_223_hystrix_timeout - Reset to previous good state
 UPDATE workflow_execution
-SET attempt = 1,
-    state = 221,
+SET state = 221,
+    attempt = 1,
     data = JSON_SET(
       data,
       '$.State', 221,
-      '$.StreamMessage.Status', 'FAILED',
-      '$.StreamMessage.ErrorMessage', 'MANUAL REJECT')
+      '$.StreamMessage', JSON_OBJECT()
+    )
 WHERE run_id = %s
-AND workflow_id = 'workflow_transfer_collection'
-AND state = 220
-AND attempt = 0;`,
-					Params: []domain.ParamInfo{
-						{Name: "run_id", Value: runID, Type: "string"},
-					},
+AND workflow_id = 'workflow_transfer_payment'
+AND state IN (220, 223);`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
 				},
 			},
-			Rollback: []domain.TemplateInfo{
-				{
-					TargetDB: "PE",
-					SQLTemplate: `UPDATE workflow_execution
-SET attempt = 0,
-    state = 220,
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "PE",
+				SQLTemplate: `-- pe_stuck_223_hystrix_timeout_rollback
+UPDATE workflow_execution
+SET state = %s,
+    attempt = %s,
     data = JSON_SET(
       data,
-      '$.State', 220,
-      '$.StreamMessage', JSON_OBJECT())
+      '$.State', %s,
+      '$.StreamMessage', %s
+    )
 WHERE run_id = %s
-AND workflow_id = 'workflow_transfer_collection';`,
-					Params: []domain.ParamInfo{
-						{Name: "run_id", Value: runID, Type: "string"},
-					},
+AND workflow_id = 'workflow_transfer_payment';`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
+					{Name: "state", Value: wf.State, Type: "int"},
+					{Name: "attempt", Value: wf.Attempt, Type: "int"},
+					{Name: "json_state", Value: wf.State, Type: "int"},
+					{Name: "stream_message", Value: utils.GetRollbackJSONValue(wf.Data, "StreamMessage", "JSON_OBJECT()"), Type: "sql"},
 				},
 			},
-			CaseType: domain.CasePe2200FastCashinFailed,
-		}
+		},
+		CaseType: domain.CasePeStuck223HystrixTimeout,
 	}
-	return nil
 }
@@ -3,8 +3,9 @@ package adapters
 import "buddy/internal/txn/domain"
 
 // registerPPETemplates registers all Partnerpay Engine (PPE) templates
-func registerPPETemplates(templates map[domain.Case]TemplateFunc) {
+func registerPPETemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CaseEcotxnChargeFailedCaptureFailedTMError] = ecotxnChargeFailedCaptureFailedTMError
+	expectations[domain.CaseEcotxnChargeFailedCaptureFailedTMError] = expectStates("PPE charge re-authorised", targetStates("PartnerpayEngine.Workflow.State", "300", "400", "800", "871", "888", "890"))
 }
 
 // ecotxnChargeFailedCaptureFailedTMError handles Ecotxn charge failed with capture failed and TM error
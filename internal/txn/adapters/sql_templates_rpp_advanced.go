@@ -3,12 +3,17 @@ package adapters
 import "buddy/internal/txn/domain"
 
 // registerRPPAdvancedTemplates registers advanced RPP (Real-time Payment Processing) templates
-func registerRPPAdvancedTemplates(templates map[domain.Case]TemplateFunc) {
+func registerRPPAdvancedTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CasePcExternalPaymentFlow201_0RPP210] = pcExternalPaymentFlow201_0RPP210
+	expectations[domain.CasePcExternalPaymentFlow201_0RPP210] = expectStates("RPP transfer resumed", targetStates("RPPAdapter.Workflow.State", rppResumedStates...))
 	templates[domain.CasePcExternalPaymentFlow201_0RPP900] = pcExternalPaymentFlow201_0RPP900
+	expectations[domain.CasePcExternalPaymentFlow201_0RPP900] = expectStates("PC external transfer completed", targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalCompletedStates...))
 	templates[domain.CaseRppRtpCashinStuck200_0] = rppRtpCashinStuck200_0
+	expectations[domain.CaseRppRtpCashinStuck200_0] = expectRPPStates("RPP RTP cash-in progressed", "wf_ct_rtp_cashin", "110", "121", "201", "210", "220", "700", "701", "900", "901")
 	templates[domain.CaseRpp210Pe220Pc201Accept] = rpp210Pe220Pc201Accept
+	expectations[domain.CaseRpp210Pe220Pc201Accept] = expectStates("RPP transfer resumed", targetStates("RPPAdapter.Workflow.State", rppResumedStates...))
 	templates[domain.CasePcStuck201WaitingRppRepublishFromRpp] = pcStuck201WaitingRppRepublishFromRpp
+	expectations[domain.CasePcStuck201WaitingRppRepublishFromRpp] = expectStates("PC external transfer completed", targetStates("PaymentCore.ExternalTransfer.Workflow.State", pcExternalCompletedStates...))
 }
 
 // pcExternalPaymentFlow201_0RPP210 handles PC 201, RPP 210 - no response from RPP
@@ -1,18 +1,34 @@
 package adapters
 
-import "buddy/internal/txn/domain"
+import (
+	"strings"
+
+	"buddy/internal/txn/domain"
+)
 
 // registerRPPBasicTemplates registers basic RPP (Real-time Payment Processing) templates
-func registerRPPBasicTemplates(templates map[domain.Case]TemplateFunc) {
+func registerRPPBasicTemplates(templates map[domain.Case]TemplateFunc, expectations map[domain.Case][]FixExpectation) {
 	templates[domain.CaseRppCashoutReject101_19] = rppCashoutReject101_19
+	expectations[domain.CaseRppCashoutReject101_19] = expectRPPStates("RPP cashout rejected", "wf_ct_cashout", rppRejectedStates...)
 	templates[domain.CaseRppQrPaymentReject210_0] = rppQrPaymentReject210_0
+	expectations[domain.CaseRppQrPaymentReject210_0] = expectRPPStates("RPP QR payment rejected", "wf_ct_qr_payment", rppRejectedStates...)
 	templates[domain.CaseRppNoResponseRejectNotFound] = rppNoResponseRejectNotFound
+	expectations[domain.CaseRppNoResponseRejectNotFound] = expectRPPStates("RPP QR payment rejected", "wf_ct_qr_payment", rppRejectedStates...)
+	templates[domain.CaseRppNoResponseRejectNotFoundState0] = rppNoResponseRejectNotFoundState0
+	expectations[domain.CaseRppNoResponseRejectNotFoundState0] = expectRPPStates("RPP QR payment rejected", "wf_ct_qr_payment", rppRejectedStates...)
 	templates[domain.CaseRppNoResponseResume] = rppNoResponseResume
+	expectations[domain.CaseRppNoResponseResume] = expectStates("RPP transfer resumed", targetStates("RPPAdapter.Workflow.State", rppResumedStates...))
+	templates[domain.CaseRppAdapterPublishFailure311] = rppAdapterPublishFailure311
+	expectations[domain.CaseRppAdapterPublishFailure311] = expectRPPStates("RPP cashout published", "wf_ct_cashout", "700", "900")
 	templates[domain.CaseRppCashinValidationFailed122_0] = rppCashinValidationFailed122_0
+	expectations[domain.CaseRppCashinValidationFailed122_0] = expectRPPStates("RPP cash-in revalidated", "wf_ct_cashin", "100", "110", "121", "200", "201", "210", "220", "700", "701", "900", "901")
 	templates[domain.CaseRppProcessRegistryStuckInit] = rppProcessRegistryStuckInit
+	expectations[domain.CaseRppProcessRegistryStuckInit] = expectRPPStates("RPP registry left stInit", "wf_process_registry", "100", "201", "202", "700", "900")
 
 	templates[domain.CaseCashInStuck100Retry] = cashInStuck100Retry
+	expectations[domain.CaseCashInStuck100Retry] = expectRPPStates("RPP cash-in progressed", "wf_ct_cashin", rppCashinProgressedStates...)
 	templates[domain.CaseCashInStuck100UpdateMismatch] = cashInStuck100UpdateMismatch
+	expectations[domain.CaseCashInStuck100UpdateMismatch] = expectRPPStates("RPP cash-in progressed", "wf_ct_cashin", rppCashinProgressedStates...)
 }
 
 // rppCashoutReject101_19 handles RPP cashout reject at state 101, attempt 19
@@ -56,7 +72,7 @@ AND workflow_id = 'wf_ct_cashout';`,
 				SQLTemplate: `-- rpp_cashout_reject_101_19_rollback
 UPDATE workflow_execution
 SET state = 101,
-    attempt = 0,
+    attempt = 19,
     data = JSON_SET(data, '$.State', 101)
 WHERE run_id = %s
 AND workflow_id = 'wf_ct_cashout';`,
@@ -178,6 +194,56 @@ AND workflow_id = 'wf_ct_qr_payment';`,
 	}
 }
 
+// rppNoResponseRejectNotFoundState0 handles RPP QR payment stuck at state 0 (stInit), any attempt,
+// while PE waits at 220 and PC at 201. The request never reached PayNet, so RPP is rejected.
+// The rollback restores the attempt the workflow had.
+func rppNoResponseRejectNotFoundState0(result domain.TransactionResult) *domain.DMLTicket {
+	if result.RPPAdapter == nil {
+		return nil
+	}
+
+	wf := getRPPWorkflowByCriteria(result.RPPAdapter.Workflow, "wf_ct_qr_payment", "0", -1)
+	if wf == nil || wf.RunID == "" {
+		return nil
+	}
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- rpp_no_response_reject_not_found_state_0 - RPP adapter stuck in initialization, never sent to Paynet
+UPDATE workflow_execution
+SET state = 221,
+    attempt = 1,
+    data = JSON_SET(data, '$.State', 221)
+WHERE run_id = %s
+AND state = 0
+AND workflow_id = 'wf_ct_qr_payment';`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
+				},
+			},
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- rpp_no_response_reject_not_found_state_0_rollback
+UPDATE workflow_execution
+SET state = 0,
+    attempt = %s,
+    data = JSON_SET(data, '$.State', 0)
+WHERE run_id = %s
+AND workflow_id = 'wf_ct_qr_payment';`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
+					{Name: "attempt", Value: wf.Attempt, Type: "int"},
+				},
+			},
+		},
+		CaseType: domain.CaseRppNoResponseRejectNotFoundState0,
+	}
+}
+
 // rppNoResponseResume handles RPP no response - resume transaction
 func rppNoResponseResume(result domain.TransactionResult) *domain.DMLTicket {
 	if result.RPPAdapter == nil {
@@ -233,6 +299,62 @@ AND workflow_id IN ('wf_ct_cashout', 'wf_ct_qr_payment');`,
 	}
 }
 
+// rppAdapterPublishFailure311 handles an RPP cashout that reached 301 (stPrepareSuccessPublish)
+// or 311 (stPrepareFailurePublish) but failed to publish to Kafka. As documented in the SOP,
+// the workflow is moved to 311 with attempt 1 so the publish is resumed. The rollback
+// restores the state and attempt it had.
+func rppAdapterPublishFailure311(result domain.TransactionResult) *domain.DMLTicket {
+	if result.RPPAdapter == nil {
+		return nil
+	}
+
+	wf := getRPPWorkflowByCriteria(result.RPPAdapter.Workflow, "wf_ct_cashout", "311", -1)
+	if wf == nil {
+		wf = getRPPWorkflowByCriteria(result.RPPAdapter.Workflow, "wf_ct_cashout", "301", -1)
+	}
+	if wf == nil || wf.RunID == "" {
+		return nil
+	}
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- rpp_adapter_publish_failure_311 - Resume failed publish
+UPDATE workflow_execution
+SET state = 311,
+    attempt = 1,
+    data = JSON_SET(data, '$.State', 311)
+WHERE run_id = %s
+AND state IN (301, 311)
+AND workflow_id = 'wf_ct_cashout';`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
+				},
+			},
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- rpp_adapter_publish_failure_311_rollback
+UPDATE workflow_execution
+SET state = %s,
+    attempt = %s,
+    data = JSON_SET(data, '$.State', %s)
+WHERE run_id = %s
+AND workflow_id = 'wf_ct_cashout';`,
+				Params: []domain.ParamInfo{
+					{Name: "run_id", Value: wf.RunID, Type: "string"},
+					{Name: "state", Value: wf.State, Type: "int"},
+					{Name: "attempt", Value: wf.Attempt, Type: "int"},
+					{Name: "json_state", Value: wf.State, Type: "int"},
+				},
+			},
+		},
+		CaseType: domain.CaseRppAdapterPublishFailure311,
+	}
+}
+
 // rppCashinValidationFailed122_0 handles RPP cashin validation failed at state 122, attempt 0
 func rppCashinValidationFailed122_0(result domain.TransactionResult) *domain.DMLTicket {
 	if result.RPPAdapter == nil {
@@ -447,3 +569,57 @@ AND state = 100;`,
 		CaseType: domain.CaseCashInStuck100UpdateMismatch,
 	}
 }
+
+// userNameChangeQrInvalidation marks the ACTIVE QR codes of a user INACTIVE after a
+// name change, so the app generates a new code with the new name. It is keyed by user
+// rather than by transaction, so it is not part of sqlTemplates. createdAts pin the
+// rows that were ACTIVE when the ticket was generated, so the rollback only
+// reactivates those.
+func userNameChangeQrInvalidation(userID string, createdAts []string) *domain.DMLTicket {
+	if userID == "" || len(createdAts) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(createdAts))
+	for i, createdAt := range createdAts {
+		quoted[i] = "'" + strings.ReplaceAll(createdAt, "'", "''") + "'"
+	}
+	createdAtList := strings.Join(quoted, ", ")
+	userID = strings.ReplaceAll(userID, "'", "''")
+
+	return &domain.DMLTicket{
+		Deploy: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- user_name_change_qr_invalidation - Mark QR code as inactive
+UPDATE qr_code
+SET status = 'INACTIVE',
+    updated_at = NOW()
+WHERE user_id = %s
+AND status = 'ACTIVE'
+AND created_at IN (%s);`,
+				Params: []domain.ParamInfo{
+					{Name: "user_id", Value: userID, Type: "string"},
+					{Name: "created_at", Value: createdAtList, Type: "sql"},
+				},
+			},
+		},
+		Rollback: []domain.TemplateInfo{
+			{
+				TargetDB: "RPP",
+				SQLTemplate: `-- user_name_change_qr_invalidation_rollback
+UPDATE qr_code
+SET status = 'ACTIVE',
+    updated_at = NOW()
+WHERE user_id = %s
+AND status = 'INACTIVE'
+AND created_at IN (%s);`,
+				Params: []domain.ParamInfo{
+					{Name: "user_id", Value: userID, Type: "string"},
+					{Name: "created_at", Value: createdAtList, Type: "sql"},
+				},
+			},
+		},
+		CaseType: domain.CaseUserNameChangeQrInvalidation,
+	}
+}
@@ -0,0 +1,268 @@
+package adapters
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"buddy/internal/txn/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed sql_schema.yaml
+var defaultSQLSchemaYAML []byte
+
+// sqlSchema is the embedded schema model generated DML is verified against
+var sqlSchema = mustLoadSQLSchema(defaultSQLSchemaYAML)
+
+// sqlTableSchema describes the columns and keys of a table
+type sqlTableSchema struct {
+	Columns []string   `yaml:"columns"`
+	Keys    [][]string `yaml:"keys"`
+}
+
+// sqlDatabaseSchema describes one service database
+type sqlDatabaseSchema struct {
+	Target string                    `yaml:"target"`
+	Tables map[string]sqlTableSchema `yaml:"tables"`
+}
+
+// sqlSchemaFile is the top-level layout of sql_schema.yaml
+type sqlSchemaFile struct {
+	Databases map[string]sqlDatabaseSchema `yaml:"databases"`
+}
+
+// sqlSchemaModel indexes the schema by TargetDB code
+type sqlSchemaModel struct {
+	byTarget map[string]sqlDatabaseModel
+}
+
+type sqlDatabaseModel struct {
+	name   string
+	tables map[string]sqlTableModel
+}
+
+type sqlTableModel struct {
+	columns map[string]bool
+	keys    [][]string
+}
+
+// knownSQLFunctions lists the functions templates may call
+var knownSQLFunctions = map[string]bool{
+	"JSON_SET": true, "JSON_REMOVE": true, "JSON_REPLACE": true, "JSON_INSERT": true,
+	"JSON_OBJECT": true, "JSON_ARRAY": true, "JSON_EXTRACT": true, "JSON_UNQUOTE": true,
+	"NOW": true, "UTC_TIMESTAMP": true, "CURRENT_TIMESTAMP": true, "DATE_ADD": true, "DATE_SUB": true,
+	"CONCAT": true, "COALESCE": true, "IFNULL": true, "CAST": true,
+}
+
+// sqlKeywords are bare words that are not column references
+var sqlKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "NULL": true, "IS": true, "IN": true,
+	"TRUE": true, "FALSE": true, "LIKE": true, "BETWEEN": true, "AS": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"INTERVAL": true, "SECOND": true, "MINUTE": true, "HOUR": true, "DAY": true,
+	"CURRENT_TIMESTAMP": true, "JSON": true, "CHAR": true, "SIGNED": true, "UNSIGNED": true,
+}
+
+func mustLoadSQLSchema(data []byte) *sqlSchemaModel {
+	model, err := parseSQLSchema(data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded SQL schema is invalid: %v", err))
+	}
+	return model
+}
+
+// parseSQLSchema decodes and indexes a schema description
+func parseSQLSchema(data []byte) (*sqlSchemaModel, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file sqlSchemaFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse SQL schema YAML: %w", err)
+	}
+
+	model := &sqlSchemaModel{byTarget: make(map[string]sqlDatabaseModel)}
+	for dbName, db := range file.Databases {
+		if db.Target == "" {
+			return nil, fmt.Errorf("database %s has no target", dbName)
+		}
+		if _, exists := model.byTarget[db.Target]; exists {
+			return nil, fmt.Errorf("target %s is declared by more than one database", db.Target)
+		}
+
+		dbModel := sqlDatabaseModel{name: dbName, tables: make(map[string]sqlTableModel)}
+		for tableName, table := range db.Tables {
+			tableModel := sqlTableModel{columns: make(map[string]bool), keys: table.Keys}
+			for _, column := range table.Columns {
+				tableModel.columns[column] = true
+			}
+			if len(table.Keys) == 0 {
+				return nil, fmt.Errorf("table %s.%s has no keys", dbName, tableName)
+			}
+			for _, key := range table.Keys {
+				for _, column := range key {
+					if !tableModel.columns[column] {
+						return nil, fmt.Errorf("key column %s.%s.%s is not a declared column", dbName, tableName, column)
+					}
+				}
+			}
+			dbModel.tables[tableName] = tableModel
+		}
+		model.byTarget[db.Target] = dbModel
+	}
+
+	return model, nil
+}
+
+// VerifySQL parses every statement in sql and checks it against the schema of the
+// target database: tables and columns must exist, functions must be known, and
+// each UPDATE/DELETE must have a WHERE clause that pins a primary or unique key.
+func VerifySQL(targetDB, sql string) error {
+	return sqlSchema.verify(targetDB, sql)
+}
+
+// VerifySQLStatements runs VerifySQL over every deploy and rollback statement
+func VerifySQLStatements(statements domain.SQLStatements) error {
+	groups := []struct {
+		targetDB string
+		sqls     []string
+	}{
+		{"PC", statements.PCDeployStatements}, {"PC", statements.PCRollbackStatements},
+		{"PE", statements.PEDeployStatements}, {"PE", statements.PERollbackStatements},
+		{"PPE", statements.PPEDeployStatements}, {"PPE", statements.PPERollbackStatements},
+		{"RPP", statements.RPPDeployStatements}, {"RPP", statements.RPPRollbackStatements},
+	}
+
+	for _, group := range groups {
+		for _, sql := range group.sqls {
+			if err := VerifySQL(group.targetDB, sql); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *sqlSchemaModel) verify(targetDB, sql string) error {
+	db, ok := m.byTarget[targetDB]
+	if !ok {
+		return fmt.Errorf("no schema for target database %s", targetDB)
+	}
+
+	statements, err := parseSQLStatements(sql)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		return fmt.Errorf("no SQL statement found")
+	}
+
+	for _, stmt := range statements {
+		if err := db.verifyStatement(stmt); err != nil {
+			return fmt.Errorf("%s %s on %s: %w", stmt.Kind, stmt.Table, db.name, err)
+		}
+	}
+	return nil
+}
+
+func (db sqlDatabaseModel) verifyStatement(stmt sqlStatement) error {
+	table, ok := db.tables[stmt.Table]
+	if !ok {
+		return fmt.Errorf("unknown table %s", stmt.Table)
+	}
+
+	for _, assignment := range stmt.Assignments {
+		if !table.columns[assignment.Column] {
+			return fmt.Errorf("unknown column %s in SET", assignment.Column)
+		}
+		if err := table.verifyExpression(assignment.Expr); err != nil {
+			return fmt.Errorf("SET %s: %w", assignment.Column, err)
+		}
+	}
+
+	for _, column := range stmt.Columns {
+		if !table.columns[column] {
+			return fmt.Errorf("unknown column %s", column)
+		}
+	}
+
+	if err := table.verifyExpression(stmt.WhereTokens); err != nil {
+		return fmt.Errorf("WHERE: %w", err)
+	}
+
+	if stmt.Kind != "UPDATE" && stmt.Kind != "DELETE" {
+		return nil
+	}
+	if !stmt.HasWhere {
+		return fmt.Errorf("missing WHERE clause")
+	}
+	if stmt.WhereHasOr {
+		return fmt.Errorf("WHERE clause uses OR, so it cannot be proven to target specific rows")
+	}
+	if !table.isKeyed(stmt.Where) {
+		return fmt.Errorf("WHERE clause does not pin a primary or unique key (%s)", formatKeys(table.keys))
+	}
+	return nil
+}
+
+// verifyExpression checks every column reference and function call in tokens
+func (t sqlTableModel) verifyExpression(tokens []sqlToken) error {
+	for i, tok := range tokens {
+		if tok.Kind != sqlTokenIdent {
+			continue
+		}
+		upper := strings.ToUpper(tok.Text)
+		isCall := i+1 < len(tokens) && isSQLPunct(tokens[i+1], "(")
+		isQualifier := i+1 < len(tokens) && isSQLPunct(tokens[i+1], ".")
+
+		switch {
+		case !tok.Quoted && sqlKeywords[upper]:
+		case isCall:
+			if !knownSQLFunctions[upper] {
+				return fmt.Errorf("unknown function %s", tok.Text)
+			}
+		case isQualifier:
+			// table qualifier of a qualified column reference
+		default:
+			if !t.columns[tok.Text] {
+				return fmt.Errorf("unknown column %s", tok.Text)
+			}
+		}
+	}
+	return nil
+}
+
+// isKeyed reports whether the predicates pin every column of at least one key
+func (t sqlTableModel) isKeyed(predicates []sqlPredicate) bool {
+	pinned := make(map[string]bool)
+	for _, predicate := range predicates {
+		if predicate.Op == "=" || predicate.Op == "IN" {
+			pinned[predicate.Column] = true
+		}
+	}
+
+	for _, key := range t.keys {
+		covered := true
+		for _, column := range key {
+			if !pinned[column] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+func formatKeys(keys [][]string) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, strings.Join(key, "+"))
+	}
+	return "expected one of: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func TestVerifySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		targetDB string
+		sql      string
+		wantErr  string
+	}{
+		{
+			name:     "keyed workflow update",
+			targetDB: "PE",
+			sql: `-- comment
+UPDATE workflow_execution
+SET state = 221, attempt = 1, ` + "`data`" + ` = JSON_SET(` + "`data`" + `, '$.State', 221)
+WHERE run_id IN ('run-1', 'run-2') AND state = 220 AND workflow_id = 'workflow_transfer_payment';`,
+		},
+		{
+			name:     "json path operator",
+			targetDB: "PE",
+			sql:      "UPDATE workflow_execution SET prev_trans_id = data->>'$.StreamMessage.ReferenceID' WHERE run_id = 'run-1';",
+		},
+		{
+			name:     "multiple statements",
+			targetDB: "PPE",
+			sql: `UPDATE charge SET status = 'FAILED', updated_at = '2025-01-01' WHERE transaction_id = 'txn-1';
+UPDATE workflow_execution SET state = 502 WHERE run_id = 'txn-1';`,
+		},
+		{
+			name:     "unknown column in SET",
+			targetDB: "PE",
+			sql:      "UPDATE workflow_execution SET stat = 221 WHERE run_id = 'run-1';",
+			wantErr:  "unknown column stat",
+		},
+		{
+			name:     "unknown column in expression",
+			targetDB: "PE",
+			sql:      "UPDATE workflow_execution SET data = JSON_SET(dta, '$.State', 221) WHERE run_id = 'run-1';",
+			wantErr:  "unknown column dta",
+		},
+		{
+			name:     "unknown function",
+			targetDB: "PE",
+			sql:      "UPDATE workflow_execution SET data = JSON_STE(data, '$.State', 221) WHERE run_id = 'run-1';",
+			wantErr:  "unknown function JSON_STE",
+		},
+		{
+			name:     "table from another database",
+			targetDB: "PE",
+			sql:      "UPDATE charge SET status = 'FAILED' WHERE transaction_id = 'txn-1';",
+			wantErr:  "unknown table charge",
+		},
+		{
+			name:     "missing WHERE",
+			targetDB: "RPP",
+			sql:      "UPDATE workflow_execution SET state = 222;",
+			wantErr:  "missing WHERE",
+		},
+		{
+			name:     "WHERE without key",
+			targetDB: "RPP",
+			sql:      "UPDATE workflow_execution SET state = 222 WHERE state = 210 AND workflow_id = 'wf_ct_cashout';",
+			wantErr:  "does not pin a primary or unique key",
+		},
+		{
+			name:     "WHERE with OR",
+			targetDB: "RPP",
+			sql:      "UPDATE workflow_execution SET state = 222 WHERE run_id = 'run-1' OR state = 210;",
+			wantErr:  "uses OR",
+		},
+		{
+			name:     "DELETE must be keyed",
+			targetDB: "PC",
+			sql:      "DELETE FROM internal_transaction WHERE status = 'FAILED';",
+			wantErr:  "does not pin",
+		},
+		{
+			name:     "unknown target",
+			targetDB: "XYZ",
+			sql:      "UPDATE workflow_execution SET state = 1 WHERE run_id = 'run-1';",
+			wantErr:  "no schema for target database XYZ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySQL(tt.targetDB, tt.sql)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected SQL to verify, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// populatedResult fills every field templates read so that each template produces SQL
+func populatedResult(caseType domain.Case) domain.TransactionResult {
+	wf := func(id, runID, state string) domain.WorkflowInfo {
+		return domain.WorkflowInfo{WorkflowID: id, RunID: runID, State: state, PrevTransID: "prev-" + runID, Data: `{"State": 0}`}
+	}
+
+	return domain.TransactionResult{
+		InputID:  "txn-1",
+		CaseType: caseType,
+		PaymentEngine: &domain.PaymentEngineInfo{
+			Transfers: domain.PETransfersInfo{
+				TransactionID: "txn-1", ExternalID: "20250101GXSPMYXXXXXXXXXXXXXXXXXX",
+				CreatedAt: "2025-01-01T00:00:00Z", UpdatedAt: "2025-01-01T00:00:00Z",
+			},
+			Workflow: wf("workflow_transfer_payment", "pe-run", "220"),
+		},
+		PaymentCore: &domain.PaymentCoreInfo{
+			InternalAuth:     domain.PCInternalInfo{TxID: "auth-1", TxStatus: "SUCCESS", Workflow: wf("internal_payment_flow", "pc-auth-run", "900")},
+			InternalCapture:  domain.PCInternalInfo{TxID: "cap-1", Workflow: wf("internal_payment_flow", "pc-cap-run", "500")},
+			ExternalTransfer: domain.PCExternalInfo{RefID: "ref-1", Workflow: wf("external_payment_flow", "pc-ext-run", "201")},
+		},
+		RPPAdapter: &domain.RPPAdapterInfo{
+			EndToEndID: "20250101GXSPMYXXXXXXXXXXXXXXXXXX", PartnerTxID: "partner-1", Status: "900",
+			Workflow: []domain.WorkflowInfo{wf("wf_ct_cashout", "rpp-run", "900")},
+		},
+		PartnerpayEngine: &domain.PartnerpayEngineInfo{
+			Charge:   domain.PPEChargeInfo{TransactionID: "txn-1", Status: "PROCESSING", UpdatedAt: "2025-01-01T00:00:00Z"},
+			Workflow: wf("workflow_charge", "ppe-run", "300"),
+		},
+	}
+}
+
+// acceptingTemplate returns the template of caseType, answering the accept/reject
+// decision of interactive cases with accept instead of prompting
+func acceptingTemplate(caseType domain.Case, templateFunc TemplateFunc) TemplateFunc {
+	if _, ok := interactiveCases[caseType]; !ok {
+		return templateFunc
+	}
+	return func(result domain.TransactionResult) *domain.DMLTicket {
+		result.CaseType = caseType
+		return ticketForDecision(result, DecisionAccept)
+	}
+}
+
+func TestSQLTemplates_PassSchemaVerification(t *testing.T) {
+	verified := 0
+	for caseType, templateFunc := range sqlTemplates {
+		ticket := acceptingTemplate(caseType, templateFunc)(populatedResult(caseType))
+		if ticket == nil {
+			continue
+		}
+		verified++
+
+		statements, err := generateSQLFromTicket(*ticket)
+		if err != nil {
+			t.Errorf("%s: failed to generate SQL: %v", caseType, err)
+			continue
+		}
+		if err := VerifySQLStatements(statements); err != nil {
+			t.Errorf("%s: %v", caseType, err)
+		}
+	}
+
+	if verified == 0 {
+		t.Fatal("expected at least one template to produce SQL")
+	}
+	t.Logf("verified SQL from %d of %d templates", verified, len(sqlTemplates))
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "errors"
+
+// QueryFailedStatus is shown instead of NotFoundStatus when a system could not
+// be queried, so a Doorman outage is not mistaken for a missing record
+const QueryFailedStatus = "QUERY_FAILED"
+
+// ErrNotFound is wrapped by populators when a query succeeded but found no record
+var ErrNotFound = errors.New("not found")
+
+// QueryFailure records a system that could not be queried for a transaction
+type QueryFailure struct {
+	System   string // e.g. payment-engine, rpp-adapter
+	Error    string
+	Attempts int // times the failing query was sent, including retries
+}
+
+// RecordQueryFailure notes that querying system failed. Nil errors and
+// ErrNotFound are not failures and are ignored.
+func (r *TransactionResult) RecordQueryFailure(system string, err error) {
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	failure := QueryFailure{System: system, Error: err.Error(), Attempts: 1}
+	var counted interface{ QueryAttempts() int }
+	if errors.As(err, &counted) {
+		failure.Attempts = counted.QueryAttempts()
+	}
+	r.QueryFailures = append(r.QueryFailures, failure)
+}
+
+// QueryFailed reports whether any system could not be queried, meaning the
+// result may be incomplete
+func (r *TransactionResult) QueryFailed() bool {
+	return len(r.QueryFailures) > 0
+}
+
+// QueryRetries returns how many extra attempts the queries of the result made,
+// both those that failed and those that succeeded after retrying
+func (r *TransactionResult) QueryRetries() int {
+	retries := r.RecoveredRetries
+	for _, failure := range r.QueryFailures {
+		if failure.Attempts > 1 {
+			retries += failure.Attempts - 1
+		}
+	}
+	return retries
+}
@@ -135,6 +135,8 @@ type TransactionResult struct {
 	RPPAdapter       *RPPAdapterInfo
 	CaseType         Case // Store the identified SOP case to avoid re-identification
 	Error            string
+	QueryFailures    []QueryFailure // systems that could not be queried; see RecordQueryFailure
+	RecoveredRetries int            // extra attempts of queries that succeeded after retrying
 }
 
 // Common status values
@@ -171,6 +173,14 @@ const (
 	CaseCashInStuck100Retry                          Case = "cash_in_stuck_100_retry"
 	CaseCashInStuck100UpdateMismatch                 Case = "cash_in_stuck_100_update_mismatch"
 	CasePcStuck201WaitingRppRepublishFromRpp         Case = "pc_stuck_201_waiting_rpp_republish_from_rpp"
+	CaseFastCashoutPendingPe220Pc201                 Case = "fast_cashout_pending_pe220_pc201"
+	CaseFastCashoutErroneousPe220Pc201               Case = "fast_cashout_erroneous_pe220_pc201"
+	CaseFastCashinUnknownPe220                       Case = "fast_cashin_unknown_pe220"
+	CaseFastCashinErroneousPe220                     Case = "fast_cashin_erroneous_pe220"
+	CaseRppNoResponseRejectNotFoundState0            Case = "rpp_no_response_reject_not_found_state_0"
+	CaseRppAdapterPublishFailure311                  Case = "rpp_adapter_publish_failure_311"
+	CasePeStuck223HystrixTimeout                     Case = "pe_stuck_223_hystrix_timeout"
+	CaseUserNameChangeQrInvalidation                 Case = "user_name_change_qr_invalidation"
 )
 
 // GetCaseSummaryOrder returns the order in which SOP cases should be displayed in summaries
@@ -182,13 +192,20 @@ func GetCaseSummaryOrder() []Case {
 		CasePeTransferPayment210_0,
 		CasePeStuckAtLimitCheck102,
 		CasePeStuck230RepublishPC,
+		CasePeStuck223HystrixTimeout,
 		CaseThoughtMachineFalseNegative,
 		CasePeCaptureProcessingPcCaptureFailedRppSuccess,
 		CasePe2200FastCashinFailed,
+		CaseFastCashoutPendingPe220Pc201,
+		CaseFastCashoutErroneousPe220Pc201,
+		CaseFastCashinUnknownPe220,
+		CaseFastCashinErroneousPe220,
 		CaseRppCashoutReject101_19,
 		CaseRppQrPaymentReject210_0,
 		CaseRppNoResponseRejectNotFound,
+		CaseRppNoResponseRejectNotFoundState0,
 		CaseRppNoResponseResume,
+		CaseRppAdapterPublishFailure311,
 		CaseRppCashinValidationFailed122_0,
 		CaseRppRtpCashinStuck200_0,
 		CaseEcotxnChargeFailedCaptureFailedTMError,
@@ -199,6 +216,7 @@ func GetCaseSummaryOrder() []Case {
 		CaseRpp210Pe220Pc201Reject,
 		CasePe220Pc201Rpp0StuckInit,
 		CaseRppProcessRegistryStuckInit,
+		CaseUserNameChangeQrInvalidation,
 		CaseCashInStuck100Retry,
 		CaseCashInStuck100UpdateMismatch,
 		CasePcStuck201WaitingRppRepublishFromRpp,
@@ -221,7 +239,7 @@ type SQLStatements struct {
 type ParamInfo struct {
 	Name  string      // Parameter name (e.g., "run_id", "prev_trans_id")
 	Value interface{} // Parameter value (use interface{} for type flexibility)
-	Type  string      // Parameter type: "string", "int"
+	Type  string      // Parameter type: "string", "int", or "sql" for a raw SQL expression
 }
 
 type TemplateInfo struct {
@@ -1,6 +1,9 @@
 package ports
 
-import "buddy/internal/txn/domain"
+import (
+	"buddy/internal/txn/domain"
+	"time"
+)
 
 // Port interfaces for data sources
 
@@ -19,6 +22,20 @@ type PaymentCorePort interface {
 	QueryPaymentCore(query string) ([]map[string]interface{}, error)
 }
 
+// BulkPaymentEnginePort is implemented by payment engine ports that can fetch
+// rows for many IDs with a single IN (...) query
+type BulkPaymentEnginePort interface {
+	QueryTransfers(transactionIDs []string) ([]map[string]interface{}, error)
+	QueryWorkflows(runIDs []string) ([]map[string]interface{}, error)
+}
+
+// BulkPaymentCorePort is implemented by payment core ports that can fetch the
+// transactions of many groups created between from and to in a single query
+type BulkPaymentCorePort interface {
+	QueryInternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error)
+	QueryExternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error)
+}
+
 // RPPAdapterPort defines the interface for RPP adapter queries
 type RPPAdapterPort interface {
 	Query(params domain.RPPQueryParams) (*domain.RPPAdapterInfo, error)
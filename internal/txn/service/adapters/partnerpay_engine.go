@@ -26,7 +26,7 @@ func (p *PartnerpayEngineAdapter) QueryCharge(transactionID string) (domain.Part
 	query := fmt.Sprintf("SELECT status, status_reason, status_reason_description, transaction_id, created_at, updated_at FROM charge WHERE transaction_id='%s'", transactionID)
 	charges, err := p.client.QueryPartnerpayEngine(query)
 	if err != nil {
-		return domain.PartnerpayEngineInfo{}, fmt.Errorf("failed to query charge table: %v", err)
+		return domain.PartnerpayEngineInfo{}, fmt.Errorf("failed to query charge table: %w", err)
 	}
 	if len(charges) == 0 {
 		return domain.PartnerpayEngineInfo{Charge: domain.PPEChargeInfo{TransactionID: transactionID, Status: domain.NotFoundStatus}}, nil
@@ -62,15 +62,45 @@ func (p *PaymentCoreAdapter) QueryWorkflows(runIDs []string) ([]map[string]inter
 	if len(runIDs) == 0 {
 		return nil, nil
 	}
-	quotedRunIDs := make([]string, len(runIDs))
-	for i, id := range runIDs {
-		quotedRunIDs[i] = "'" + id + "'"
+	query := fmt.Sprintf("SELECT run_id, workflow_id, state, attempt FROM workflow_execution WHERE run_id IN (%s)", quoteIDs(runIDs))
+	return p.client.QueryPaymentCore(query)
+}
+
+// QueryInternalTransactionsByGroupIDs fetches the internal transactions of many
+// groups created between from and to in one query
+func (p *PaymentCoreAdapter) QueryInternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("QueryInternalTransactionsByGroupIDs: database client is not initialized")
 	}
-	runIDsStr := strings.Join(quotedRunIDs, ", ")
-	query := fmt.Sprintf("SELECT run_id, workflow_id, state, attempt FROM workflow_execution WHERE run_id IN (%s)", runIDsStr)
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf("SELECT group_id, tx_id, tx_type, status, error_code, error_msg, created_at FROM internal_transaction WHERE group_id IN (%s) AND created_at >= '%s' AND created_at <= '%s'", quoteIDs(groupIDs), from.Format(time.RFC3339), to.Format(time.RFC3339))
 	return p.client.QueryPaymentCore(query)
 }
 
+// QueryExternalTransactionsByGroupIDs fetches the external transactions of many
+// groups created between from and to in one query
+func (p *PaymentCoreAdapter) QueryExternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("QueryExternalTransactionsByGroupIDs: database client is not initialized")
+	}
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf("SELECT group_id, ref_id, tx_type, status, created_at FROM external_transaction WHERE group_id IN (%s) AND created_at >= '%s' AND created_at <= '%s'", quoteIDs(groupIDs), from.Format(time.RFC3339), to.Format(time.RFC3339))
+	return p.client.QueryPaymentCore(query)
+}
+
+// quoteIDs formats ids as the body of an SQL IN (...) list
+func quoteIDs(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = "'" + strings.ReplaceAll(id, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // QueryPaymentCore executes a custom query against Payment Core
 func (p *PaymentCoreAdapter) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
 	if p.client == nil {
@@ -78,3 +108,6 @@ func (p *PaymentCoreAdapter) QueryPaymentCore(query string) ([]map[string]interf
 	}
 	return p.client.QueryPaymentCore(query)
 }
+
+// Ensure the adapter can be used for bulk population
+var _ ports.BulkPaymentCorePort = (*PaymentCoreAdapter)(nil)
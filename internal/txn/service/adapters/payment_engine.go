@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"buddy/internal/txn/domain"
 	"buddy/internal/txn/ports"
 	"fmt"
 	"time"
@@ -43,11 +44,35 @@ func (p *PaymentEngineAdapter) QueryWorkflow(referenceID string) (map[string]int
 		return nil, err
 	}
 	if len(workflows) == 0 {
-		return nil, fmt.Errorf("no workflow found")
+		return nil, fmt.Errorf("workflow %s: %w", referenceID, domain.ErrNotFound)
 	}
 	return workflows[0], nil
 }
 
+// QueryTransfers fetches the transfers of many transaction IDs in one query
+func (p *PaymentEngineAdapter) QueryTransfers(transactionIDs []string) ([]map[string]interface{}, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("QueryTransfers: database client is not initialized")
+	}
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf("SELECT transaction_id, status, reference_id, created_at, updated_at, type, txn_subtype, txn_domain, external_id, source_account_id, destination_account_id, amount, properties FROM transfer WHERE transaction_id IN (%s)", quoteIDs(transactionIDs))
+	return p.client.QueryPaymentEngine(query)
+}
+
+// QueryWorkflows fetches the workflows of many run IDs in one query
+func (p *PaymentEngineAdapter) QueryWorkflows(runIDs []string) ([]map[string]interface{}, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("QueryWorkflows: database client is not initialized")
+	}
+	if len(runIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf("SELECT run_id, workflow_id, prev_trans_id, state, attempt, created_at, updated_at, data FROM workflow_execution WHERE run_id IN (%s)", quoteIDs(runIDs))
+	return p.client.QueryPaymentEngine(query)
+}
+
 func (p *PaymentEngineAdapter) QueryTransferByExternalID(externalID, createdAt string) (map[string]interface{}, error) {
 	// Parse the created_at timestamp
 	parsedTime, err := time.Parse(time.RFC3339, createdAt)
@@ -81,3 +106,6 @@ func (p *PaymentEngineAdapter) QueryTransferByExternalID(externalID, createdAt s
 
 	return transfers[0], nil
 }
+
+// Ensure the adapter can be used for bulk population
+var _ ports.BulkPaymentEnginePort = (*PaymentEngineAdapter)(nil)
@@ -4,6 +4,7 @@ import (
 	"buddy/internal/txn/domain"
 	"buddy/internal/txn/ports"
 	"buddy/internal/txn/utils"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -35,19 +36,29 @@ func (r *RPPAdapter) Query(params domain.RPPQueryParams) (*domain.RPPAdapterInfo
 	}
 
 	// Priority 2: Query by partner_tx_id if provided
+	var partnerErr error
 	if params.PartnerTxID != "" {
 		info, err := r.queryByPartnerTxID(params.PartnerTxID)
 		if err == nil && info != nil {
 			return info, nil
 		}
+		partnerErr = err
 	}
 
 	// Priority 3: Query by account details, amount and timestamp
 	if params.SourceAccountID != "" && params.DestinationAccountID != "" && params.Timestamp != "" {
-		return r.queryByAccountsAmountAndTimestamp(params)
+		info, err := r.queryByAccountsAmountAndTimestamp(params)
+		if err == nil && info == nil && partnerErr != nil {
+			// Nothing matched, but the partner_tx_id lookup never got an answer
+			return nil, partnerErr
+		}
+		return info, err
 	}
 
-	return nil, fmt.Errorf("insufficient parameters provided for RPP query")
+	if partnerErr != nil {
+		return nil, partnerErr
+	}
+	return nil, fmt.Errorf("insufficient parameters provided for RPP query: %w", domain.ErrNotFound)
 }
 
 func (r *RPPAdapter) queryByE2EID(externalID string) (*domain.RPPAdapterInfo, error) {
@@ -55,7 +66,12 @@ func (r *RPPAdapter) queryByE2EID(externalID string) (*domain.RPPAdapterInfo, er
 	rppResults, err := r.client.ExecuteQuery("prd-payments-rpp-adapter-rds-mysql", "prd-payments-rpp-adapter-rds-mysql", "rpp_adapter", query)
 	if err != nil || len(rppResults) == 0 {
 		// Fallback: Query wf_process_registry workflow using date extracted from EndToEndID
-		return r.queryProcessRegistryByE2EID(externalID)
+		info, fallbackErr := r.queryProcessRegistryByE2EID(externalID)
+		if err != nil && errors.Is(fallbackErr, domain.ErrNotFound) {
+			// Not finding the registry workflow says nothing when credit_transfer failed
+			return nil, err
+		}
+		return info, fallbackErr
 	}
 	row := rppResults[0]
 	info := &domain.RPPAdapterInfo{
@@ -277,6 +293,7 @@ func (r *RPPAdapter) queryProcessRegistryByE2EID(externalID string) (*domain.RPP
 
 	// Collect all matching workflow rows across all 1-hour windows
 	allWorkflowRows := make([]map[string]interface{}, 0)
+	var windowErr error
 
 	timeWindowStart := startDate
 	for timeWindowStart.Before(endOfDay) {
@@ -297,6 +314,7 @@ func (r *RPPAdapter) queryProcessRegistryByE2EID(externalID string) (*domain.RPP
 		workflowRows, err := r.client.QueryRppAdapter(workflowQuery)
 		if err != nil {
 			// Continue to next time window if query fails (e.g., too many rows scanned)
+			windowErr = err
 			timeWindowStart = timeWindowEnd
 			continue
 		}
@@ -309,7 +327,10 @@ func (r *RPPAdapter) queryProcessRegistryByE2EID(externalID string) (*domain.RPP
 	}
 
 	if len(allWorkflowRows) == 0 {
-		return nil, fmt.Errorf("no wf_process_registry workflow found for EndToEndID: %s", externalID)
+		if windowErr != nil {
+			return nil, fmt.Errorf("wf_process_registry lookup for EndToEndID %s incomplete: %w", externalID, windowErr)
+		}
+		return nil, fmt.Errorf("no wf_process_registry workflow found for EndToEndID %s: %w", externalID, domain.ErrNotFound)
 	}
 
 	// Create RPPAdapterInfo with workflow data
@@ -19,66 +19,105 @@ type BatchSummary struct {
 
 // ProcessBatchFile processes a file containing multiple transaction IDs
 func ProcessBatchFile(filePath string) {
-	processBatchFileWithEnv(filePath, "my")
+	_ = GetTransactionQueryService().processBatchFile(filePath, "my", DefaultBatchOptions())
 }
 
 // ProcessBatchFileWithEnv processes a file with specified environment
 func ProcessBatchFileWithEnv(filePath, env string) {
-	processBatchFileWithEnv(filePath, env)
+	_ = GetTransactionQueryService().processBatchFile(filePath, env, DefaultBatchOptions())
+}
+
+// ProcessBatchFileWithOptions processes a file with specified environment and batch
+// options. The error, already printed, tells the caller the run did not complete.
+func ProcessBatchFileWithOptions(filePath, env string, opts BatchOptions) error {
+	return GetTransactionQueryService().processBatchFile(filePath, env, opts)
 }
 
 // ProcessEcoBatchFileWithEnv processes a file with specified environment for eco transactions
 func ProcessEcoBatchFileWithEnv(filePath, env string) {
-	processEcoBatchFileWithEnv(filePath, env)
+	processEcoBatchFileWithEnv(filePath, env, adapters.OutputText)
 }
 
-// processBatchFileWithEnv is the internal implementation
-func processBatchFileWithEnv(filePath, env string) {
+// ProcessEcoBatchFileWithOutput processes an eco transaction file, writing results in the given format
+func ProcessEcoBatchFileWithOutput(filePath, env string, output adapters.OutputFormat) {
+	processEcoBatchFileWithEnv(filePath, env, output)
+}
+
+// processBatchFile is the internal implementation of the batch file entry points.
+// Errors are printed as they happen and returned so callers can fail the run.
+func (s *TransactionQueryService) processBatchFile(filePath, env string, opts BatchOptions) error {
 	// Read transaction IDs from file
 	ids, err := utils.ReadTransactionIDsFromFile(filePath)
 	if err != nil {
 		fmt.Printf("Error reading file: %v\n", err)
-		return
+		return err
 	}
 
 	if len(ids) == 0 {
 		fmt.Printf("No transaction IDs found in %s\n", filePath)
-		return
+		return nil
 	}
 
 	fmt.Printf("Processing %d transaction IDs from %s\n", len(ids), filePath)
 
-	// Get the TransactionService singleton for batch processing
-	txnService := GetTransactionQueryService()
+	// Checkpoint every completed transaction so an interrupted run can be resumed
+	checkpoint, err := OpenCheckpoint(filePath, opts.Resume, opts.Fresh)
+	if err != nil {
+		fmt.Printf("Error opening checkpoint: %v\n", err)
+		return err
+	}
+	defer func() {
+		_ = checkpoint.Close()
+	}()
+	if opts.Resume {
+		fmt.Printf("Resuming: %d transactions already completed in %s\n", checkpoint.Len(), checkpoint.Path())
+	}
+	opts.Checkpoint = checkpoint
 
-	// Process each transaction ID
+	// Query all transaction IDs; interactive prompts only happen later in GenerateSQLStatements
 	results := make([]domain.TransactionResult, 0, len(ids))
-	for _, id := range ids {
-		result := txnService.QueryTransactionWithEnv(id, env)
+	for _, result := range s.QueryTransactionsWithEnv(ids, env, opts) {
 		results = append(results, *result)
 	}
 
 	// Generate output path
 	outputPath := generateOutputPath(filePath)
+	sqlBasePath := strings.TrimSuffix(outputPath, "-output.txt")
 
-	// Write detailed results to output file
-	if err := adapters.WriteBatchResults(results, outputPath); err != nil {
-		fmt.Printf("Error writing output file: %v\n", err)
-		return
-	}
+	var statements domain.SQLStatements
+	if opts.Output.IsStructured() {
+		// Structured results embed the generated SQL, so generate it before writing
+		if statements, err = adapters.GenerateSQLStatementsWithDecisions(results, opts.DecisionProvider()); err != nil {
+			fmt.Printf("Error generating SQL: %v\n", err)
+			return err
+		}
+		outputPath = adapters.StructuredOutputPath(outputPath, opts.Output)
+		if err := adapters.WriteStructuredBatchResults(results, statements, outputPath, opts.Output); err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			return err
+		}
+	} else {
+		// Write detailed results to output file
+		if err := adapters.WriteBatchResults(results, outputPath); err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			return err
+		}
 
-	// Generate SQL statements
-	statements := adapters.GenerateSQLStatements(results)
+		// Generate SQL statements
+		if statements, err = adapters.GenerateSQLStatementsWithDecisions(results, opts.DecisionProvider()); err != nil {
+			fmt.Printf("Error generating SQL: %v\n", err)
+			return err
+		}
+	}
 
 	// Clear existing SQL files before writing (for batch mode, always start fresh)
 	adapters.ClearSQLFiles()
 
 	// Write SQL files
-	sqlBasePath := strings.TrimSuffix(outputPath, "-output.txt")
 	filesCreated, err := adapters.WriteSQLFiles(statements, sqlBasePath)
 	if err != nil {
 		fmt.Printf("Error writing SQL files: %v\n", err)
-		return
+		return err
 	}
 
 	// Display generated files
@@ -91,10 +130,19 @@ func processBatchFileWithEnv(filePath, env string) {
 	// Generate and display summary
 	summary := generateBatchSummary(results)
 	printBatchSummary(filePath, summary, outputPath)
+
+	// A clean run has nothing to resume; keep the checkpoint only for failed IDs
+	removed, err := checkpoint.Finish(results)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	} else if !removed {
+		fmt.Printf("Checkpoint kept at %s; rerun with --resume to retry failed transactions\n", checkpoint.Path())
+	}
+	return nil
 }
 
 // processEcoBatchFileWithEnv is the internal implementation for eco transactions
-func processEcoBatchFileWithEnv(filePath, env string) {
+func processEcoBatchFileWithEnv(filePath, env string, output adapters.OutputFormat) {
 	// Read transaction IDs from file
 	ids, err := utils.ReadTransactionIDsFromFile(filePath)
 	if err != nil {
@@ -121,21 +169,32 @@ func processEcoBatchFileWithEnv(filePath, env string) {
 
 	// Generate output path
 	outputPath := generateOutputPath(filePath)
+	sqlBasePath := strings.TrimSuffix(outputPath, "-output.txt")
 
-	// Write detailed results to output file
-	if err := adapters.WriteBatchResults(results, outputPath); err != nil {
-		fmt.Printf("Error writing output file: %v\n", err)
-		return
-	}
+	var statements domain.SQLStatements
+	if output.IsStructured() {
+		// Structured results embed the generated SQL, so generate it before writing
+		statements = adapters.GenerateSQLStatements(results)
+		outputPath = adapters.StructuredOutputPath(outputPath, output)
+		if err := adapters.WriteStructuredBatchResults(results, statements, outputPath, output); err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			return
+		}
+	} else {
+		// Write detailed results to output file
+		if err := adapters.WriteBatchResults(results, outputPath); err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			return
+		}
 
-	// Generate SQL statements
-	statements := adapters.GenerateSQLStatements(results)
+		// Generate SQL statements
+		statements = adapters.GenerateSQLStatements(results)
+	}
 
 	// Clear existing SQL files before writing (for batch mode, always start fresh)
 	adapters.ClearSQLFiles()
 
 	// Write SQL files
-	sqlBasePath := strings.TrimSuffix(outputPath, "-output.txt")
 	filesCreated, err := adapters.WriteSQLFiles(statements, sqlBasePath)
 	if err != nil {
 		fmt.Printf("Error writing SQL files: %v\n", err)
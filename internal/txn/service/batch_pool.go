@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"buddy/internal/clients/doorman"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+	"buddy/internal/txn/service/population"
+)
+
+// DefaultBatchRatePerSecond caps how many transactions are started per second
+// across all workers so that batch runs do not flood Doorman.
+const DefaultBatchRatePerSecond = 10
+
+// BatchOptions controls how a batch of transaction IDs is queried and reported
+type BatchOptions struct {
+	Concurrency   int                   // Number of parallel workers; values below 1 run sequentially
+	RatePerSecond float64               // Maximum transactions started per second; 0 disables the limit
+	Prefix        string                // Prefix for progress output (e.g. "[MY] ")
+	Output        adapters.OutputFormat // Format of the results file; empty means text
+
+	// BulkSize is how many IDs are fetched per IN (...) query before the
+	// transactions are populated; 0 queries every ID separately
+	BulkSize int
+
+	// Checkpoint, when set, receives every completed transaction. With Resume,
+	// IDs already in the checkpoint are served from it instead of being queried.
+	// Fresh discards a checkpoint left by an earlier run instead of refusing to start.
+	Checkpoint *Checkpoint
+	Resume     bool
+	Fresh      bool
+
+	// Decisions answers interactive SOP cases during SQL generation; nil prompts on stdin
+	Decisions *adapters.Decisions
+}
+
+// DecisionProvider returns the provider that answers interactive SOP cases for this run.
+// With structured output stdout carries the results, so prompts are written to stderr.
+func (o BatchOptions) DecisionProvider() adapters.DecisionProvider {
+	prompt := adapters.NewPromptDecisions()
+	if o.Output.IsStructured() {
+		prompt = adapters.NewPromptDecisionsTo(os.Stderr)
+	}
+
+	if o.Decisions == nil {
+		return prompt
+	}
+	if o.Decisions.Prompt == nil || !o.Output.IsStructured() {
+		return o.Decisions
+	}
+	decisions := *o.Decisions
+	decisions.Prompt = prompt
+	return &decisions
+}
+
+// Interactive reports whether this run may prompt on stdin
+func (o BatchOptions) Interactive() bool {
+	return o.Decisions == nil || o.Decisions.Prompt != nil
+}
+
+// DefaultBatchOptions returns sequential batch options with the default rate limit
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{
+		Concurrency:   1,
+		RatePerSecond: DefaultBatchRatePerSecond,
+		BulkSize:      population.DefaultBulkSize,
+	}
+}
+
+// QueryTransactionsWithEnv queries every ID with a bounded worker pool.
+// With opts.BulkSize set, rows for all IDs are prefetched with IN (...) queries
+// first, so the workers mostly populate from memory.
+// Results are returned in input order; an entry is nil if the query returned nil.
+func (s *TransactionQueryService) QueryTransactionsWithEnv(ids []string, env string, opts BatchOptions) []*domain.TransactionResult {
+	defer s.reportRetries(opts.Prefix, s.RetryStats())
+
+	strategy := s.strategy
+	if pending := pendingIDs(ids, opts); opts.BulkSize > 0 && len(pending) > 1 && s.bulk != nil {
+		fmt.Printf("%sPrefetching %d transactions, %d per query\n", opts.Prefix, len(pending), opts.BulkSize)
+		strategy = s.bulk.Prefetch(pending, opts.BulkSize)
+	}
+	return runBatchQueries(ids, opts, s.countRetries(opts, func(id string) *domain.TransactionResult {
+		return populateResult(strategy, id)
+	}))
+}
+
+// pendingIDs returns the IDs that are not already completed in a resumed checkpoint
+func pendingIDs(ids []string, opts BatchOptions) []string {
+	if !opts.Resume || opts.Checkpoint == nil {
+		return ids
+	}
+	pending := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := opts.Checkpoint.Completed(id); !ok {
+			pending = append(pending, id)
+		}
+	}
+	return pending
+}
+
+// QueryEcoTransactionsWithEnv is the eco-transaction equivalent of QueryTransactionsWithEnv
+func (s *TransactionQueryService) QueryEcoTransactionsWithEnv(ids []string, env string, opts BatchOptions) []*domain.TransactionResult {
+	defer s.reportRetries(opts.Prefix, s.RetryStats())
+
+	return runBatchQueries(ids, opts, s.countRetries(opts, func(id string) *domain.TransactionResult {
+		return populateResult(s.ecoStrategy, id)
+	}))
+}
+
+// countRetries makes query attribute the retries of its Doorman queries to each
+// result. The counts come from the shared client, so they are only attributed
+// when transactions are queried one at a time; concurrent runs report the
+// total through reportRetries instead.
+func (s *TransactionQueryService) countRetries(opts BatchOptions, query func(id string) *domain.TransactionResult) func(id string) *domain.TransactionResult {
+	if opts.Concurrency > 1 {
+		return query
+	}
+	return func(id string) *domain.TransactionResult {
+		return s.withRetries(func() *domain.TransactionResult { return query(id) })
+	}
+}
+
+// reportRetries prints how many queries of a batch only succeeded after
+// retrying since the since snapshot
+func (s *TransactionQueryService) reportRetries(prefix string, since doorman.RetryStats) {
+	if stats := s.RetryStats().Sub(since); stats.Queries > 0 {
+		fmt.Printf("%s%d queries succeeded after retrying (%d retries)\n", prefix, stats.Queries, stats.Retries)
+	}
+}
+
+// runBatchQueries fans ids out to a worker pool, throttled by opts.RatePerSecond,
+// and prints a live progress counter. Only queries run concurrently; callers
+// must do anything interactive (SQL generation prompts) after this returns.
+func runBatchQueries(ids []string, opts BatchOptions, query func(id string) *domain.TransactionResult) []*domain.TransactionResult {
+	results := make([]*domain.TransactionResult, len(ids))
+	if len(ids) == 0 {
+		return results
+	}
+
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(ids) {
+		workers = len(ids)
+	}
+
+	var throttle <-chan time.Time
+	if opts.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.RatePerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	var (
+		done   int
+		failed int
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		jobs   = make(chan int)
+	)
+
+	// progress records a finished query and redraws the counter in place
+	progress := func(result *domain.TransactionResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if result == nil || result.Error != "" || result.QueryFailed() {
+			failed++
+		}
+		fmt.Printf("\r%sQueried %d/%d transactions (%d with errors)", opts.Prefix, done, len(ids), failed)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = query(ids[i])
+				if opts.Checkpoint != nil {
+					if err := opts.Checkpoint.Record(ids[i], results[i]); err != nil {
+						fmt.Printf("\n%sWarning: %v\n", opts.Prefix, err)
+					}
+				}
+				progress(results[i])
+			}
+		}()
+	}
+
+	dispatched := 0
+	for i := range ids {
+		if opts.Resume && opts.Checkpoint != nil {
+			if result, ok := opts.Checkpoint.Completed(ids[i]); ok {
+				results[i] = result
+				progress(result)
+				continue
+			}
+		}
+
+		if throttle != nil && dispatched > 0 {
+			<-throttle
+		}
+		dispatched++
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	fmt.Println()
+
+	return results
+}
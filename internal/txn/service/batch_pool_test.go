@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"buddy/internal/clients/doorman"
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+)
+
+func TestRunBatchQueries_PreservesOrderAndBoundsConcurrency(t *testing.T) {
+	ids := make([]string, 25)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("txn-%02d", i)
+	}
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+
+	query := func(id string) *domain.TransactionResult {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if id == "txn-07" {
+			return nil
+		}
+		return &domain.TransactionResult{InputID: id}
+	}
+
+	results := runBatchQueries(ids, BatchOptions{Concurrency: 4}, query)
+
+	if len(results) != len(ids) {
+		t.Fatalf("expected %d results, got %d", len(ids), len(results))
+	}
+	for i, result := range results {
+		if ids[i] == "txn-07" {
+			if result != nil {
+				t.Errorf("expected nil result for txn-07, got %+v", result)
+			}
+			continue
+		}
+		if result == nil || result.InputID != ids[i] {
+			t.Errorf("result %d out of order: want %s, got %+v", i, ids[i], result)
+		}
+	}
+
+	if peak > 4 {
+		t.Errorf("expected at most 4 concurrent queries, saw %d", peak)
+	}
+}
+
+// retryingClient is a fakeClient whose retry counts are set by the test
+type retryingClient struct {
+	fakeClient
+	stats doorman.RetryStats
+}
+
+func (r *retryingClient) RetryStats() doorman.RetryStats { return r.stats }
+
+func TestCountRetries_AttributesRetriesWhenSequential(t *testing.T) {
+	client := &retryingClient{}
+	cache, err := NewCachingClient(client, QueryCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &TransactionQueryService{client: cache}
+
+	// Every transaction needs one query that succeeds on its third attempt
+	query := func(id string) *domain.TransactionResult {
+		client.stats.Queries++
+		client.stats.Retries += 2
+		return &domain.TransactionResult{InputID: id}
+	}
+
+	sequential := BatchOptions{Concurrency: 1}
+	for _, result := range runBatchQueries([]string{"txn-1", "txn-2"}, sequential, svc.countRetries(sequential, query)) {
+		if result.RecoveredRetries != 2 || result.QueryRetries() != 2 {
+			t.Errorf("expected 2 retries on %s, got %d", result.InputID, result.RecoveredRetries)
+		}
+	}
+
+	// Concurrent workers share the client, so nothing is attributed per result
+	if result := svc.countRetries(BatchOptions{Concurrency: 4}, query)("txn-3"); result.RecoveredRetries != 0 {
+		t.Errorf("expected no per-result retries when concurrent, got %d", result.RecoveredRetries)
+	}
+
+	if stats := svc.RetryStats(); stats != (doorman.RetryStats{Queries: 3, Retries: 6}) {
+		t.Errorf("expected the service to report the client's retries, got %+v", stats)
+	}
+}
+
+func TestBatchOptions_DecisionProviderPromptsOnStderrForStructuredOutput(t *testing.T) {
+	decisions := adapters.NewDecisions(false)
+	opts := BatchOptions{Output: adapters.OutputJSON, Decisions: decisions}
+
+	provider, ok := opts.DecisionProvider().(*adapters.Decisions)
+	if !ok {
+		t.Fatalf("expected the configured decisions, got %T", opts.DecisionProvider())
+	}
+	if provider == decisions || provider.Prompt == decisions.Prompt {
+		t.Error("expected structured output to replace the stdout prompt")
+	}
+	if decisions.Prompt == nil {
+		t.Error("expected the configured decisions to be left unchanged")
+	}
+
+	opts.Output = adapters.OutputText
+	if opts.DecisionProvider() != adapters.DecisionProvider(decisions) {
+		t.Error("expected text output to use the configured decisions as is")
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+)
+
+// fixedStrategy populates every transaction with the same case
+type fixedStrategy struct {
+	caseType domain.Case
+}
+
+func (f fixedStrategy) Populate(input string) (*domain.TransactionResult, error) {
+	return &domain.TransactionResult{InputID: input, CaseType: f.caseType}, nil
+}
+
+func (f fixedStrategy) GetEnvironment() string { return "sg" }
+
+func TestProcessBatchFile_NonInteractiveFailsWithoutDecision(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "ids.txt")
+	if err := os.WriteFile(input, []byte("txn-1\ntxn-2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading stdin would return EOF here rather than a missing-decision error
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stdinWriter.Close()
+	stdin := os.Stdin
+	os.Stdin = stdinReader
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		_ = stdinReader.Close()
+	})
+
+	svc := &TransactionQueryService{strategy: fixedStrategy{caseType: domain.CaseFastCashoutPendingPe220Pc201}, env: "sg"}
+	opts := DefaultBatchOptions()
+	opts.RatePerSecond = 0
+	opts.Decisions = adapters.NewDecisions(true)
+
+	err = svc.processBatchFile(input, "sg", opts)
+	if err == nil || !strings.Contains(err.Error(), "no decision for transaction txn-1") {
+		t.Fatalf("expected the run to fail on the missing decision, got %v", err)
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.sql")); len(matches) > 0 {
+		t.Errorf("expected no SQL files, got %v", matches)
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"buddy/internal/clients/doorman"
+	"buddy/internal/txn/ports"
+)
+
+// DefaultQueryCacheTTL is how long a cached query result is served before it is
+// fetched again
+const DefaultQueryCacheTTL = 10 * time.Minute
+
+// QueryCacheOptions controls the query result cache of the transaction service
+type QueryCacheOptions struct {
+	Disabled bool          // send every query to Doorman (--no-cache)
+	TTL      time.Duration // 0 uses DefaultQueryCacheTTL
+	Dir      string        // optional directory that keeps results across runs
+
+	// Namespace separates results of different backends in Dir, e.g. the
+	// profile name, so staging rows are never served to a production run
+	Namespace string
+}
+
+// cachedResult is a query result held in memory, and its on-disk form
+type cachedResult struct {
+	Method  string                   `json:"method"`
+	Target  string                   `json:"target,omitempty"`
+	Query   string                   `json:"query"`
+	Rows    []map[string]interface{} `json:"rows"`
+	SavedAt time.Time                `json:"saved_at"`
+
+	ready chan struct{} // closed once Rows are set or the query failed
+	done  bool          // guarded by CachingClient.mu
+	err   error
+}
+
+// CachingClient wraps a ClientPort and serves repeated read-only queries from a
+// cache keyed by target database and normalized SQL. Concurrent callers asking
+// for the same query share a single request. Failed queries are never cached.
+type CachingClient struct {
+	client    ports.ClientPort
+	ttl       time.Duration
+	dir       string
+	namespace string
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedResult
+	hits    int
+	misses  int
+}
+
+// NewCachingClient wraps client with a query result cache
+func NewCachingClient(client ports.ClientPort, opts QueryCacheOptions) (*CachingClient, error) {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultQueryCacheTTL
+	}
+	if opts.Dir != "" {
+		// Cached rows are production data; keep them private to the user
+		if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory %s: %w", opts.Dir, err)
+		}
+	}
+	return &CachingClient{
+		client:    client,
+		ttl:       ttl,
+		dir:       opts.Dir,
+		namespace: opts.Namespace,
+		now:       time.Now,
+		entries:   make(map[string]*cachedResult),
+	}, nil
+}
+
+// Stats returns how many queries were served from the cache and how many were sent
+func (c *CachingClient) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// isCacheableQuery reports whether query only reads data
+func isCacheableQuery(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *CachingClient) fresh(entry *cachedResult) bool {
+	return c.now().Sub(entry.SavedAt) < c.ttl
+}
+
+// cached serves query from the cache, or runs fetch and caches its result
+func (c *CachingClient) cached(method, target, query string, fetch func() ([]map[string]interface{}, error)) ([]map[string]interface{}, error) {
+	if !isCacheableQuery(query) {
+		return fetch()
+	}
+	key := strings.TrimSuffix(recordingKey(method, c.namespace+"|"+target, query), ".json")
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && (!entry.done || c.fresh(entry)) {
+		c.hits++
+		c.mu.Unlock()
+		<-entry.ready
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return copyRows(entry.Rows), nil
+	}
+
+	if entry := c.readDisk(key); entry != nil {
+		entry.ready, entry.done = make(chan struct{}), true
+		close(entry.ready)
+		c.entries[key] = entry
+		c.hits++
+		c.mu.Unlock()
+		return copyRows(entry.Rows), nil
+	}
+
+	entry := &cachedResult{Method: method, Target: target, Query: query, ready: make(chan struct{})}
+	c.entries[key] = entry
+	c.misses++
+	c.mu.Unlock()
+
+	rows, err := fetch()
+
+	c.mu.Lock()
+	entry.Rows, entry.err, entry.SavedAt, entry.done = rows, err, c.now(), true
+	close(entry.ready)
+	if err != nil {
+		// Let the next caller try again rather than replaying the failure
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		return rows, err
+	}
+	c.writeDisk(key, entry)
+	return copyRows(rows), nil
+}
+
+func (c *CachingClient) readDisk(key string) *cachedResult {
+	if c.dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	var entry cachedResult
+	if err := json.Unmarshal(data, &entry); err != nil || !c.fresh(&entry) {
+		return nil
+	}
+	return &entry
+}
+
+func (c *CachingClient) writeDisk(key string, entry *cachedResult) {
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("Warning: failed to encode cached query: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, key+".json"), data, 0o600); err != nil {
+		fmt.Printf("Warning: failed to write cached query: %v\n", err)
+	}
+}
+
+// copyRows returns a copy so that callers cannot modify cached rows
+func copyRows(rows []map[string]interface{}) []map[string]interface{} {
+	if rows == nil {
+		return nil
+	}
+	copied := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		copied[i] = make(map[string]interface{}, len(row))
+		for k, v := range row {
+			copied[i][k] = v
+		}
+	}
+	return copied
+}
+
+// RetryStats returns the retry counts of the wrapped client; cache hits never retry
+func (c *CachingClient) RetryStats() doorman.RetryStats {
+	return retryStatsOf(c.client)
+}
+
+func (c *CachingClient) QueryPaymentEngine(query string) ([]map[string]interface{}, error) {
+	return c.cached("QueryPaymentEngine", "", query, func() ([]map[string]interface{}, error) {
+		return c.client.QueryPaymentEngine(query)
+	})
+}
+
+func (c *CachingClient) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
+	return c.cached("QueryPaymentCore", "", query, func() ([]map[string]interface{}, error) {
+		return c.client.QueryPaymentCore(query)
+	})
+}
+
+func (c *CachingClient) QueryRppAdapter(query string) ([]map[string]interface{}, error) {
+	return c.cached("QueryRppAdapter", "", query, func() ([]map[string]interface{}, error) {
+		return c.client.QueryRppAdapter(query)
+	})
+}
+
+func (c *CachingClient) QueryFastAdapter(query string) ([]map[string]interface{}, error) {
+	return c.cached("QueryFastAdapter", "", query, func() ([]map[string]interface{}, error) {
+		return c.client.QueryFastAdapter(query)
+	})
+}
+
+func (c *CachingClient) QueryPartnerpayEngine(query string) ([]map[string]interface{}, error) {
+	return c.cached("QueryPartnerpayEngine", "", query, func() ([]map[string]interface{}, error) {
+		return c.client.QueryPartnerpayEngine(query)
+	})
+}
+
+func (c *CachingClient) ExecuteQuery(cluster, service, database, query string) ([]map[string]interface{}, error) {
+	return c.cached("ExecuteQuery", cluster+"/"+service+"/"+database, query, func() ([]map[string]interface{}, error) {
+		return c.client.ExecuteQuery(cluster, service, database, query)
+	})
+}
+
+// Ensure the caching client implements ports.ClientPort
+var _ ports.ClientPort = (*CachingClient)(nil)
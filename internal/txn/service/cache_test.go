@@ -0,0 +1,144 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingClient_ReusesIdenticalQueries(t *testing.T) {
+	live := &fakeClient{}
+	cache, err := NewCachingClient(live, QueryCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := cache.QueryPaymentCore("SELECT * FROM workflow_execution WHERE run_id = 'r1'")
+	second, _ := cache.QueryPaymentCore("SELECT *  FROM workflow_execution\n WHERE run_id = 'r1'")
+	if live.calls != 1 {
+		t.Errorf("expected normalized SQL to hit the cache, got %d calls", live.calls)
+	}
+	if len(second) != 1 || second[0]["state"] != first[0]["state"] {
+		t.Errorf("unexpected cached rows: %v", second)
+	}
+
+	// Cached rows are copies
+	second[0]["state"] = "changed"
+	third, _ := cache.QueryPaymentCore("SELECT * FROM workflow_execution WHERE run_id = 'r1'")
+	if third[0]["state"] != float64(220) {
+		t.Error("callers must not be able to modify cached rows")
+	}
+
+	// The same SQL against another database is a different entry
+	_, _ = cache.QueryRppAdapter("SELECT * FROM workflow_execution WHERE run_id = 'r1'")
+	_, _ = cache.ExecuteQuery("c1", "i1", "s1", "SELECT 1")
+	_, _ = cache.ExecuteQuery("c2", "i2", "s2", "SELECT 1")
+	if live.calls != 4 {
+		t.Errorf("expected one call per target database, got %d", live.calls)
+	}
+
+	hits, misses := cache.Stats()
+	if hits != 2 || misses != 4 {
+		t.Errorf("expected 2 hits and 4 misses, got %d and %d", hits, misses)
+	}
+}
+
+func TestCachingClient_SkipsFailuresAndWrites(t *testing.T) {
+	live := &fakeClient{}
+	cache, _ := NewCachingClient(live, QueryCacheOptions{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.QueryPaymentEngine("SELECT fail"); err == nil {
+			t.Fatal("expected error")
+		}
+		_, _ = cache.QueryPaymentEngine("UPDATE transfer SET status = 'FAILED'")
+	}
+	if live.calls != 4 {
+		t.Errorf("expected failures and writes to reach the client every time, got %d calls", live.calls)
+	}
+}
+
+func TestCachingClient_TTL(t *testing.T) {
+	live := &fakeClient{}
+	cache, _ := NewCachingClient(live, QueryCacheOptions{TTL: time.Minute})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, _ = cache.QueryPaymentEngine("SELECT 1")
+	now = now.Add(30 * time.Second)
+	_, _ = cache.QueryPaymentEngine("SELECT 1")
+	now = now.Add(time.Minute)
+	_, _ = cache.QueryPaymentEngine("SELECT 1")
+
+	if live.calls != 2 {
+		t.Errorf("expected an expired entry to be fetched again, got %d calls", live.calls)
+	}
+}
+
+func TestCachingClient_DiskStore(t *testing.T) {
+	dir := t.TempDir()
+	live := &fakeClient{}
+
+	first, _ := NewCachingClient(live, QueryCacheOptions{Dir: dir, Namespace: "my-prd"})
+	_, _ = first.QueryPaymentEngine("SELECT 1")
+
+	second, _ := NewCachingClient(live, QueryCacheOptions{Dir: dir, Namespace: "my-prd"})
+	rows, err := second.QueryPaymentEngine("SELECT 1")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected rows from disk, got %v, %v", rows, err)
+	}
+	if live.calls != 1 {
+		t.Errorf("expected the second run to read the disk store, got %d calls", live.calls)
+	}
+
+	staging, _ := NewCachingClient(live, QueryCacheOptions{Dir: dir, Namespace: "my-stg"})
+	_, _ = staging.QueryPaymentEngine("SELECT 1")
+	if live.calls != 2 {
+		t.Errorf("expected namespaces to be kept apart, got %d calls", live.calls)
+	}
+}
+
+// slowClient blocks every query until release is closed
+type slowClient struct {
+	fakeClient
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowClient) QueryPaymentCore(q string) ([]map[string]interface{}, error) {
+	s.calls.Add(1)
+	<-s.release
+	return []map[string]interface{}{{"query": q}}, nil
+}
+
+func TestCachingClient_SharesInFlightQueries(t *testing.T) {
+	live := &slowClient{release: make(chan struct{})}
+	cache, _ := NewCachingClient(live, QueryCacheOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rows, err := cache.QueryPaymentCore("SELECT 1"); err != nil || len(rows) != 1 {
+				t.Errorf("unexpected result: %v, %v", rows, err)
+			}
+		}()
+	}
+
+	// Let every goroutine reach the cache before the first query returns
+	for {
+		hits, misses := cache.Stats()
+		if hits+misses == 5 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(live.release)
+	wg.Wait()
+
+	if got := live.calls.Load(); got != 1 {
+		t.Errorf("expected concurrent identical queries to share one request, got %d", got)
+	}
+}
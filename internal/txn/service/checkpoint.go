@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"buddy/internal/txn/domain"
+)
+
+// checkpointVersion is bumped whenever CheckpointEntry changes incompatibly
+const checkpointVersion = 1
+
+// CheckpointEntry is one completed transaction in a checkpoint file
+type CheckpointEntry struct {
+	Version     int                      `json:"version"`
+	InputID     string                   `json:"input_id"`
+	CaseType    domain.Case              `json:"case_type"`
+	Result      domain.TransactionResult `json:"result"`
+	CompletedAt time.Time                `json:"completed_at"`
+}
+
+// Checkpoint records completed transactions of a batch run so an interrupted run
+// can be resumed. The file holds one JSON entry per line and is appended to
+// after every transaction.
+type Checkpoint struct {
+	path    string
+	entries map[string]CheckpointEntry
+	file    *os.File
+	mu      sync.Mutex
+}
+
+// CheckpointPath returns the checkpoint file path for a batch input file
+func CheckpointPath(filePath string) string {
+	return filePath + ".buddy-state.ndjson"
+}
+
+// OpenCheckpoint opens the checkpoint for a batch input file. With resume, the
+// entries of an existing checkpoint are loaded. Otherwise a non-empty checkpoint
+// left by an earlier run is refused unless fresh is set, in which case the file
+// is started over.
+func OpenCheckpoint(filePath string, resume, fresh bool) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		path:    CheckpointPath(filePath),
+		entries: make(map[string]CheckpointEntry),
+	}
+
+	if !resume && !fresh {
+		if info, err := os.Stat(cp.path); err == nil && info.Size() > 0 {
+			return nil, fmt.Errorf("checkpoint %s from an earlier run exists; rerun with --resume to continue it or --fresh to start over", cp.path)
+		}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	partialLine := false
+	if resume {
+		var err error
+		if partialLine, err = cp.load(); err != nil {
+			return nil, err
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(cp.path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint %s: %w", cp.path, err)
+	}
+	cp.file = file
+
+	if partialLine {
+		if _, err := file.Write([]byte("\n")); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write checkpoint %s: %w", cp.path, err)
+		}
+	}
+
+	return cp, nil
+}
+
+// load reads existing entries. A truncated last line from a crashed run is
+// ignored and reported through the returned flag so it can be terminated.
+func (c *Checkpoint) load() (bool, error) {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read checkpoint %s: %w", c.path, err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry CheckpointEntry
+		if len(line) == 0 || json.Unmarshal(line, &entry) != nil {
+			continue
+		}
+		if entry.Version != checkpointVersion {
+			return false, fmt.Errorf("checkpoint %s has unsupported version %d", c.path, entry.Version)
+		}
+		c.entries[entry.InputID] = entry
+	}
+
+	return len(data) > 0 && data[len(data)-1] != '\n', nil
+}
+
+// Path returns the checkpoint file path
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// Len returns the number of completed transactions in the checkpoint
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Completed returns the checkpointed result for an input ID
+func (c *Checkpoint) Completed(inputID string) (*domain.TransactionResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[inputID]
+	if !ok {
+		return nil, false
+	}
+	result := entry.Result
+	result.CaseType = entry.CaseType
+	return &result, true
+}
+
+// Record appends the completed transaction for inputID to the checkpoint. Results
+// with an error or a failed query are not recorded so that they are queried
+// again on resume.
+func (c *Checkpoint) Record(inputID string, result *domain.TransactionResult) error {
+	if result == nil || result.Error != "" || result.QueryFailed() {
+		return nil
+	}
+
+	entry := CheckpointEntry{
+		Version:     checkpointVersion,
+		InputID:     inputID,
+		CaseType:    result.CaseType,
+		Result:      *result,
+		CompletedAt: time.Now(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", c.path, err)
+	}
+	c.entries[entry.InputID] = entry
+	return nil
+}
+
+// Finish closes the checkpoint and removes its file when every result completed,
+// since a finished run has nothing left to resume. The file is kept when any
+// result has an error or a failed query so that --resume retries only those.
+// It reports whether the file was removed.
+func (c *Checkpoint) Finish(results []domain.TransactionResult) (bool, error) {
+	for _, result := range results {
+		if result.Error != "" || result.QueryFailed() {
+			return false, nil
+		}
+	}
+
+	if err := c.Close(); err != nil {
+		return false, fmt.Errorf("failed to close checkpoint %s: %w", c.path, err)
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to remove checkpoint %s: %w", c.path, err)
+	}
+	return true, nil
+}
+
+// Close closes the checkpoint file. Closing an already closed checkpoint is a no-op.
+func (c *Checkpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
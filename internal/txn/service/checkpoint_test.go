@@ -0,0 +1,176 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"buddy/internal/txn/domain"
+)
+
+func TestCheckpoint_RecordAndResume(t *testing.T) {
+	input := t.TempDir() + "/ids.txt"
+
+	cp, err := OpenCheckpoint(input, false, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint: %v", err)
+	}
+	if err := cp.Record("txn-1", &domain.TransactionResult{InputID: "txn-1", CaseType: domain.CasePeStuck230RepublishPC}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := cp.Record("txn-2", &domain.TransactionResult{InputID: "txn-2", Error: "query failed"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := cp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(input + ".buddy-state.ndjson"); err != nil {
+		t.Fatalf("expected the checkpoint to be named for its NDJSON content: %v", err)
+	}
+
+	// Simulate a crash in the middle of writing the next entry
+	f, err := os.OpenFile(CheckpointPath(input), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open checkpoint: %v", err)
+	}
+	if _, err := f.WriteString(`{"version":1,"input_id":"txn-3"`); err != nil {
+		t.Fatalf("write partial entry: %v", err)
+	}
+	_ = f.Close()
+
+	resumed, err := OpenCheckpoint(input, true, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint resume: %v", err)
+	}
+	defer func() {
+		_ = resumed.Close()
+	}()
+
+	if resumed.Len() != 1 {
+		t.Fatalf("expected 1 completed transaction, got %d", resumed.Len())
+	}
+	result, ok := resumed.Completed("txn-1")
+	if !ok || result.InputID != "txn-1" || result.CaseType != domain.CasePeStuck230RepublishPC {
+		t.Errorf("unexpected checkpointed result: %+v", result)
+	}
+	if _, ok := resumed.Completed("txn-2"); ok {
+		t.Error("errored results must not be checkpointed")
+	}
+
+	// Entries written after the partial line must still be readable
+	if err := resumed.Record("txn-3", &domain.TransactionResult{InputID: "txn-3"}); err != nil {
+		t.Fatalf("Record after resume: %v", err)
+	}
+	_ = resumed.Close()
+
+	reopened, err := OpenCheckpoint(input, true, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint reopen: %v", err)
+	}
+	defer func() {
+		_ = reopened.Close()
+	}()
+	if reopened.Len() != 2 {
+		t.Errorf("expected 2 completed transactions after reopen, got %d", reopened.Len())
+	}
+}
+
+func TestRunBatchQueries_SkipsCheckpointedIDs(t *testing.T) {
+	input := t.TempDir() + "/ids.txt"
+
+	cp, err := OpenCheckpoint(input, false, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint: %v", err)
+	}
+	if err := cp.Record("txn-1", &domain.TransactionResult{InputID: "txn-1", CaseType: domain.CasePeStuck230RepublishPC}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	_ = cp.Close()
+
+	cp, err = OpenCheckpoint(input, true, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint resume: %v", err)
+	}
+	defer func() {
+		_ = cp.Close()
+	}()
+
+	var queried []string
+	results := runBatchQueries([]string{"txn-1", "txn-2"}, BatchOptions{Checkpoint: cp, Resume: true}, func(id string) *domain.TransactionResult {
+		queried = append(queried, id)
+		return &domain.TransactionResult{InputID: id}
+	})
+
+	if len(queried) != 1 || queried[0] != "txn-2" {
+		t.Errorf("expected only txn-2 to be queried, got %v", queried)
+	}
+	if results[0] == nil || results[0].CaseType != domain.CasePeStuck230RepublishPC {
+		t.Errorf("expected checkpointed result for txn-1, got %+v", results[0])
+	}
+	if _, ok := cp.Completed("txn-2"); !ok {
+		t.Error("expected txn-2 to be checkpointed after querying")
+	}
+}
+
+func TestOpenCheckpoint_RefusesExistingCheckpointWithoutResumeOrFresh(t *testing.T) {
+	input := t.TempDir() + "/ids.txt"
+
+	cp, err := OpenCheckpoint(input, false, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint: %v", err)
+	}
+	if err := cp.Record("txn-1", &domain.TransactionResult{InputID: "txn-1"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	_ = cp.Close()
+
+	if _, err := OpenCheckpoint(input, false, false); err == nil || !strings.Contains(err.Error(), "--fresh") {
+		t.Fatalf("expected an existing checkpoint to be refused, got %v", err)
+	}
+	if data, err := os.ReadFile(CheckpointPath(input)); err != nil || len(data) == 0 {
+		t.Fatalf("expected the refused checkpoint to be left intact, got %q (%v)", data, err)
+	}
+
+	fresh, err := OpenCheckpoint(input, false, true)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint fresh: %v", err)
+	}
+	defer func() {
+		_ = fresh.Close()
+	}()
+	if fresh.Len() != 0 {
+		t.Errorf("expected a fresh checkpoint to start empty, got %d entries", fresh.Len())
+	}
+	if info, err := os.Stat(CheckpointPath(input)); err != nil || info.Size() != 0 {
+		t.Errorf("expected --fresh to truncate the checkpoint, got %v (%v)", info, err)
+	}
+}
+
+func TestCheckpoint_FinishRemovesOnlyCleanRuns(t *testing.T) {
+	input := t.TempDir() + "/ids.txt"
+
+	cp, err := OpenCheckpoint(input, false, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint: %v", err)
+	}
+	failed := []domain.TransactionResult{{InputID: "txn-1"}, {InputID: "txn-2", Error: "query failed"}}
+	removed, err := cp.Finish(failed)
+	if err != nil || removed {
+		t.Fatalf("expected the checkpoint to be kept when a transaction failed, got removed=%v err=%v", removed, err)
+	}
+	if _, err := os.Stat(CheckpointPath(input)); err != nil {
+		t.Fatalf("expected the checkpoint to still exist: %v", err)
+	}
+
+	removed, err = cp.Finish([]domain.TransactionResult{{InputID: "txn-1"}, {InputID: "txn-2"}})
+	if err != nil || !removed {
+		t.Fatalf("expected the checkpoint to be removed after a clean run, got removed=%v err=%v", removed, err)
+	}
+	if _, err := os.Stat(CheckpointPath(input)); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint file to be removed, got %v", err)
+	}
+	if err := cp.Close(); err != nil {
+		t.Errorf("Close after Finish: %v", err)
+	}
+}
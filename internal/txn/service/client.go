@@ -5,11 +5,30 @@ import (
 	"fmt"
 )
 
+// retryReporter is implemented by clients that count the queries Doorman only
+// answered after retrying
+type retryReporter interface {
+	RetryStats() doorman.RetryStats
+}
+
+// retryStatsOf returns the retry counts of client, or zero if it keeps none
+func retryStatsOf(client any) doorman.RetryStats {
+	if reporter, ok := client.(retryReporter); ok {
+		return reporter.RetryStats()
+	}
+	return doorman.RetryStats{}
+}
+
 // DoormanClient implements the ports.ClientPort interface
 type DoormanClient struct {
 	client doorman.DoormanInterface
 }
 
+// RetryStats returns the retry counts of the underlying Doorman client
+func (d *DoormanClient) RetryStats() doorman.RetryStats {
+	return retryStatsOf(d.client)
+}
+
 func (d *DoormanClient) QueryPaymentEngine(query string) ([]map[string]interface{}, error) {
 	if d.client == nil {
 		return nil, fmt.Errorf("doorman client is not initialized - check environment configuration")
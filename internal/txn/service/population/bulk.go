@@ -0,0 +1,343 @@
+package population
+
+import (
+	"buddy/internal/txn/adapters"
+	"buddy/internal/txn/domain"
+	"buddy/internal/txn/ports"
+	"buddy/internal/txn/utils"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DefaultBulkSize is how many IDs are fetched per IN (...) query
+const DefaultBulkSize = 100
+
+// paymentCoreWindow matches the created_at window of the per-ID payment core queries
+const paymentCoreWindow = time.Hour
+
+// BulkPopulator populates many transactions with a few IN (...) queries per
+// database instead of one query per transaction and table
+type BulkPopulator struct {
+	env      string
+	adapters AdapterSet
+	sopRepo  *adapters.SOPRepository
+}
+
+// NewBulkPopulator creates a bulk populator over the given adapters
+func NewBulkPopulator(env string, adapters AdapterSet, sopRepo *adapters.SOPRepository) *BulkPopulator {
+	return &BulkPopulator{env: env, adapters: adapters, sopRepo: sopRepo}
+}
+
+// Prefetch loads transfer, internal_transaction, external_transaction and
+// workflow_execution rows for ids, chunkSize IDs per query, and returns a
+// strategy that populates each ID from those rows. Anything the bulk queries did
+// not cover, e.g. E2E IDs or a chunk whose query failed, is queried per ID as usual.
+func (b *BulkPopulator) Prefetch(ids []string, chunkSize int) PopulationStrategy {
+	bulkPE, peOK := b.adapters.PaymentEngine.(ports.BulkPaymentEnginePort)
+	bulkPC, pcOK := b.adapters.PaymentCore.(ports.BulkPaymentCorePort)
+	if !peOK {
+		return NewPopulationStrategy(b.env, b.adapters, b.sopRepo)
+	}
+	if chunkSize < 1 {
+		chunkSize = DefaultBulkSize
+	}
+
+	pe := &prefetchedPaymentEngine{
+		PaymentEnginePort: b.adapters.PaymentEngine,
+		transfers:         newRowSet(),
+		workflows:         newRowSet(),
+	}
+	var pc *prefetchedPaymentCore
+	if pcOK {
+		pc = &prefetchedPaymentCore{
+			PaymentCorePort: b.adapters.PaymentCore,
+			internal:        newRowSet(),
+			external:        newRowSet(),
+			workflows:       newRowSet(),
+		}
+	}
+
+	// E2E IDs are resolved through the RPP and Fast adapters first
+	var transactionIDs []string
+	for _, id := range ids {
+		if id != "" && !domain.IsRppE2EID(id) {
+			transactionIDs = append(transactionIDs, id)
+		}
+	}
+
+	for _, chunk := range chunkIDs(transactionIDs, chunkSize) {
+		transfers := prefetchPaymentEngine(bulkPE, pe, chunk, chunkSize)
+		if pc != nil {
+			prefetchPaymentCore(bulkPC, pc, transfers, chunkSize)
+		}
+	}
+
+	prefetched := b.adapters
+	prefetched.PaymentEngine = pe
+	if pc != nil {
+		prefetched.PaymentCore = pc
+	}
+	return NewPopulationStrategy(b.env, prefetched, b.sopRepo)
+}
+
+// prefetchPaymentEngine loads the transfers of chunk and their workflows, and
+// returns the transfers that were found
+func prefetchPaymentEngine(port ports.BulkPaymentEnginePort, pe *prefetchedPaymentEngine, chunk []string, chunkSize int) []map[string]interface{} {
+	transfers, err := port.QueryTransfers(chunk)
+	if err != nil {
+		slog.Warn("Bulk transfer query failed, falling back to per-ID queries",
+			"ids", len(chunk),
+			"error", err)
+		return nil
+	}
+	pe.transfers.load(chunk, transfers, "transaction_id")
+
+	var referenceIDs []string
+	for _, transfer := range transfers {
+		if referenceID := utils.GetStringValue(transfer, "reference_id"); referenceID != "" {
+			referenceIDs = append(referenceIDs, referenceID)
+		}
+	}
+	for _, runIDs := range chunkIDs(referenceIDs, chunkSize) {
+		workflows, err := port.QueryWorkflows(runIDs)
+		if err != nil {
+			slog.Warn("Bulk payment engine workflow query failed, falling back to per-ID queries",
+				"ids", len(runIDs),
+				"error", err)
+			continue
+		}
+		pe.workflows.load(runIDs, workflows, "run_id")
+	}
+
+	return transfers
+}
+
+// prefetchPaymentCore loads the internal and external transactions grouped
+// under transfers, and the workflows behind them
+func prefetchPaymentCore(port ports.BulkPaymentCorePort, pc *prefetchedPaymentCore, transfers []map[string]interface{}, chunkSize int) {
+	var (
+		groupIDs []string
+		keys     []string
+		from, to time.Time
+	)
+	for _, transfer := range transfers {
+		groupID := utils.GetStringValue(transfer, "transaction_id")
+		createdAtStr := utils.GetStringValue(transfer, "created_at")
+		createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+		if groupID == "" || err != nil {
+			continue
+		}
+		if len(groupIDs) == 0 || createdAt.Before(from) {
+			from = createdAt
+		}
+		if len(groupIDs) == 0 || createdAt.After(to) {
+			to = createdAt
+		}
+		groupIDs = append(groupIDs, groupID)
+		keys = append(keys, groupKey(groupID, createdAtStr))
+	}
+	if len(groupIDs) == 0 {
+		return
+	}
+	from, to = from.Add(-paymentCoreWindow), to.Add(paymentCoreWindow)
+
+	var runIDs []string
+	if rows, err := port.QueryInternalTransactionsByGroupIDs(groupIDs, from, to); err != nil {
+		slog.Warn("Bulk internal_transaction query failed, falling back to per-ID queries",
+			"ids", len(groupIDs),
+			"error", err)
+	} else {
+		pc.internal.loadGroups(keys, rows)
+		runIDs = appendColumn(runIDs, rows, "tx_id")
+	}
+	if rows, err := port.QueryExternalTransactionsByGroupIDs(groupIDs, from, to); err != nil {
+		slog.Warn("Bulk external_transaction query failed, falling back to per-ID queries",
+			"ids", len(groupIDs),
+			"error", err)
+	} else {
+		pc.external.loadGroups(keys, rows)
+		runIDs = appendColumn(runIDs, rows, "ref_id")
+	}
+
+	for _, chunk := range chunkIDs(runIDs, chunkSize) {
+		workflows, err := pc.PaymentCorePort.QueryWorkflows(chunk)
+		if err != nil {
+			slog.Warn("Bulk payment core workflow query failed, falling back to per-ID queries",
+				"ids", len(chunk),
+				"error", err)
+			continue
+		}
+		pc.workflows.load(chunk, workflows, "run_id")
+	}
+}
+
+// prefetchedPaymentEngine serves transfers and workflows loaded by Prefetch and
+// passes everything else to the live port
+type prefetchedPaymentEngine struct {
+	ports.PaymentEnginePort
+	transfers *rowSet
+	workflows *rowSet
+}
+
+func (p *prefetchedPaymentEngine) QueryTransfer(transactionID string) (map[string]interface{}, error) {
+	rows, ok := p.transfers.get(transactionID)
+	if !ok {
+		return p.PaymentEnginePort.QueryTransfer(transactionID)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+func (p *prefetchedPaymentEngine) QueryWorkflow(referenceID string) (map[string]interface{}, error) {
+	rows, ok := p.workflows.get(referenceID)
+	if !ok {
+		return p.PaymentEnginePort.QueryWorkflow(referenceID)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("workflow %s: %w", referenceID, domain.ErrNotFound)
+	}
+	return rows[0], nil
+}
+
+// prefetchedPaymentCore serves transactions and workflows loaded by Prefetch and
+// passes everything else to the live port
+type prefetchedPaymentCore struct {
+	ports.PaymentCorePort
+	internal  *rowSet
+	external  *rowSet
+	workflows *rowSet
+}
+
+func (p *prefetchedPaymentCore) QueryInternalTransactions(transactionID string, createdAt string) ([]map[string]interface{}, error) {
+	rows, ok := p.internal.get(groupKey(transactionID, createdAt))
+	if !ok {
+		return p.PaymentCorePort.QueryInternalTransactions(transactionID, createdAt)
+	}
+	return withinWindow(rows, createdAt), nil
+}
+
+func (p *prefetchedPaymentCore) QueryExternalTransactions(transactionID string, createdAt string) ([]map[string]interface{}, error) {
+	rows, ok := p.external.get(groupKey(transactionID, createdAt))
+	if !ok {
+		return p.PaymentCorePort.QueryExternalTransactions(transactionID, createdAt)
+	}
+	return withinWindow(rows, createdAt), nil
+}
+
+func (p *prefetchedPaymentCore) QueryWorkflows(runIDs []string) ([]map[string]interface{}, error) {
+	var workflows []map[string]interface{}
+	for _, runID := range runIDs {
+		rows, ok := p.workflows.get(runID)
+		if !ok {
+			return p.PaymentCorePort.QueryWorkflows(runIDs)
+		}
+		workflows = append(workflows, rows...)
+	}
+	return workflows, nil
+}
+
+// rowSet holds prefetched rows by key. A key that was queried but matched no
+// rows is remembered, so it is not queried again. Keys are compared case
+// insensitively, as the database compares IDs in the IN (...) list.
+type rowSet struct {
+	rows map[string][]map[string]interface{}
+}
+
+func newRowSet() *rowSet {
+	return &rowSet{rows: make(map[string][]map[string]interface{})}
+}
+
+// load records rows under the value of their column, and every key in keys as queried
+func (s *rowSet) load(keys []string, rows []map[string]interface{}, column string) {
+	for _, key := range keys {
+		if _, ok := s.rows[strings.ToLower(key)]; !ok {
+			s.rows[strings.ToLower(key)] = nil
+		}
+	}
+	for _, row := range rows {
+		key := strings.ToLower(utils.GetStringValue(row, column))
+		s.rows[key] = append(s.rows[key], row)
+	}
+}
+
+// loadGroups records rows under the groupKey of their group_id. keys are the
+// groupKeys that were queried.
+func (s *rowSet) loadGroups(keys []string, rows []map[string]interface{}) {
+	byGroup := make(map[string][]map[string]interface{})
+	for _, row := range rows {
+		groupID := strings.ToLower(utils.GetStringValue(row, "group_id"))
+		byGroup[groupID] = append(byGroup[groupID], row)
+	}
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		groupID, _, _ := strings.Cut(key, "|")
+		s.rows[key] = byGroup[groupID]
+	}
+}
+
+// get returns the rows for key and whether key was prefetched at all
+func (s *rowSet) get(key string) ([]map[string]interface{}, bool) {
+	rows, ok := s.rows[strings.ToLower(key)]
+	return rows, ok
+}
+
+// groupKey identifies a payment core lookup by group ID and the created_at it
+// is anchored to; lookups anchored elsewhere are not served from the prefetch
+func groupKey(groupID, createdAt string) string {
+	return groupID + "|" + createdAt
+}
+
+// withinWindow keeps the rows a per-ID query anchored at createdAt would have
+// returned. Rows with an unparseable created_at are kept.
+func withinWindow(rows []map[string]interface{}, createdAt string) []map[string]interface{} {
+	anchor, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return rows
+	}
+	from, to := anchor.Add(-paymentCoreWindow), anchor.Add(paymentCoreWindow)
+
+	var kept []map[string]interface{}
+	for _, row := range rows {
+		rowTime, err := time.Parse(time.RFC3339, utils.GetStringValue(row, "created_at"))
+		if err != nil || (!rowTime.Before(from) && !rowTime.After(to)) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// appendColumn appends the non-empty values of column in rows to values
+func appendColumn(values []string, rows []map[string]interface{}, column string) []string {
+	for _, row := range rows {
+		if value := utils.GetStringValue(row, column); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// chunkIDs splits ids into slices of at most size IDs, dropping duplicates
+func chunkIDs(ids []string, size int) [][]string {
+	seen := make(map[string]bool, len(ids))
+	var chunks [][]string
+	var chunk []string
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		chunk = append(chunk, id)
+		if len(chunk) == size {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
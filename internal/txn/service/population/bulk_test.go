@@ -0,0 +1,220 @@
+package population
+
+import (
+	"buddy/internal/txn/domain"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePaymentEngine serves transfers and workflows from memory and counts calls
+type fakePaymentEngine struct {
+	transfers map[string]map[string]interface{}
+	workflows map[string]map[string]interface{}
+	bulkErr   error
+	calls     map[string]int
+}
+
+func (f *fakePaymentEngine) count(method string) {
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *fakePaymentEngine) QueryTransfer(transactionID string) (map[string]interface{}, error) {
+	f.count("QueryTransfer")
+	return f.transfers[transactionID], nil
+}
+
+func (f *fakePaymentEngine) QueryWorkflow(referenceID string) (map[string]interface{}, error) {
+	f.count("QueryWorkflow")
+	if workflow, ok := f.workflows[referenceID]; ok {
+		return workflow, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakePaymentEngine) QueryTransferByExternalID(externalID, createdAt string) (map[string]interface{}, error) {
+	f.count("QueryTransferByExternalID")
+	return nil, nil
+}
+
+func (f *fakePaymentEngine) QueryTransfers(transactionIDs []string) ([]map[string]interface{}, error) {
+	f.count("QueryTransfers")
+	if f.bulkErr != nil {
+		return nil, f.bulkErr
+	}
+	var rows []map[string]interface{}
+	for _, id := range transactionIDs {
+		if transfer, ok := f.transfers[id]; ok {
+			rows = append(rows, transfer)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakePaymentEngine) QueryWorkflows(runIDs []string) ([]map[string]interface{}, error) {
+	f.count("QueryWorkflows")
+	var rows []map[string]interface{}
+	for _, id := range runIDs {
+		if workflow, ok := f.workflows[id]; ok {
+			rows = append(rows, workflow)
+		}
+	}
+	return rows, nil
+}
+
+// fakePaymentCore serves internal transactions and workflows from memory and counts calls
+type fakePaymentCore struct {
+	internal  []map[string]interface{}
+	workflows map[string]map[string]interface{}
+	calls     map[string]int
+}
+
+func (f *fakePaymentCore) count(method string) {
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[method]++
+}
+
+func (f *fakePaymentCore) QueryInternalTransactions(transactionID string, createdAt string) ([]map[string]interface{}, error) {
+	f.count("QueryInternalTransactions")
+	return nil, nil
+}
+
+func (f *fakePaymentCore) QueryExternalTransactions(transactionID string, createdAt string) ([]map[string]interface{}, error) {
+	f.count("QueryExternalTransactions")
+	return nil, nil
+}
+
+func (f *fakePaymentCore) QueryWorkflows(runIDs []string) ([]map[string]interface{}, error) {
+	f.count("QueryWorkflows")
+	var rows []map[string]interface{}
+	for _, id := range runIDs {
+		if workflow, ok := f.workflows[id]; ok {
+			rows = append(rows, workflow)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakePaymentCore) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
+	f.count("QueryPaymentCore")
+	return nil, nil
+}
+
+func (f *fakePaymentCore) QueryInternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error) {
+	f.count("QueryInternalTransactionsByGroupIDs")
+	return f.internal, nil
+}
+
+func (f *fakePaymentCore) QueryExternalTransactionsByGroupIDs(groupIDs []string, from, to time.Time) ([]map[string]interface{}, error) {
+	f.count("QueryExternalTransactionsByGroupIDs")
+	return nil, nil
+}
+
+type fakeRPPAdapter struct{}
+
+func (fakeRPPAdapter) Query(params domain.RPPQueryParams) (*domain.RPPAdapterInfo, error) {
+	return nil, nil
+}
+
+func newBulkFixture() (*fakePaymentEngine, *fakePaymentCore, AdapterSet) {
+	pe := &fakePaymentEngine{
+		transfers: map[string]map[string]interface{}{
+			"txn-1": {"transaction_id": "txn-1", "status": "PROCESSING", "reference_id": "ref-1", "created_at": "2025-01-10T08:00:00Z"},
+			"txn-2": {"transaction_id": "txn-2", "status": "COMPLETED", "reference_id": "ref-2", "created_at": "2025-01-12T08:00:00Z"},
+		},
+		workflows: map[string]map[string]interface{}{
+			"ref-1": {"run_id": "ref-1", "workflow_id": "workflow_transfer_payment", "state": float64(220)},
+			"ref-2": {"run_id": "ref-2", "workflow_id": "workflow_transfer_payment", "state": float64(900)},
+		},
+	}
+	pc := &fakePaymentCore{
+		internal: []map[string]interface{}{
+			{"group_id": "txn-1", "tx_id": "auth-1", "tx_type": "AUTH", "status": "SUCCESS", "created_at": "2025-01-10T08:00:05Z"},
+			// Inside the bulk window but more than an hour from txn-1
+			{"group_id": "txn-1", "tx_id": "auth-late", "tx_type": "CAPTURE", "status": "SUCCESS", "created_at": "2025-01-11T08:00:00Z"},
+		},
+		workflows: map[string]map[string]interface{}{
+			"auth-1": {"run_id": "auth-1", "workflow_id": "internal_payment_flow", "state": float64(900)},
+		},
+	}
+	return pe, pc, AdapterSet{PaymentEngine: pe, PaymentCore: pc, RPPAdapter: fakeRPPAdapter{}}
+}
+
+func TestBulkPopulator_PopulatesFromPrefetchedRows(t *testing.T) {
+	pe, pc, adapterSet := newBulkFixture()
+	ids := []string{"txn-1", "txn-2", "txn-missing"}
+
+	strategy := NewBulkPopulator("my", adapterSet, nil).Prefetch(ids, 2)
+
+	results := make(map[string]*domain.TransactionResult)
+	for _, id := range ids {
+		result, err := strategy.Populate(id)
+		if err != nil {
+			t.Fatalf("Populate(%s): %v", id, err)
+		}
+		results[id] = result
+	}
+
+	if got := pe.calls["QueryTransfers"]; got != 2 {
+		t.Errorf("expected 2 chunked transfer queries, got %d", got)
+	}
+	for _, method := range []string{"QueryTransfer", "QueryWorkflow"} {
+		if got := pe.calls[method]; got != 0 {
+			t.Errorf("expected no per-ID %s calls, got %d", method, got)
+		}
+	}
+	if got := pc.calls["QueryInternalTransactions"]; got != 0 {
+		t.Errorf("expected no per-ID internal_transaction queries, got %d", got)
+	}
+	if got := pc.calls["QueryWorkflows"]; got != 1 {
+		t.Errorf("expected payment core workflows to be fetched once, got %d", got)
+	}
+
+	first := results["txn-1"]
+	if first.PaymentEngine == nil || first.PaymentEngine.Workflow.State != "220" {
+		t.Fatalf("expected payment engine workflow state 220, got %+v", first.PaymentEngine)
+	}
+	if auth := first.PaymentCore.InternalAuth; auth.TxID != "auth-1" || auth.Workflow.State != "900" {
+		t.Errorf("expected AUTH auth-1 with workflow state 900, got %+v", auth)
+	}
+	if capture := first.PaymentCore.InternalCapture; capture.TxID != "" {
+		t.Errorf("expected rows outside the per-ID window to be dropped, got %+v", capture)
+	}
+	if second := results["txn-2"]; second.PaymentEngine.Workflow.State != "900" {
+		t.Errorf("expected txn-2 workflow state 900, got %s", second.PaymentEngine.Workflow.State)
+	}
+	if missing := results["txn-missing"]; !strings.Contains(missing.Error, "not found in payment engine") {
+		t.Errorf("expected not found error, got %q", missing.Error)
+	}
+}
+
+func TestBulkPopulator_FallsBackWhenBulkQueryFails(t *testing.T) {
+	pe, _, adapterSet := newBulkFixture()
+	pe.bulkErr = errors.New("doorman unavailable")
+
+	strategy := NewBulkPopulator("my", adapterSet, nil).Prefetch([]string{"txn-1", "txn-2"}, 10)
+	result, err := strategy.Populate("txn-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := pe.calls["QueryTransfer"]; got != 1 {
+		t.Errorf("expected a per-ID transfer query after the bulk query failed, got %d", got)
+	}
+	if result.PaymentEngine == nil || result.PaymentEngine.Transfers.Status != "PROCESSING" {
+		t.Errorf("expected the live transfer, got %+v", result.PaymentEngine)
+	}
+}
+
+func TestChunkIDs(t *testing.T) {
+	chunks := chunkIDs([]string{"a", "b", "a", "c", "d", "e"}, 2)
+	if len(chunks) != 3 || len(chunks[2]) != 1 || chunks[1][0] != "c" {
+		t.Errorf("unexpected chunks: %v", chunks)
+	}
+}
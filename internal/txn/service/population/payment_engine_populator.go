@@ -20,8 +20,11 @@ func NewPEPopulator(port ports.PaymentEnginePort) PaymentEnginePopulator {
 // QueryByTransactionID fetches transfer and workflow by transaction ID
 func (p *pePopulator) QueryByTransactionID(transactionID string) (*domain.PaymentEngineInfo, error) {
 	transfer, err := p.port.QueryTransfer(transactionID)
-	if err != nil || transfer == nil {
-		return nil, fmt.Errorf("failed to query payment engine: %w", err)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, fmt.Errorf("transfer %s: %w", transactionID, domain.ErrNotFound)
 	}
 
 	info := &domain.PaymentEngineInfo{
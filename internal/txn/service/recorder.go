@@ -0,0 +1,174 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"buddy/internal/clients/doorman"
+	"buddy/internal/txn/ports"
+)
+
+// recordedQuery is the on-disk form of a single query/response pair
+type recordedQuery struct {
+	Method string                   `json:"method"`
+	Target string                   `json:"target,omitempty"` // cluster/instance/schema for ExecuteQuery
+	Query  string                   `json:"query"`
+	Rows   []map[string]interface{} `json:"rows"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+// recordingKey returns the file name used to store a query. Whitespace in the
+// query is normalized so that formatting changes do not break replay.
+func recordingKey(method, target, query string) string {
+	normalized := strings.Join(strings.Fields(query), " ")
+	sum := sha256.Sum256([]byte(method + "\n" + target + "\n" + normalized))
+	return fmt.Sprintf("%s-%s.json", method, hex.EncodeToString(sum[:])[:16])
+}
+
+// RecordingClient wraps a ClientPort and saves every query/response pair to a directory
+type RecordingClient struct {
+	client ports.ClientPort
+	dir    string
+	mu     sync.Mutex
+}
+
+// NewRecordingClient creates a recording client that writes into dir
+func NewRecordingClient(client ports.ClientPort, dir string) (*RecordingClient, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create record directory %s: %w", dir, err)
+	}
+	return &RecordingClient{client: client, dir: dir}, nil
+}
+
+func (r *RecordingClient) record(method, target, query string, rows []map[string]interface{}, queryErr error) {
+	entry := recordedQuery{Method: method, Target: target, Query: query, Rows: rows}
+	if queryErr != nil {
+		entry.Error = queryErr.Error()
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		fmt.Printf("Warning: failed to encode recorded query: %v\n", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := filepath.Join(r.dir, recordingKey(method, target, query))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Printf("Warning: failed to write recorded query: %v\n", err)
+	}
+}
+
+// RetryStats returns the retry counts of the wrapped client
+func (r *RecordingClient) RetryStats() doorman.RetryStats {
+	return retryStatsOf(r.client)
+}
+
+func (r *RecordingClient) QueryPaymentEngine(query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.QueryPaymentEngine(query)
+	r.record("QueryPaymentEngine", "", query, rows, err)
+	return rows, err
+}
+
+func (r *RecordingClient) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.QueryPaymentCore(query)
+	r.record("QueryPaymentCore", "", query, rows, err)
+	return rows, err
+}
+
+func (r *RecordingClient) QueryRppAdapter(query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.QueryRppAdapter(query)
+	r.record("QueryRppAdapter", "", query, rows, err)
+	return rows, err
+}
+
+func (r *RecordingClient) QueryFastAdapter(query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.QueryFastAdapter(query)
+	r.record("QueryFastAdapter", "", query, rows, err)
+	return rows, err
+}
+
+func (r *RecordingClient) QueryPartnerpayEngine(query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.QueryPartnerpayEngine(query)
+	r.record("QueryPartnerpayEngine", "", query, rows, err)
+	return rows, err
+}
+
+func (r *RecordingClient) ExecuteQuery(cluster, service, database, query string) ([]map[string]interface{}, error) {
+	rows, err := r.client.ExecuteQuery(cluster, service, database, query)
+	r.record("ExecuteQuery", cluster+"/"+service+"/"+database, query, rows, err)
+	return rows, err
+}
+
+// ReplayClient implements ports.ClientPort by serving responses saved by RecordingClient
+type ReplayClient struct {
+	dir string
+}
+
+// NewReplayClient creates a replay client that reads from dir
+func NewReplayClient(dir string) (*ReplayClient, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("replay directory %s is not readable: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("replay path %s is not a directory", dir)
+	}
+	return &ReplayClient{dir: dir}, nil
+}
+
+func (r *ReplayClient) replay(method, target, query string) ([]map[string]interface{}, error) {
+	path := filepath.Join(r.dir, recordingKey(method, target, query))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s query in %s: %s", method, r.dir, strings.Join(strings.Fields(query), " "))
+	}
+
+	var entry recordedQuery
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode recorded query %s: %w", path, err)
+	}
+
+	if entry.Error != "" {
+		return entry.Rows, errors.New(entry.Error)
+	}
+	return entry.Rows, nil
+}
+
+func (r *ReplayClient) QueryPaymentEngine(query string) ([]map[string]interface{}, error) {
+	return r.replay("QueryPaymentEngine", "", query)
+}
+
+func (r *ReplayClient) QueryPaymentCore(query string) ([]map[string]interface{}, error) {
+	return r.replay("QueryPaymentCore", "", query)
+}
+
+func (r *ReplayClient) QueryRppAdapter(query string) ([]map[string]interface{}, error) {
+	return r.replay("QueryRppAdapter", "", query)
+}
+
+func (r *ReplayClient) QueryFastAdapter(query string) ([]map[string]interface{}, error) {
+	return r.replay("QueryFastAdapter", "", query)
+}
+
+func (r *ReplayClient) QueryPartnerpayEngine(query string) ([]map[string]interface{}, error) {
+	return r.replay("QueryPartnerpayEngine", "", query)
+}
+
+func (r *ReplayClient) ExecuteQuery(cluster, service, database, query string) ([]map[string]interface{}, error) {
+	return r.replay("ExecuteQuery", cluster+"/"+service+"/"+database, query)
+}
+
+// Ensure record and replay clients implement ports.ClientPort
+var (
+	_ ports.ClientPort = (*RecordingClient)(nil)
+	_ ports.ClientPort = (*ReplayClient)(nil)
+)
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeClient returns canned rows for every query and counts calls
+type fakeClient struct {
+	calls int
+}
+
+func (f *fakeClient) rows(query string) ([]map[string]interface{}, error) {
+	f.calls++
+	if query == "SELECT fail" {
+		return nil, errors.New("query failed")
+	}
+	return []map[string]interface{}{{"query": query, "state": float64(220)}}, nil
+}
+
+func (f *fakeClient) QueryPaymentEngine(q string) ([]map[string]interface{}, error) { return f.rows(q) }
+func (f *fakeClient) QueryPaymentCore(q string) ([]map[string]interface{}, error)   { return f.rows(q) }
+func (f *fakeClient) QueryRppAdapter(q string) ([]map[string]interface{}, error)    { return f.rows(q) }
+func (f *fakeClient) QueryFastAdapter(q string) ([]map[string]interface{}, error)   { return f.rows(q) }
+func (f *fakeClient) QueryPartnerpayEngine(q string) ([]map[string]interface{}, error) {
+	return f.rows(q)
+}
+func (f *fakeClient) ExecuteQuery(cluster, service, database, q string) ([]map[string]interface{}, error) {
+	return f.rows(cluster + service + database + q)
+}
+
+func TestRecordAndReplay_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	live := &fakeClient{}
+
+	recorder, err := NewRecordingClient(live, dir)
+	if err != nil {
+		t.Fatalf("NewRecordingClient: %v", err)
+	}
+
+	if _, err := recorder.QueryPaymentEngine("SELECT * FROM transfer WHERE transaction_id = 'abc'"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recorder.ExecuteQuery("cluster", "instance", "schema", "SELECT 1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := recorder.QueryPaymentCore("SELECT fail"); err == nil {
+		t.Fatal("expected recorded query to fail")
+	}
+
+	replayer, err := NewReplayClient(dir)
+	if err != nil {
+		t.Fatalf("NewReplayClient: %v", err)
+	}
+
+	// Whitespace differences must not affect lookup
+	rows, err := replayer.QueryPaymentEngine("SELECT *  FROM transfer\n WHERE transaction_id = 'abc'")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["state"] != float64(220) {
+		t.Errorf("unexpected replayed rows: %+v", rows)
+	}
+
+	if _, err := replayer.ExecuteQuery("cluster", "instance", "schema", "SELECT 1"); err != nil {
+		t.Errorf("replay of ExecuteQuery failed: %v", err)
+	}
+
+	if _, err := replayer.QueryPaymentCore("SELECT fail"); err == nil || err.Error() != "query failed" {
+		t.Errorf("expected recorded error to be replayed, got %v", err)
+	}
+
+	// Same query against a different database is a different recording
+	if _, err := replayer.QueryRppAdapter("SELECT * FROM transfer WHERE transaction_id = 'abc'"); err == nil {
+		t.Error("expected missing recording to return an error")
+	}
+
+	if live.calls != 3 {
+		t.Errorf("expected replay not to hit the live client, got %d calls", live.calls)
+	}
+}
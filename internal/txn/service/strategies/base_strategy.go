@@ -4,6 +4,8 @@ import (
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
 	"buddy/internal/txn/service/builders"
+	"errors"
+	"fmt"
 	"log/slog"
 )
 
@@ -32,6 +34,15 @@ type PartnerpayPopulator interface {
 	QueryCharge(runID string) (*domain.PartnerpayEngineInfo, error)
 }
 
+// System names used when recording query failures on a result
+const (
+	SystemPaymentEngine    = "payment-engine"
+	SystemPaymentCore      = "payment-core"
+	SystemRPPAdapter       = "rpp-adapter"
+	SystemFastAdapter      = "fast-adapter"
+	SystemPartnerpayEngine = "partnerpay-engine"
+)
+
 // BasePopulationStrategy contains shared logic for Malaysia and Singapore strategies
 type BasePopulationStrategy struct {
 	env                 string
@@ -79,6 +90,7 @@ func (s *BasePopulationStrategy) populatePaymentEngineFromAdapters(result *domai
 			peInfo, err := s.pePopulator.QueryByTransactionID(
 				result.RPPAdapter.PartnerTxID,
 			)
+			result.RecordQueryFailure(SystemPaymentEngine, err)
 			if err == nil && peInfo != nil {
 				result.PaymentEngine = peInfo
 			}
@@ -88,6 +100,7 @@ func (s *BasePopulationStrategy) populatePaymentEngineFromAdapters(result *domai
 				result.RPPAdapter.EndToEndID,
 				result.RPPAdapter.CreatedAt,
 			)
+			result.RecordQueryFailure(SystemPaymentEngine, err)
 			if err == nil && peInfo != nil {
 				result.PaymentEngine = peInfo
 			}
@@ -99,6 +112,7 @@ func (s *BasePopulationStrategy) populatePaymentEngineFromAdapters(result *domai
 				result.FastAdapter.InstructionID,
 				result.FastAdapter.CreatedAt,
 			)
+			result.RecordQueryFailure(SystemPaymentEngine, err)
 			if err == nil && peInfo != nil {
 				result.PaymentEngine = peInfo
 			}
@@ -119,6 +133,7 @@ func (s *BasePopulationStrategy) populateAdaptersFromPaymentEngine(result *domai
 			adapterData, err := s.adapterPopulator.QueryByInputID(
 				result.PaymentEngine.Transfers.ExternalID,
 			)
+			result.RecordQueryFailure(SystemRPPAdapter, err)
 			if err == nil && adapterData != nil {
 				if rppInfo, ok := adapterData.(*domain.RPPAdapterInfo); ok {
 					result.RPPAdapter = rppInfo
@@ -136,6 +151,7 @@ func (s *BasePopulationStrategy) populateAdaptersFromPaymentEngine(result *domai
 				Timestamp:            result.PaymentEngine.Transfers.CreatedAt,
 			}
 			rppInfo, err := rppPort.port.Query(params)
+			result.RecordQueryFailure(SystemRPPAdapter, err)
 			if err == nil && rppInfo != nil {
 				result.RPPAdapter = rppInfo
 			}
@@ -168,6 +184,7 @@ func (s *BasePopulationStrategy) populatePaymentCore(result *domain.TransactionR
 
 	// Query internal transactions
 	internalTxs, err := s.pcPopulator.QueryInternal(transactionID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err == nil {
 		for _, internalTx := range internalTxs {
 			switch internalTx.TxType {
@@ -181,6 +198,7 @@ func (s *BasePopulationStrategy) populatePaymentCore(result *domain.TransactionR
 
 	// Query external transactions
 	externalTxs, err := s.pcPopulator.QueryExternal(transactionID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err == nil {
 		for _, externalTx := range externalTxs {
 			if externalTx.TxType == "TRANSFER" {
@@ -193,8 +211,25 @@ func (s *BasePopulationStrategy) populatePaymentCore(result *domain.TransactionR
 	return nil
 }
 
+// paymentEngineError describes why the payment-engine transfer for an input is
+// missing, keeping "not found" apart from "could not be queried"
+func paymentEngineError(err error) string {
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Sprintf("not found in payment engine: %v", err)
+	}
+	return fmt.Sprintf("failed to query payment engine: %v", err)
+}
+
 // identifyCase identifies the SOP case for the transaction
 func (s *BasePopulationStrategy) identifyCase(result *domain.TransactionResult) {
+	if result.QueryFailed() {
+		// Matching a case on partial data could generate SQL for the wrong fix
+		slog.Warn("Skipping case identification: some systems could not be queried",
+			"inputID", result.InputID,
+			"failures", len(result.QueryFailures))
+		result.CaseType = domain.CaseNone
+		return
+	}
 	if s.sopRepo != nil {
 		s.sopRepo.IdentifyCase(result, s.env)
 	}
@@ -239,6 +274,7 @@ func (s *BasePopulationStrategy) populatePaymentCoreFromRPP(result *domain.Trans
 
 	// Query internal transactions (AUTH, CAPTURE)
 	internalTxs, err := s.pcPopulator.QueryInternal(transactionID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err != nil {
 		slog.Warn("Failed to query PaymentCore internal transactions from RPP",
 			"error", err,
@@ -259,6 +295,7 @@ func (s *BasePopulationStrategy) populatePaymentCoreFromRPP(result *domain.Trans
 
 	// Query external transactions (TRANSFER)
 	externalTxs, err := s.pcPopulator.QueryExternal(transactionID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err != nil {
 		slog.Warn("Failed to query PaymentCore external transactions from RPP",
 			"error", err,
@@ -301,6 +338,7 @@ func (s *BasePopulationStrategy) populatePartnerpayFromRPP(result *domain.Transa
 		}
 
 		chargeInfo, err := s.partnerpayPopulator.QueryCharge(wf.RunID)
+		result.RecordQueryFailure(SystemPartnerpayEngine, err)
 		if err != nil {
 			slog.Debug("Failed to query PartnerpayEngine charge for workflow",
 				"error", err,
@@ -1,8 +1,11 @@
 package strategies
 
 import (
+	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -93,3 +96,61 @@ func TestPopulatePaymentEngineFromAdapters_RPP_PartnerTxID(t *testing.T) {
 	// (Optional: depending on implementation preference, but good for stability)
 	// For now, let's focus on the feature: adding the fallback.
 }
+
+// attemptsError mimics a Doorman query error that was retried
+type attemptsError struct{ attempts int }
+
+func (e attemptsError) Error() string      { return "doorman query failed: 503 Service Unavailable" }
+func (e attemptsError) QueryAttempts() int { return e.attempts }
+
+func TestPopulate_NotFoundVersusQueryFailed(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		mockPE := &MockPaymentEnginePopulator{
+			QueryByTransactionIDFunc: func(transactionID string) (*domain.PaymentEngineInfo, error) {
+				return nil, fmt.Errorf("transfer %s: %w", transactionID, domain.ErrNotFound)
+			},
+		}
+		result, _ := NewMalaysiaStrategy(mockPE, nil, nil, nil, nil).Populate("txn-1")
+
+		if result.QueryFailed() {
+			t.Errorf("a missing transfer is not a query failure: %+v", result.QueryFailures)
+		}
+		if !strings.HasPrefix(result.Error, "not found in payment engine") {
+			t.Errorf("unexpected error: %q", result.Error)
+		}
+	})
+
+	t.Run("QueryFailed", func(t *testing.T) {
+		mockPE := &MockPaymentEnginePopulator{
+			QueryByTransactionIDFunc: func(transactionID string) (*domain.PaymentEngineInfo, error) {
+				return nil, fmt.Errorf("wrapped: %w", attemptsError{attempts: 3})
+			},
+		}
+		result, _ := NewSingaporeStrategy(mockPE, nil, nil, nil, nil, nil).Populate("txn-1")
+
+		if len(result.QueryFailures) != 1 {
+			t.Fatalf("expected 1 query failure, got %+v", result.QueryFailures)
+		}
+		failure := result.QueryFailures[0]
+		if failure.System != SystemPaymentEngine || failure.Attempts != 3 {
+			t.Errorf("unexpected failure: %+v", failure)
+		}
+		if result.QueryRetries() != 2 {
+			t.Errorf("expected 2 retries, got %d", result.QueryRetries())
+		}
+		if !strings.HasPrefix(result.Error, "failed to query payment engine") {
+			t.Errorf("unexpected error: %q", result.Error)
+		}
+	})
+}
+
+func TestIdentifyCase_SkippedOnQueryFailure(t *testing.T) {
+	strategy := NewBaseStrategy("my", nil, nil, nil, nil, adapters.NewSOPRepository())
+	result := &domain.TransactionResult{InputID: "txn-1"}
+	result.RecordQueryFailure(SystemPaymentCore, errors.New("network error"))
+
+	strategy.identifyCase(result)
+	if result.CaseType != domain.CaseNone {
+		t.Errorf("expected no case on partial data, got %s", result.CaseType)
+	}
+}
@@ -48,6 +48,7 @@ func (s *EcoPopulationStrategy) Populate(input string) (*domain.TransactionResul
 			result.PartnerpayEngine = ppeInfo
 		} else {
 			result.Error = fmt.Sprintf("failed to query partnerpay engine: %v", err)
+			result.RecordQueryFailure(SystemPartnerpayEngine, err)
 			return result, nil
 		}
 	}
@@ -84,6 +85,7 @@ func (s *EcoPopulationStrategy) populatePaymentCoreEco(result *domain.Transactio
 
 	// Query internal transactions
 	internalTxs, err := s.pcPopulator.QueryInternal(groupID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err == nil {
 		for _, internalTx := range internalTxs {
 			switch internalTx.TxType {
@@ -97,6 +99,7 @@ func (s *EcoPopulationStrategy) populatePaymentCoreEco(result *domain.Transactio
 
 	// Query external transactions
 	externalTxs, err := s.pcPopulator.QueryExternal(groupID, createdAt)
+	result.RecordQueryFailure(SystemPaymentCore, err)
 	if err == nil {
 		for _, externalTx := range externalTxs {
 			if externalTx.TxType == "TRANSFER" {
@@ -109,6 +112,10 @@ func (s *EcoPopulationStrategy) populatePaymentCoreEco(result *domain.Transactio
 
 // identifyCase identifies the SOP case for the transaction
 func (s *EcoPopulationStrategy) identifyCase(result *domain.TransactionResult) {
+	if result.QueryFailed() {
+		result.CaseType = domain.CaseNone
+		return
+	}
 	if s.sopRepo != nil {
 		s.sopRepo.IdentifyCase(result, s.env)
 	}
@@ -3,7 +3,6 @@ package strategies
 import (
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
-	"fmt"
 	"log/slog"
 )
 
@@ -37,6 +36,7 @@ func (s *MalaysiaPopulationStrategy) Populate(input string) (*domain.Transaction
 
 	if domain.IsRppE2EID(input) && s.adapterPopulator != nil {
 		adapterData, err := s.adapterPopulator.QueryByInputID(input)
+		result.RecordQueryFailure(SystemRPPAdapter, err)
 		if err == nil && adapterData != nil {
 			if rppInfo, ok := adapterData.(*domain.RPPAdapterInfo); ok {
 				result.RPPAdapter = rppInfo
@@ -65,7 +65,8 @@ func (s *MalaysiaPopulationStrategy) Populate(input string) (*domain.Transaction
 	} else {
 		peInfo, err := s.pePopulator.QueryByTransactionID(input)
 		if err != nil {
-			result.Error = fmt.Sprintf("failed to query payment engine: %v", err)
+			result.Error = paymentEngineError(err)
+			result.RecordQueryFailure(SystemPaymentEngine, err)
 			return result, nil
 		}
 		result.PaymentEngine = peInfo
@@ -3,7 +3,6 @@ package strategies
 import (
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
-	"fmt"
 )
 
 // SingaporePopulationStrategy implements the population strategy for Singapore
@@ -46,6 +45,7 @@ func (s *SingaporePopulationStrategy) Populate(input string) (*domain.Transactio
 			fastInfo, err := s.fastAdapterPort.Query(domain.FastQueryParams{
 				InstructionID: input,
 			})
+			result.RecordQueryFailure(SystemFastAdapter, err)
 			if err == nil && fastInfo != nil {
 				result.FastAdapter = fastInfo
 			}
@@ -54,7 +54,8 @@ func (s *SingaporePopulationStrategy) Populate(input string) (*domain.Transactio
 		// Transaction ID: Query PaymentEngine directly
 		peInfo, err := s.pePopulator.QueryByTransactionID(input)
 		if err != nil {
-			result.Error = fmt.Sprintf("failed to query payment engine: %v", err)
+			result.Error = paymentEngineError(err)
+			result.RecordQueryFailure(SystemPaymentEngine, err)
 			return result, nil
 		}
 		result.PaymentEngine = peInfo
@@ -84,6 +85,7 @@ func (s *SingaporePopulationStrategy) Populate(input string) (*domain.Transactio
 					InstructionID: result.PaymentEngine.Transfers.ExternalID,
 					Timestamp:     result.PaymentEngine.Transfers.CreatedAt,
 				})
+				result.RecordQueryFailure(SystemFastAdapter, err)
 				if err == nil && fastInfo != nil {
 					result.FastAdapter = fastInfo
 				}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"fmt"
+	"os"
+
 	"buddy/internal/clients/doorman"
 	"buddy/internal/txn/adapters"
 	"buddy/internal/txn/domain"
@@ -23,9 +26,11 @@ type AdapterSet struct {
 type TransactionQueryService struct {
 	strategy    population.PopulationStrategy
 	ecoStrategy population.PopulationStrategy
+	bulk        *population.BulkPopulator
 	adapters    AdapterSet
 	sopRepo     *adapters.SOPRepository
 	env         string
+	client      ports.ClientPort
 }
 
 var (
@@ -49,15 +54,25 @@ func GetTransactionQueryService() *TransactionQueryService {
 	return txnSvc
 }
 
-// createTransactionService creates a new transaction query service for the given environment
+// createTransactionService creates a new transaction query service for the given
+// environment. Repeated queries within the process are served from memory.
 func createTransactionService(env string) *TransactionQueryService {
+	svc := createTransactionServiceWithClient(env, doorman.Doorman)
+	if err := svc.ConfigureQueryCache(QueryCacheOptions{}); err != nil {
+		fmt.Printf("Warning: query cache disabled: %v\n", err)
+	}
+	return svc
+}
+
+// createTransactionServiceWithClient creates a transaction query service backed by the given client
+func createTransactionServiceWithClient(env string, client ports.ClientPort) *TransactionQueryService {
 	var adapterSet AdapterSet
 
 	switch env {
 	case "my":
-		adapterSet = createMalaysiaAdapters()
+		adapterSet = createMalaysiaAdapters(client)
 	case "sg":
-		adapterSet = createSingaporeAdapters()
+		adapterSet = createSingaporeAdapters(client)
 	default:
 		panic("unsupported environment: " + env)
 	}
@@ -73,19 +88,21 @@ func createTransactionService(env string) *TransactionQueryService {
 
 	strategy := population.NewPopulationStrategy(env, populationAdapters, adapters.SOPRepo)
 	ecoStrategy := population.NewEcoStrategy(env, populationAdapters, adapters.SOPRepo)
+	bulk := population.NewBulkPopulator(env, populationAdapters, adapters.SOPRepo)
 
 	return &TransactionQueryService{
 		adapters:    adapterSet,
 		sopRepo:     adapters.SOPRepo,
 		env:         env,
+		client:      client,
 		strategy:    strategy,
 		ecoStrategy: ecoStrategy,
+		bulk:        bulk,
 	}
 }
 
 // createMalaysiaAdapters creates adapters for Malaysia environment
-func createMalaysiaAdapters() AdapterSet {
-	client := doorman.Doorman
+func createMalaysiaAdapters(client ports.ClientPort) AdapterSet {
 	return AdapterSet{
 		PaymentEngine:    svcAdapters.NewPaymentEngineAdapter(client),
 		PaymentCore:      svcAdapters.NewPaymentCoreAdapter(client),
@@ -96,8 +113,7 @@ func createMalaysiaAdapters() AdapterSet {
 }
 
 // createSingaporeAdapters creates adapters for Singapore environment
-func createSingaporeAdapters() AdapterSet {
-	client := doorman.Doorman
+func createSingaporeAdapters(client ports.ClientPort) AdapterSet {
 	return AdapterSet{
 		PaymentEngine:    svcAdapters.NewPaymentEngineAdapter(client),
 		PaymentCore:      svcAdapters.NewPaymentCoreAdapter(client),
@@ -107,6 +123,61 @@ func createSingaporeAdapters() AdapterSet {
 	}
 }
 
+// UseClient rebuilds the adapters and strategies of the service on top of client
+func (s *TransactionQueryService) UseClient(client ports.ClientPort) {
+	*s = *createTransactionServiceWithClient(s.env, client)
+}
+
+// ConfigureQueryCache puts a query result cache in front of the current client,
+// replacing any cache configured before. opts.Disabled removes the cache.
+func (s *TransactionQueryService) ConfigureQueryCache(opts QueryCacheOptions) error {
+	client := s.client
+	if cache, ok := client.(*CachingClient); ok {
+		client = cache.client
+	}
+	if opts.Disabled || client == nil {
+		s.UseClient(client)
+		return nil
+	}
+
+	cache, err := NewCachingClient(client, opts)
+	if err != nil {
+		return err
+	}
+	s.UseClient(cache)
+	return nil
+}
+
+// ConfigureRecordReplay switches the service to record queries into recordDir or
+// to serve them from replayDir. Empty directories leave the service unchanged.
+// The mode is announced on stderr so it never mixes with structured output.
+func (s *TransactionQueryService) ConfigureRecordReplay(recordDir, replayDir string) error {
+	if recordDir != "" && replayDir != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
+
+	if replayDir != "" {
+		client, err := NewReplayClient(replayDir)
+		if err != nil {
+			return err
+		}
+		s.UseClient(client)
+		fmt.Fprintf(os.Stderr, "Replaying queries from %s\n", replayDir)
+		return nil
+	}
+
+	if recordDir != "" {
+		client, err := NewRecordingClient(doorman.Doorman, recordDir)
+		if err != nil {
+			return err
+		}
+		s.UseClient(client)
+		fmt.Fprintf(os.Stderr, "Recording queries to %s\n", recordDir)
+	}
+
+	return nil
+}
+
 // QueryTransaction retrieves complete transaction information by ID
 func (s *TransactionQueryService) QueryTransaction(transactionID string) *domain.TransactionResult {
 	return s.QueryTransactionWithEnv(transactionID, s.env)
@@ -114,7 +185,33 @@ func (s *TransactionQueryService) QueryTransaction(transactionID string) *domain
 
 // QueryTransactionWithEnv retrieves complete transaction information by ID with specified environment
 func (s *TransactionQueryService) QueryTransactionWithEnv(inputID string, env string) *domain.TransactionResult {
-	result, err := s.strategy.Populate(inputID)
+	return s.withRetries(func() *domain.TransactionResult {
+		return populateResult(s.strategy, inputID)
+	})
+}
+
+// RetryStats returns how many queries of the service's client only succeeded
+// after retrying; clients that do not retry report zero
+func (s *TransactionQueryService) RetryStats() doorman.RetryStats {
+	return retryStatsOf(s.client)
+}
+
+// withRetries runs populate and records on its result the retries of the
+// queries that succeeded after retrying. Queries running concurrently on the
+// same client would be counted too, so callers only use it for one
+// transaction at a time.
+func (s *TransactionQueryService) withRetries(populate func() *domain.TransactionResult) *domain.TransactionResult {
+	before := s.RetryStats()
+	result := populate()
+	if result != nil {
+		result.RecoveredRetries += s.RetryStats().Sub(before).Retries
+	}
+	return result
+}
+
+// populateResult runs strategy for inputID, turning a failure into an error result
+func populateResult(strategy population.PopulationStrategy, inputID string) *domain.TransactionResult {
+	result, err := strategy.Populate(inputID)
 	if err != nil {
 		return &domain.TransactionResult{
 			InputID: inputID,
@@ -126,14 +223,9 @@ func (s *TransactionQueryService) QueryTransactionWithEnv(inputID string, env st
 
 // QueryEcoTransactionWithEnv retrieves ecological transaction information by run_id
 func (s *TransactionQueryService) QueryEcoTransactionWithEnv(runID string, env string) *domain.TransactionResult {
-	result, err := s.ecoStrategy.Populate(runID)
-	if err != nil {
-		return &domain.TransactionResult{
-			InputID: runID,
-			Error:   err.Error(),
-		}
-	}
-	return result
+	return s.withRetries(func() *domain.TransactionResult {
+		return populateResult(s.ecoStrategy, runID)
+	})
 }
 
 // QueryPartnerpayEngine queries the partnerpay-engine database for a transaction by run_id
@@ -127,7 +127,8 @@ func mysqlStringLiteral(s string) string {
 	return b.String()
 }
 
-// GetRollbackStreamMessage extracts and formats the StreamMessage for rollback
+// GetRollbackStreamMessage extracts and formats the StreamMessage for rollback.
+// It returns NULL when the data has no StreamMessage to restore.
 func GetRollbackStreamMessage(data string) string {
 	// Default success object
 	streamMessageExpr := "JSON_OBJECT('TxID','', 'Status','SUCCESS', 'ErrorCode','', 'ExternalID','', 'ReferenceID','', 'ErrorMessage','', 'ValueTimestamp','')"
@@ -135,19 +136,22 @@ func GetRollbackStreamMessage(data string) string {
 	if data != "" {
 		var dataMap map[string]interface{}
 		if err := json.Unmarshal([]byte(data), &dataMap); err == nil {
-			if sm, ok := dataMap["StreamMessage"]; ok {
-				if smBytes, err := json.Marshal(sm); err == nil {
-					smJSON := string(smBytes)
-					if expr, err := ToMySQLJSONObjectExpr(smJSON); err == nil {
-						streamMessageExpr = expr
-						// Update status to SUCCESS and clear errors
-						streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
-							"'Status','FAILED'", "'Status','SUCCESS'")
-						streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
-							"'ErrorCode','ADAPTER_ERROR'", "'ErrorCode',''")
-						streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
-							"'ErrorMessage','Manual Rejected'", "'ErrorMessage',''")
-					}
+			sm, ok := dataMap["StreamMessage"]
+			if !ok || sm == nil {
+				// Nothing to restore; clear what the deploy wrote
+				return "NULL"
+			}
+			if smBytes, err := json.Marshal(sm); err == nil {
+				smJSON := string(smBytes)
+				if expr, err := ToMySQLJSONObjectExpr(smJSON); err == nil {
+					streamMessageExpr = expr
+					// Update status to SUCCESS and clear errors
+					streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
+						"'Status','FAILED'", "'Status','SUCCESS'")
+					streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
+						"'ErrorCode','ADAPTER_ERROR'", "'ErrorCode',''")
+					streamMessageExpr = strings.ReplaceAll(streamMessageExpr,
+						"'ErrorMessage','Manual Rejected'", "'ErrorMessage',''")
 				}
 			}
 		}
@@ -155,3 +159,30 @@ func GetRollbackStreamMessage(data string) string {
 
 	return streamMessageExpr
 }
+
+// GetRollbackJSONValue returns a MySQL expression that restores the top-level
+// key of workflow data to its pre-deploy value. It returns NULL when the key
+// was absent, and fallback when data is empty or not a JSON object.
+func GetRollbackJSONValue(data, key, fallback string) string {
+	if data == "" {
+		return fallback
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+
+	var dataMap map[string]any
+	if err := dec.Decode(&dataMap); err != nil || dataMap == nil {
+		return fallback
+	}
+
+	value, ok := dataMap[key]
+	if !ok {
+		return "NULL"
+	}
+	expr, err := buildValue(value)
+	if err != nil {
+		return fallback
+	}
+	return expr
+}